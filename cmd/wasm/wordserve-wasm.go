@@ -302,13 +302,20 @@ func parseWordLine(line string) (string, int) {
 }
 
 // parseBinaryChunk parses a binary dictionary chunk
-// Format: [4 bytes word count][word entries...]
+// Format: ["WSD1" magic][1 byte version][4 bytes word count][word entries...]
+// Legacy chunks start straight with the word count.
 // Each word entry: [2 bytes word length][word string][2 bytes rank]
 func parseBinaryChunk(data []byte) (map[string]int, error) {
 	if len(data) < 4 {
 		return nil, js.Error{Value: js.ValueOf("chunk too small")}
 	}
 	reader := &byteReader{data: data, pos: 0}
+	if string(data[:4]) == "WSD1" {
+		if len(data) < 9 || data[4] != 1 {
+			return nil, js.Error{Value: js.ValueOf("unsupported chunk format version")}
+		}
+		reader.pos = 5
+	}
 	var wordCount int32
 	if err := binary.Read(reader, binary.LittleEndian, &wordCount); err != nil {
 		return nil, js.Error{Value: js.ValueOf("failed to read word count")}
@@ -15,17 +15,25 @@ The server uses lazy, chunked dictionaries to manage large word
 datasets with low memory overhead. Words are ranked by frequency and filtered
 using configurable thresholds to deliver relevant suggestions.
 
+The -http flag serves the same completions as JSON over HTTP instead.
+
 # CLI Mode
 
 The CLI provides an interactive shell for debugging and testing the completion
-engine's functionality.
+engine's functionality. With -input it completes the prefixes of a file instead,
+and -bench times a prefix workload and prints latency percentiles.
+
+With -serve the HTTP server runs next to the CLI on the same completer, for
+debugging a client while typing prefixes. The IPC server can't, as the CLI reads stdin.
 
 # Data Files
 
 The data directory must contain dictionary files named `dict_0001.bin`,
 `dict_0002.bin`, etc., along with a `words.txt` file. If these files are
 missing, the application will attempt to generate them locally or download them
-from the project's GitHub releases page.
+from the project's GitHub releases page. With -text a plain word list is loaded
+instead and no chunk files are used. -build writes the chunk files from
+`words.txt` and exits, which is how release data is made.
 
 # Config
 
@@ -36,14 +44,20 @@ created automatically if one does not exist.
 package main
 
 import (
+	"context"
 	"flag"
 	"fmt"
 	"os"
 	"os/signal"
+	"path/filepath"
+	"sync/atomic"
 	"syscall"
+	"time"
 
 	"github.com/bastiangx/wordserve/internal/cli"
+	"github.com/bastiangx/wordserve/internal/utils"
 	"github.com/bastiangx/wordserve/pkg/config"
+	"github.com/bastiangx/wordserve/pkg/dictionary"
 	"github.com/bastiangx/wordserve/pkg/server"
 	completion "github.com/bastiangx/wordserve/pkg/suggest"
 	"github.com/charmbracelet/lipgloss"
@@ -57,11 +71,20 @@ var (
 	date    = "unknown"
 )
 
+// devServeAddr is where -c -serve listens when -http isn't set, local only as it is meant for development
+const devServeAddr = "127.0.0.1:8080"
+
+// readyTimeout is how long startup waits for the initial chunks before reporting they are still loading
+const readyTimeout = 5 * time.Second
+
 const (
 	AppName = "wordserve"
 	gh      = "https://github.com/bastiangx/wordserve"
 )
 
+// beforeExit is run by sigHandler before exiting, once the IPC server is up
+var beforeExit atomic.Pointer[func()]
+
 // sigHandler is a simple handler for OS signals to exit normally.
 func sigHandler() {
 	c := make(chan os.Signal, 1)
@@ -70,10 +93,90 @@ func sigHandler() {
 	go func() {
 		<-c
 		fmt.Fprintf(os.Stderr, "\nExiting...\n")
+		if hook := beforeExit.Load(); hook != nil {
+			(*hook)()
+		}
 		os.Exit(0)
 	}()
 }
 
+// newCompleter creates a completer for dataDir with the dictionary settings of appConfig
+func newCompleter(appConfig *config.Config, dataDir string, chunkSize, wordLimit int, hotCache bool) *completion.Completer {
+	completer := completion.NewLazyCompleter(dataDir, chunkSize, wordLimit, hotCache)
+	completer.GetChunkLoader().SetReleaseURL(appConfig.Dict.ReleaseURL)
+	completer.GetChunkLoader().SetMaxMemory(int64(appConfig.Dict.MaxMemoryBytes))
+	if conversion, err := dictionary.ParseRankConversion(appConfig.Dict.RankConversion); err != nil {
+		log.Warnf("Reading chunk values as ranks: %v", err)
+	} else {
+		completer.GetChunkLoader().SetRankConversion(conversion)
+	}
+	completer.SetFoldDiacritics(appConfig.Dict.FoldDiacritics)
+	if sortMode, err := completion.ParseSortMode(appConfig.Dict.SortMode); err != nil {
+		log.Warnf("Using frequency order: %v", err)
+	} else {
+		completer.SetSortMode(sortMode)
+	}
+	completer.SetPreferInflections(appConfig.Dict.PreferInflections)
+	completer.SetFrequencyThresholds(completion.FrequencyThresholds{
+		Min:         appConfig.Dict.MinFreqThreshold,
+		ShortPrefix: appConfig.Dict.MinFreqShortPrefix,
+	})
+
+	completer.GetChunkLoader().SetProgressCallback(func(loadedChunks, totalChunks, loadedWords int) {
+		log.Debugf("Loaded %d/%d chunks (%s words) from %s", loadedChunks, totalChunks, utils.FormatWithCommas(loadedWords), dataDir)
+	})
+	return completer
+}
+
+// newTextCompleter creates a static completer holding the words of a text dictionary,
+// no chunk files are read
+func newTextCompleter(appConfig *config.Config, path string) (*completion.Completer, error) {
+	completer := completion.NewCompleter()
+	if err := completer.LoadTextDictionary(path); err != nil {
+		return nil, err
+	}
+	completer.SetFoldDiacritics(appConfig.Dict.FoldDiacritics)
+	if sortMode, err := completion.ParseSortMode(appConfig.Dict.SortMode); err != nil {
+		log.Warnf("Using frequency order: %v", err)
+	} else {
+		completer.SetSortMode(sortMode)
+	}
+	completer.SetPreferInflections(appConfig.Dict.PreferInflections)
+	completer.SetFrequencyThresholds(completion.FrequencyThresholds{
+		Min:         appConfig.Dict.MinFreqThreshold,
+		ShortPrefix: appConfig.Dict.MinFreqShortPrefix,
+	})
+	return completer, nil
+}
+
+// initLanguage loads the dictionary of an extra language from dict.languages.
+// Its dir needs its own words.txt, otherwise the loader would fetch the
+// release's English one to build from.
+func initLanguage(appConfig *config.Config, dataDir string, chunkSize, wordLimit int, hotCache bool) (*completion.Completer, error) {
+	if !utils.FileExists(filepath.Join(dataDir, "words.txt")) {
+		return nil, fmt.Errorf("no words.txt in %s", dataDir)
+	}
+	completer := newCompleter(appConfig, dataDir, chunkSize, wordLimit, hotCache)
+	if err := completer.Initialize(); err != nil {
+		return nil, err
+	}
+	return completer, nil
+}
+
+// newServer creates the server for completer and adds the languages of dict.languages
+func newServer(appConfig *config.Config, configPath string, completer *completion.Completer, chunkSize, wordLimit int, hotCache bool) *server.Server {
+	srv := server.NewServer(completer, appConfig, configPath)
+	for code, dataDir := range appConfig.Dict.Languages {
+		langCompleter, err := initLanguage(appConfig, dataDir, chunkSize, wordLimit, hotCache)
+		if err != nil {
+			log.Errorf("Not serving language %q: %v", code, err)
+			continue
+		}
+		srv.AddLanguage(code, langCompleter)
+	}
+	return srv
+}
+
 // main calls other packages to initialize the server or CLI inputs.
 // main() does not implement logic for them and only manages the flow.
 func main() {
@@ -86,11 +189,20 @@ func main() {
 	debugMode := flag.Bool("v", false, "Toggle verbose mode")
 	cliMode := flag.Bool("c", false, "Run CLI -- useful for testing and debugging")
 	limit := flag.Int("limit", defaultConfig.CLI.DefaultLimit, "Number of suggestions to return")
-	minPrefix := flag.Int("prmin", defaultConfig.CLI.DefaultMinLen, "Minimum prefix length for suggestions (1 < n <= prmax)")
-	maxPrefix := flag.Int("prmax", defaultConfig.CLI.DefaultMaxLen, "Maximum prefix length for suggestions")
+	minPrefix := flag.Int("prmin", defaultConfig.CLI.DefaultMinLen, "Minimum prefix length for suggestions (1 <= n <= prmax)")
+	maxPrefix := flag.Int("prmax", defaultConfig.CLI.DefaultMaxLen, "Maximum prefix length for suggestions (prmin <= n)")
 	noFilter := flag.Bool("no-filter", defaultConfig.CLI.DefaultNoFilter, "Disable input filtering (DBG only) - shows all raw dictionary entries (numbers, symbols, etc)")
 	wordLimit := flag.Int("words", defaultConfig.Dict.MaxWords, "Maximum number of words to load (use 0 for all words)")
 	chunkSize := flag.Int("chunk", defaultConfig.Dict.ChunkSize, "Number of words per chunk for lazy loading")
+	hotCache := flag.Bool("hotcache", false, "Cache results for frequently requested prefixes")
+	quiet := flag.Bool("quiet", false, "CLI without banner and prompts, only results on stdout")
+	serveMode := flag.Bool("serve", false, "With -c, also serve HTTP on the -http address (default 127.0.0.1:8080) from the same completer")
+	textDict := flag.String("text", "", "Load a plain text dictionary (word and optional count per line) instead of chunk files")
+	httpAddr := flag.String("http", "", "Serve completions as JSON over HTTP on this address (e.g. :8080) instead of stdin/stdout")
+	inputFile := flag.String("input", "", "Complete each prefix in this file (one per line) in CLI mode, then exit")
+	benchMode := flag.Bool("bench", false, "Time completions of a prefix workload (-input or a built-in one) and print latency percentiles")
+	benchIterations := flag.Int("bench-iter", 1000, "Times -bench completes each prefix")
+	buildMode := flag.Bool("build", false, "Build every dictionary chunk of -chunk words in the data dir from its words.txt, then exit")
 
 	flag.Parse()
 
@@ -136,9 +248,42 @@ func main() {
 	log.Debugf("Using data dir at: %s", resolvedDataDir)
 	log.Debugf("Init completer: maxWords=[%d], chunkSize=[%d]", *wordLimit, *chunkSize)
 
-	completer := completion.NewLazyCompleter(resolvedDataDir, *chunkSize, *wordLimit)
+	appConfig, configPath, err := config.LoadConfigWithPriority(*configFile)
+	if err != nil {
+		log.Fatalf("Failed to load config: %v", err)
+		os.Exit(1)
+	}
+	log.Debugf("Using config file: %s", configPath)
 
-	if *binaryDir != "" {
+	if *buildMode {
+		wordsPath := filepath.Join(resolvedDataDir, "words.txt")
+		if err := dictionary.BuildChunksWithWorkers(wordsPath, resolvedDataDir, *chunkSize, 0, appConfig.Dict.BuildWorkers); err != nil {
+			log.Fatalf("Failed to build dictionary: %v", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	var completer *completion.Completer
+	if *textDict != "" {
+		completer, err = newTextCompleter(appConfig, *textDict)
+		if err != nil {
+			log.Fatalf("Failed to load text dictionary: %v", err)
+			os.Exit(1)
+		}
+	} else {
+		completer = newCompleter(appConfig, resolvedDataDir, *chunkSize, *wordLimit, *hotCache)
+		completer.SetUserWordsPath(appConfig.Dict.UserWordsPath)
+	}
+	if path := appConfig.Dict.BlacklistPath; path != "" {
+		if err := completer.Blacklist().Load(path); err != nil {
+			log.Warnf("Continuing without blacklist: %v", err)
+		}
+	}
+
+	if *textDict != "" {
+		log.Debugf("Using text dictionary at: %s", *textDict)
+	} else if *binaryDir != "" {
 		err := completer.Initialize()
 		if err != nil {
 			log.Fatalf("Failed to init completer: %v", err)
@@ -152,7 +297,29 @@ func main() {
 	// CLI would be mainly used for testing and dbg purposes.
 	// Any new features or changes should be tested in CLI mode first.
 	// NOTE: Server interface has vastly different parameters compared to CLI and what it accepts.
-	if *cliMode {
+	if *serveMode && !*cliMode {
+		log.Fatal("-serve needs -c, use -http alone to only serve HTTP")
+		os.Exit(1)
+	}
+	if *cliMode || *inputFile != "" || *benchMode {
+		if err := cli.ValidatePrefixRange(*minPrefix, *maxPrefix); err != nil {
+			log.Fatalf("Invalid prefix length flags: %v", err)
+			os.Exit(1)
+		}
+		// stdin belongs to the CLI, so the server next to it speaks HTTP
+		if *serveMode {
+			addr := *httpAddr
+			if addr == "" {
+				addr = devServeAddr
+			}
+			srv := newServer(appConfig, configPath, completer, *chunkSize, *wordLimit, *hotCache)
+			go func() {
+				if err := srv.StartHTTP(addr); err != nil {
+					log.Errorf("HTTP server stopped: %v", err)
+				}
+			}()
+			log.Printf("Serving HTTP on %s next to the CLI", addr)
+		}
 		log.SetReportTimestamp(false)
 		log.Debug("Input info:",
 			"minPrefix", *minPrefix,
@@ -161,6 +328,30 @@ func main() {
 			"noFilter", *noFilter)
 
 		inputHandler := cli.NewInputHandler(completer, *minPrefix, *maxPrefix, *limit, *noFilter)
+		digits, _ := utils.ParseDigitMode(appConfig.Server.DigitMode)
+		inputHandler.SetQuiet(*quiet)
+		inputHandler.SetInputFilter(utils.InputFilter{Digits: digits, AllowSymbols: appConfig.Server.AllowSymbols})
+		if *benchMode {
+			var prefixes []string
+			if *inputFile != "" {
+				if prefixes, err = cli.ReadPrefixes(*inputFile); err != nil {
+					log.Fatalf("CLI error: %v", err)
+					os.Exit(1)
+				}
+			}
+			if err := inputHandler.Benchmark(prefixes, *benchIterations); err != nil {
+				log.Fatalf("CLI error: %v", err)
+				os.Exit(1)
+			}
+			return
+		}
+		if *inputFile != "" {
+			if err := inputHandler.RunFile(*inputFile); err != nil {
+				log.Fatalf("CLI error: %v", err)
+				os.Exit(1)
+			}
+			return
+		}
 		if err := inputHandler.Start(); err != nil {
 			log.Fatalf("CLI error: %v", err)
 			os.Exit(1)
@@ -170,16 +361,25 @@ func main() {
 
 	log.Debug("spawning IPC")
 
-	appConfig, configPath, err := config.LoadConfigWithPriority(*configFile)
-	if err != nil {
-		log.Fatalf("Failed to load config: %v", err)
-		os.Exit(1)
+	srv := newServer(appConfig, configPath, completer, *chunkSize, *wordLimit, *hotCache)
+
+	var loader *dictionary.Loader
+	if *binaryDir != "" && *textDict == "" {
+		loader = completer.GetChunkLoader()
 	}
-	log.Debugf("Using config file: %s", configPath)
-	srv := server.NewServer(completer, appConfig, configPath)
+	showStartupInfo(resolvedDataDir, loader)
 
-	showStartupInfo(resolvedDataDir)
+	if *httpAddr != "" {
+		if err := srv.StartHTTP(*httpAddr); err != nil {
+			log.Fatalf("Failed to start HTTP server: %v", err)
+			os.Exit(1)
+		}
+		return
+	}
 
+	// answer the requests already read before a signal ends the process
+	shutdown := srv.Shutdown
+	beforeExit.Store(&shutdown)
 	if err := srv.Start(); err != nil {
 		log.Fatalf("Failed to start server: %v", err)
 		os.Exit(1)
@@ -187,7 +387,8 @@ func main() {
 }
 
 // showStartupInfo displays some basic info about the init process.
-func showStartupInfo(dataDir string) {
+// It waits up to readyTimeout for the initial chunks, so the status reflects what is loaded.
+func showStartupInfo(dataDir string, loader *dictionary.Loader) {
 	pid := os.Getpid()
 	currentLevel := log.GetLevel()
 	log.SetLevel(log.InfoLevel)
@@ -199,9 +400,26 @@ func showStartupInfo(dataDir string) {
 	log.Infof("Process ID: [ %d ]", pid)
 	log.Info("init: OK")
 	log.Infof("data dir: ( %s )", dataDir)
-	log.Info("status: ready")
+	showLoadStatus(loader)
 	println("===========")
 	println("Press Ctrl+C to exit")
 
 	log.SetLevel(currentLevel)
 }
+
+// showLoadStatus waits for the initial dictionary chunks and logs how far loading got
+func showLoadStatus(loader *dictionary.Loader) {
+	if loader == nil {
+		log.Info("status: ready (no chunk files)")
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), readyTimeout)
+	defer cancel()
+	err := loader.WaitUntilReady(ctx)
+	stats := loader.GetStats()
+	if err != nil {
+		log.Infof("status: loading (%d/%d chunks so far)", stats.LoadedChunks, stats.AvailableChunks)
+		return
+	}
+	log.Infof("status: ready (%s words in %d chunks)", utils.FormatWithCommas(stats.LoadedWords), stats.LoadedChunks)
+}
@@ -0,0 +1,190 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bastiangx/wordserve/pkg/config"
+	"github.com/bastiangx/wordserve/pkg/dictionary"
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+// buildServer builds the wordserve binary into a temp dir and returns its path
+func buildServer(t *testing.T) string {
+	t.Helper()
+	bin := filepath.Join(t.TempDir(), "wordserve")
+	if runtime.GOOS == "windows" {
+		bin += ".exe"
+	}
+	build := exec.Command(filepath.Join(runtime.GOROOT(), "bin", "go"), "build", "-o", bin, ".")
+	if out, err := build.CombinedOutput(); err != nil {
+		t.Fatalf("go build failed: %v\n%s", err, out)
+	}
+	return bin
+}
+
+// writeDataDir builds chunks of 100 words into a temp dir, hello, help and
+// helmet being the most frequent words, and returns the dir
+func writeDataDir(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	var lines strings.Builder
+	for i, word := range []string{"hello", "help", "helmet", "world"} {
+		fmt.Fprintf(&lines, "%s\t%d\n", word, 1000-i)
+	}
+	for i := range 296 {
+		fmt.Fprintf(&lines, "filler%03d\t%d\n", i, 500-i)
+	}
+	wordsPath := filepath.Join(dir, "words.txt")
+	if err := os.WriteFile(wordsPath, []byte(lines.String()), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := dictionary.BuildChunks(wordsPath, dir, 100, 0); err != nil {
+		t.Fatal(err)
+	}
+	return dir
+}
+
+// client speaks msgpack to a running server over its stdin and stdout
+type client struct {
+	t       *testing.T
+	encoder *msgpack.Encoder
+	decoder *msgpack.Decoder
+}
+
+// send writes request and returns the next response
+func (c *client) send(request map[string]any) map[string]any {
+	c.t.Helper()
+	if err := c.encoder.Encode(request); err != nil {
+		c.t.Fatalf("sending %v: %v", request, err)
+	}
+	var response map[string]any
+	if err := c.decoder.Decode(&response); err != nil {
+		c.t.Fatalf("reading the response to %v: %v", request, err)
+	}
+	if response["id"] != request["id"] {
+		c.t.Fatalf("response %v doesn't answer request %v", response, request)
+	}
+	return response
+}
+
+// suggestions returns the words of a completion response
+func suggestions(t *testing.T, response map[string]any) []string {
+	t.Helper()
+	list, ok := response["s"].([]any)
+	if !ok {
+		t.Fatalf("response %v has no suggestions", response)
+	}
+	var words []string
+	for _, item := range list {
+		words = append(words, item.(map[string]any)["w"].(string))
+	}
+	return words
+}
+
+func TestServerRoundTrip(t *testing.T) {
+	if testing.Short() {
+		t.Skip("builds and runs the server")
+	}
+	bin := buildServer(t)
+	dataDir := writeDataDir(t)
+	release := httptest.NewServer(http.NotFoundHandler())
+	defer release.Close()
+
+	cfg := config.DefaultConfig()
+	cfg.Dict.MaxWords = 300
+	cfg.Dict.ChunkSize = 100
+	cfg.Dict.ReleaseURL = release.URL
+	configPath := filepath.Join(t.TempDir(), "config.toml")
+	if err := config.SaveConfig(cfg, configPath); err != nil {
+		t.Fatal(err)
+	}
+
+	cmd := exec.Command(bin, "-config", configPath, "-data", dataDir, "-words", "300", "-chunk", "100")
+	cmd.Env = append(os.Environ(), "XDG_CONFIG_HOME="+t.TempDir())
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var stderr strings.Builder
+	cmd.Stderr = &stderr
+	if err := cmd.Start(); err != nil {
+		t.Fatal(err)
+	}
+	// A server that stops answering fails the test instead of hanging it
+	watchdog := time.AfterFunc(30*time.Second, func() { cmd.Process.Kill() })
+	defer watchdog.Stop()
+	defer func() {
+		if t.Failed() {
+			t.Logf("server stderr:\n%s", stderr.String())
+		}
+	}()
+
+	c := &client{t: t, encoder: msgpack.NewEncoder(stdin), decoder: msgpack.NewDecoder(bufio.NewReader(stdout))}
+
+	if got, want := suggestions(t, c.send(map[string]any{"id": "1", "p": "hel", "l": 5})), []string{"hello", "help", "helmet"}; !slices.Equal(got, want) {
+		t.Errorf("completing hel = %v, want %v", got, want)
+	}
+	if got := suggestions(t, c.send(map[string]any{"id": "2", "p": "Wor", "l": 5})); !slices.Equal(got, []string{"World"}) {
+		t.Errorf("completing Wor = %v, want [World]", got)
+	}
+
+	// Dictionary actions
+	if response := c.send(map[string]any{"id": "3", "action": "add_word", "word": "helicopter", "freq": 100000}); response["status"] != "ok" {
+		t.Errorf("add_word = %v", response)
+	}
+	if got := suggestions(t, c.send(map[string]any{"id": "4", "p": "hel", "l": 1})); !slices.Equal(got, []string{"helicopter"}) {
+		t.Errorf("completing hel after add_word = %v, want [helicopter]", got)
+	}
+	if response := c.send(map[string]any{"id": "5", "action": "remove_word", "word": "helicopter"}); response["status"] != "ok" {
+		t.Errorf("remove_word = %v", response)
+	}
+	if response := c.send(map[string]any{"id": "6", "action": "get_info"}); response["status"] != "ok" || fmt.Sprint(response["available_chunks"]) != "3" {
+		t.Errorf("get_info = %v, want 3 available chunks", response)
+	}
+
+	// Config actions
+	if response := c.send(map[string]any{"id": "7", "action": "get_config_path"}); response["config_path"] == nil {
+		t.Errorf("get_config_path = %v, want the path", response)
+	}
+	if response := c.send(map[string]any{"id": "8", "action": "set_config", "max_limit": 2}); response["status"] != "ok" {
+		t.Errorf("set_config = %v", response)
+	}
+	if got := suggestions(t, c.send(map[string]any{"id": "9", "p": "hel", "l": 10})); len(got) != 2 {
+		t.Errorf("completing hel with max_limit 2 = %v, want 2 words", got)
+	}
+
+	// Errors
+	if response := c.send(map[string]any{"id": "10", "p": ""}); fmt.Sprint(response["c"]) != "400" {
+		t.Errorf("empty prefix = %v, want a 400 error", response)
+	}
+	if response := c.send(map[string]any{"id": "11", "action": "remove_word", "word": "nonexistent"}); response["status"] != "error" {
+		t.Errorf("removing a missing word = %v, want an error", response)
+	}
+
+	if response := c.send(map[string]any{"id": "bye", "action": "shutdown"}); response["status"] != "ok" {
+		t.Errorf("shutdown = %v", response)
+	}
+	stdin.Close()
+	if rest, _ := io.ReadAll(stdout); len(rest) != 0 {
+		t.Errorf("%d bytes written after the shutdown acknowledgement", len(rest))
+	}
+	if err := cmd.Wait(); err != nil {
+		t.Errorf("server exited with %v", err)
+	}
+}
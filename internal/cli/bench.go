@@ -0,0 +1,64 @@
+package cli
+
+import (
+	"fmt"
+	"runtime"
+	"slices"
+	"time"
+)
+
+// defaultBenchPrefixes is the workload of -bench when no -input file is given:
+// short, common and longer prefixes, so both wide and narrow subtrees are walked.
+var defaultBenchPrefixes = []string{
+	"a", "s", "t", "th", "the", "wh", "wo", "wor", "in", "re",
+	"con", "pro", "com", "inter", "hel", "qu", "ex", "un", "str", "ca",
+}
+
+// Benchmark completes every prefix iterations times with the handler's limit and
+// prints latency percentiles and allocations per completion.
+// Prefixes default to a fixed workload when empty. One untimed pass runs first,
+// so loading and cache population don't count.
+func (h *InputHandler) Benchmark(prefixes []string, iterations int) error {
+	if len(prefixes) == 0 {
+		prefixes = defaultBenchPrefixes
+	}
+	if iterations < 1 {
+		return fmt.Errorf("-bench-iter must be at least 1, got %d", iterations)
+	}
+	if err := h.waitForChunks(); err != nil {
+		return err
+	}
+	for _, prefix := range prefixes {
+		h.completer.Complete(prefix, h.suggestLimit)
+	}
+
+	latencies := make([]time.Duration, 0, iterations*len(prefixes))
+	var before, after runtime.MemStats
+	runtime.GC()
+	runtime.ReadMemStats(&before)
+	for range iterations {
+		for _, prefix := range prefixes {
+			start := time.Now()
+			h.completer.Complete(prefix, h.suggestLimit)
+			latencies = append(latencies, time.Since(start))
+		}
+	}
+	runtime.ReadMemStats(&after)
+
+	ops := len(latencies)
+	slices.Sort(latencies)
+	fmt.Fprintf(h.out, "%d completions (%d prefixes x %d), limit %d\n", ops, len(prefixes), iterations, h.suggestLimit)
+	fmt.Fprintf(h.out, "%-10s %12s\n", "p50", percentile(latencies, 50))
+	fmt.Fprintf(h.out, "%-10s %12s\n", "p90", percentile(latencies, 90))
+	fmt.Fprintf(h.out, "%-10s %12s\n", "p99", percentile(latencies, 99))
+	fmt.Fprintf(h.out, "%-10s %12s\n", "max", latencies[ops-1])
+	fmt.Fprintf(h.out, "%-10s %12d\n", "allocs/op", (after.Mallocs-before.Mallocs)/uint64(ops))
+	fmt.Fprintf(h.out, "%-10s %12d\n", "B/op", (after.TotalAlloc-before.TotalAlloc)/uint64(ops))
+	return nil
+}
+
+// percentile returns the p-th percentile of sorted latencies, nearest rank
+func percentile(sorted []time.Duration, p int) time.Duration {
+	rank := (len(sorted)*p + 99) / 100
+	return sorted[max(rank, 1)-1]
+}
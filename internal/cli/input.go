@@ -3,14 +3,19 @@ package cli
 
 import (
 	"bufio"
+	"context"
+	"errors"
 	"fmt"
+	"io"
 	"os"
 	"strings"
 	"time"
 
 	"github.com/bastiangx/wordserve/internal/utils"
+	"github.com/bastiangx/wordserve/pkg/dictionary"
 	completion "github.com/bastiangx/wordserve/pkg/suggest"
 	"github.com/charmbracelet/log"
+	"github.com/charmbracelet/x/term"
 )
 
 // InputHandler processes user input from stdin, providing
@@ -23,40 +28,193 @@ type InputHandler struct {
 	suggestLimit    int
 	requestCount    int
 	noFilter        bool
+	inputFilter     utils.InputFilter
+	quiet           bool
+	out             io.Writer // results, while prompts and logs go to stderr
+	color           bool
 }
 
-// NewInputHandler handles initialization of the InputHandler with basic parameters
+// ValidatePrefixRange checks the prefix length flags, 1 <= prmin <= prmax.
+// Flags set the wrong way round would reject every prefix without saying why.
+func ValidatePrefixRange(minLength, maxLength int) error {
+	if minLength < 1 {
+		return fmt.Errorf("-prmin must be at least 1, got %d", minLength)
+	}
+	if maxLength < minLength {
+		return fmt.Errorf("-prmax (%d) must not be less than -prmin (%d), were they swapped?", maxLength, minLength)
+	}
+	return nil
+}
+
+// NewInputHandler handles initialization of the InputHandler with basic parameters.
+// An invalid prefix range is clamped to 1 <= minLength <= maxLength, with a warning.
 func NewInputHandler(completer completion.ICompleter, minLength, maxLength, limit int, noFilter bool) *InputHandler {
+	if err := ValidatePrefixRange(minLength, maxLength); err != nil {
+		minLength = max(minLength, 1)
+		maxLength = max(maxLength, minLength)
+		log.Warnf("%v, using prefix lengths %d to %d", err, minLength, maxLength)
+	}
 	return &InputHandler{
 		completer:       completer,
 		minPrefixLength: minLength,
 		maxPrefixLength: maxLength,
 		suggestLimit:    limit,
 		noFilter:        noFilter,
+		out:             os.Stdout,
+		color:           term.IsTerminal(os.Stdout.Fd()),
+	}
+}
+
+// SetQuiet turns off the banner and prompts, leaving only results on stdout
+// and logs on stderr, for scripts driving the CLI.
+func (h *InputHandler) SetQuiet(quiet bool) {
+	h.quiet = quiet
+}
+
+// chrome prints UI text, the banner and hints, to stderr unless quiet
+func (h *InputHandler) chrome(text string) {
+	if !h.quiet {
+		fmt.Fprintln(os.Stderr, text)
 	}
 }
 
+// SetInputFilter sets what the input filter lets through, the strict default InputFilter otherwise.
+func (h *InputHandler) SetInputFilter(filter utils.InputFilter) {
+	h.inputFilter = filter
+}
+
+// chunksCommand prints per-chunk stats instead of completing
+const chunksCommand = ":chunks"
+
 // Start begins the interface loop.
 // It continuously prompts for input, reads a line from stdin,
 // and passes the trimmed input to the handleInput() for processing.
-// Loop terminates if an error occurs while reading from stdin
+// On a terminal, lines are read with history and Tab completion instead.
+// Loop terminates at the end of stdin or if an error occurs while reading from it
 func (h *InputHandler) Start() error {
-	log.Print("WordServe CLI [BETA]")
+	h.chrome("WordServe CLI [BETA]")
+	if isTerminal() {
+		return h.startInteractive()
+	}
+	// Piped input is there before the chunks load, wait so its first prefixes see them
+	if err := h.waitForChunks(); err != nil {
+		return err
+	}
 	reader := bufio.NewReader(os.Stdin)
-	log.Print("type something and press Enter to see the suggestions (Ctrl+C to exit):")
+	h.chrome("type something and press Enter to see the suggestions (Ctrl+C to exit):")
 
 	for {
-		log.Print("> ")
+		if !h.quiet {
+			fmt.Fprint(os.Stderr, "> ")
+		}
 		prefix, err := reader.ReadString('\n')
+		if errors.Is(err, io.EOF) {
+			h.handleLine(prefix)
+			return nil
+		}
 		if err != nil {
 			return err
 		}
-		prefix = strings.TrimSpace(prefix)
-		if prefix == "" {
+		h.handleLine(prefix)
+	}
+}
+
+// RunFile completes each prefix in the file at path, one per line, then returns.
+// Blank lines and lines starting with # are skipped, as in the blacklist file.
+// It waits for the initial chunks first, so early prefixes see the whole dictionary.
+func (h *InputHandler) RunFile(path string) error {
+	prefixes, err := ReadPrefixes(path)
+	if err != nil {
+		return err
+	}
+	if err := h.waitForChunks(); err != nil {
+		return err
+	}
+	for _, prefix := range prefixes {
+		h.handleLine(prefix)
+	}
+	return nil
+}
+
+// ReadPrefixes reads the prefixes of an -input file, one per line,
+// skipping blank lines and lines starting with #.
+func ReadPrefixes(path string) ([]string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open input file: %w", err)
+	}
+	defer file.Close()
+
+	var prefixes []string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
 			continue
 		}
-		h.handleInput(prefix)
+		prefixes = append(prefixes, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read input file: %w", err)
+	}
+	return prefixes, nil
+}
+
+// waitForChunks blocks until the initial chunks are loaded, when the completer has a chunk loader
+func (h *InputHandler) waitForChunks() error {
+	lazy, ok := h.completer.(interface{ GetChunkLoader() *dictionary.Loader })
+	if !ok || lazy.GetChunkLoader() == nil {
+		return nil
 	}
+	return lazy.GetChunkLoader().WaitUntilReady(context.Background())
+}
+
+// startInteractive runs the loop with line editing when stdin is a terminal.
+// Up/down recall earlier prefixes and Tab fills in the top suggestion.
+// Ctrl+C or Ctrl+D on an empty line end it.
+func (h *InputHandler) startInteractive() error {
+	h.chrome("type something and press Enter to see the suggestions, Tab to fill in the top one (Ctrl+C to exit):")
+	editor := newLineEditor(h.topSuggestion)
+	prompt := "> "
+	if h.quiet {
+		prompt = ""
+	}
+	for {
+		prefix, err := editor.readLine(prompt)
+		if errors.Is(err, errInterrupted) || errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		h.handleLine(prefix)
+	}
+}
+
+// topSuggestion returns the best completion of prefix, for Tab
+func (h *InputHandler) topSuggestion(prefix string) (string, bool) {
+	prefix = strings.TrimSpace(prefix)
+	if len(prefix) < h.minPrefixLength || len(prefix) > h.maxPrefixLength {
+		return "", false
+	}
+	suggestions := h.completer.Complete(prefix, 1)
+	if len(suggestions) == 0 {
+		return "", false
+	}
+	return suggestions[0].Word, true
+}
+
+// handleLine runs a command or completes the prefix typed on one line
+func (h *InputHandler) handleLine(prefix string) {
+	prefix = strings.TrimSpace(prefix)
+	if prefix == "" {
+		return
+	}
+	if prefix == chunksCommand {
+		h.printChunkStats()
+		return
+	}
+	h.handleInput(prefix)
 }
 
 // handleInput processes a single prefix to generate suggestions.
@@ -83,8 +241,8 @@ func (h *InputHandler) handleInput(prefix string) {
 
 	// input filtering by default (unless --no-filter flag is used)
 	if !h.noFilter {
-		if !utils.IsValidInput(prefix) {
-			log.Info("No results found for prefix: '%s'", prefix)
+		if !utils.IsValidInputWith(prefix, h.inputFilter) {
+			log.Infof("No results found for prefix: '%s'", prefix)
 			return
 		}
 	} else {
@@ -106,10 +264,45 @@ func (h *InputHandler) handleInput(prefix string) {
 		return
 	}
 
-	log.Printf("Found %d suggestions for prefix '%s':", len(suggestions), prefix)
+	fmt.Fprintf(h.out, "Found %d suggestions for prefix '%s':\n", len(suggestions), prefix)
 	for i, s := range suggestions {
 		fmtFreq := utils.FormatWithCommas(s.Frequency)
-		clWord := fmt.Sprintf("\033[38;5;75m%s\033[0m", s.Word)
-		log.Printf("%2d. %-40s (freq: %8s)", i+1, clWord, fmtFreq)
+		word := fmt.Sprintf("%-26s", s.Word)
+		if h.color {
+			word = fmt.Sprintf("\033[38;5;75m%s\033[0m", word)
+		}
+		fmt.Fprintf(h.out, "%2d. %s (freq: %8s)\n", i+1, word, fmtFreq)
+	}
+}
+
+// printChunkStats lists every chunk with the words it holds and contributes, for
+// checking where memory goes. Needs a completer backed by chunk files.
+func (h *InputHandler) printChunkStats() {
+	lazy, ok := h.completer.(interface{ GetChunkLoader() *dictionary.Loader })
+	if !ok || lazy.GetChunkLoader() == nil {
+		log.Warn("No chunk loader, the dictionary isn't loaded from chunk files")
+		return
+	}
+	stats := lazy.GetChunkLoader().GetChunkStats()
+	if len(stats) == 0 {
+		log.Warn("No chunks found")
+		return
+	}
+	total := 0
+	for _, stat := range stats {
+		state := "unloaded"
+		if stat.Partial {
+			state = "partial"
+		} else if stat.Loaded {
+			state = "loaded"
+		}
+		total += stat.WordCount
+		scores := ""
+		if stat.Loaded {
+			scores = fmt.Sprintf("  scores %d..%d", stat.MinScore, stat.MaxScore)
+		}
+		fmt.Fprintf(h.out, "chunk %4d  %-8s  %8s / %8s words%s\n", stat.ID, state,
+			utils.FormatWithCommas(stat.WordCount), utils.FormatWithCommas(stat.FileWords), scores)
 	}
+	fmt.Fprintf(h.out, "%s words loaded from %d chunks\n", utils.FormatWithCommas(total), len(stats))
 }
@@ -0,0 +1,172 @@
+package cli
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/charmbracelet/x/term"
+)
+
+// errInterrupted is returned by readLine when Ctrl+C is pressed. The terminal is in raw
+// mode while a line is edited, so Ctrl+C arrives as a key instead of a signal.
+var errInterrupted = errors.New("interrupted")
+
+// maxHistory is how many entered lines the editor keeps for recall
+const maxHistory = 200
+
+// lineEditor reads lines from a terminal with basic editing: left/right to move,
+// up/down to recall earlier lines and Tab to replace the input with a completion.
+// It is only used when stdin is a TTY, otherwise lines are read as they come.
+type lineEditor struct {
+	fd       uintptr
+	in       *bufio.Reader
+	out      io.Writer
+	history  []string
+	complete func(prefix string) (string, bool)
+}
+
+func newLineEditor(complete func(prefix string) (string, bool)) *lineEditor {
+	return &lineEditor{
+		fd:       os.Stdin.Fd(),
+		in:       bufio.NewReader(os.Stdin),
+		out:      os.Stderr,
+		complete: complete,
+	}
+}
+
+// isTerminal reports whether stdin is an interactive terminal
+func isTerminal() bool {
+	return term.IsTerminal(os.Stdin.Fd())
+}
+
+// readLine shows prompt and returns the line typed, without the newline.
+// Ctrl+D on an empty line returns io.EOF, Ctrl+C returns errInterrupted.
+func (e *lineEditor) readLine(prompt string) (string, error) {
+	state, err := term.MakeRaw(e.fd)
+	if err != nil {
+		return "", err
+	}
+	defer term.Restore(e.fd, state)
+
+	var line []rune
+	cursor := 0
+	recall := len(e.history) // index into history, len(history) is the line being typed
+	draft := ""
+	redraw := func() {
+		fmt.Fprintf(e.out, "\r\033[K%s%s", prompt, string(line))
+		if back := len(line) - cursor; back > 0 {
+			fmt.Fprintf(e.out, "\033[%dD", back)
+		}
+	}
+	setLine := func(text string) {
+		line = []rune(text)
+		cursor = len(line)
+		redraw()
+	}
+	redraw()
+
+	for {
+		r, _, err := e.in.ReadRune()
+		if err != nil {
+			return "", err
+		}
+		switch r {
+		case '\r', '\n':
+			fmt.Fprint(e.out, "\r\n")
+			text := string(line)
+			e.remember(text)
+			return text, nil
+		case 3: // Ctrl+C
+			fmt.Fprint(e.out, "\r\n")
+			return "", errInterrupted
+		case 4: // Ctrl+D
+			if len(line) == 0 {
+				fmt.Fprint(e.out, "\r\n")
+				return "", io.EOF
+			}
+		case 127, 8: // Backspace
+			if cursor > 0 {
+				line = append(line[:cursor-1], line[cursor:]...)
+				cursor--
+				redraw()
+			}
+		case '\t':
+			if completion, ok := e.complete(string(line)); ok {
+				setLine(completion)
+			}
+		case 27: // escape sequence, arrow keys are ESC [ A..D or ESC O A..D
+			switch e.readEscape() {
+			case 'A': // up
+				if recall > 0 {
+					if recall == len(e.history) {
+						draft = string(line)
+					}
+					recall--
+					setLine(e.history[recall])
+				}
+			case 'B': // down
+				if recall < len(e.history) {
+					recall++
+					if recall == len(e.history) {
+						setLine(draft)
+					} else {
+						setLine(e.history[recall])
+					}
+				}
+			case 'C': // right
+				if cursor < len(line) {
+					cursor++
+					redraw()
+				}
+			case 'D': // left
+				if cursor > 0 {
+					cursor--
+					redraw()
+				}
+			}
+		default:
+			if r < ' ' {
+				continue
+			}
+			line = append(line[:cursor], append([]rune{r}, line[cursor:]...)...)
+			cursor++
+			redraw()
+		}
+	}
+}
+
+// readEscape reads the rest of an escape sequence and returns its final byte, so keys
+// with parameters, e.g. ESC [ 1 ; 5 C, don't leave stray characters in the line
+func (e *lineEditor) readEscape() rune {
+	prefix, _, err := e.in.ReadRune()
+	if err != nil || (prefix != '[' && prefix != 'O') {
+		return 0
+	}
+	for {
+		r, _, err := e.in.ReadRune()
+		if err != nil {
+			return 0
+		}
+		// parameter and intermediate bytes come before the final one
+		if r >= 0x40 && r <= 0x7e {
+			return r
+		}
+		if r < 0x20 || r > 0x3f {
+			return 0
+		}
+	}
+}
+
+// remember adds a line to the history, skipping blanks and repeats of the last line
+func (e *lineEditor) remember(text string) {
+	if text == "" || (len(e.history) > 0 && e.history[len(e.history)-1] == text) {
+		return
+	}
+	e.history = append(e.history, text)
+	if len(e.history) > maxHistory {
+		e.history = e.history[len(e.history)-maxHistory:]
+	}
+}
@@ -2,6 +2,8 @@
 package utils
 
 import (
+	"fmt"
+	"regexp"
 	"strings"
 	"unicode"
 	"unicode/utf8"
@@ -73,21 +75,146 @@ func ContainsSpecialChars(s string) bool {
 	return false
 }
 
-// IsRepetitive checks if a string consists of repetitive characters
+// IsRepetitive checks if a string is one character or one short pattern
+// repeated, like "aaa", "héhéhé", "ααα" or "abcabc". Patterns are cut at rune
+// boundaries, so repeated multi-byte characters are detected too, and the
+// string is compared in place without allocating, as this runs per request.
 func IsRepetitive(s string) bool {
-	if len(s) <= 2 {
+	if utf8.RuneCountInString(s) <= 2 {
 		return false
 	}
-	firstChar := s[0]
-	for i := 1; i < len(s); i++ {
-		if s[i] != firstChar {
+	// k is the byte length of a pattern of whole runes, it only needs to go up to half the string
+	for k := range s {
+		if k == 0 {
+			continue
+		}
+		if k > len(s)/2 {
+			break
+		}
+		if len(s)%k == 0 && repeatsWithPeriod(s, k) {
+			return true
+		}
+	}
+	return false
+}
+
+// repeatsWithPeriod reports whether s is its first period bytes repeated
+func repeatsWithPeriod(s string, period int) bool {
+	for i := period; i < len(s); i += period {
+		if s[i:i+period] != s[:period] {
 			return false
 		}
 	}
 	return true
 }
 
-// IsValidInput checks if input should be processed at all.
+// DigitMode selects how the input filter treats digits in a prefix.
+type DigitMode int
+
+const (
+	// DigitsMixed accepts digits next to letters, so "word2" completes to "word2vec",
+	// but rejects prefixes made only of digits, like "2024". The default.
+	DigitsMixed DigitMode = iota
+	// DigitsAny also accepts digit-only prefixes, so "3" completes to "3d".
+	DigitsAny
+	// DigitsNone rejects every prefix containing a digit.
+	DigitsNone
+)
+
+// String returns the config name of the mode.
+func (m DigitMode) String() string {
+	switch m {
+	case DigitsAny:
+		return "any"
+	case DigitsNone:
+		return "none"
+	}
+	return "mixed"
+}
+
+// ParseDigitMode parses a config value ("mixed", "any" or "none").
+// An empty string is the default DigitsMixed.
+func ParseDigitMode(s string) (DigitMode, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "", "mixed":
+		return DigitsMixed, nil
+	case "any":
+		return DigitsAny, nil
+	case "none":
+		return DigitsNone, nil
+	}
+	return DigitsMixed, fmt.Errorf("unknown digit mode %q, expected mixed, any or none", s)
+}
+
+// InputFilter relaxes the checks of [IsValidInputWith].
+// The zero value is the default, strict filter.
+type InputFilter struct {
+	// Digits selects how digits in a prefix are treated
+	Digits DigitMode
+	// AllowSymbols accepts characters other than letters, digits and separators,
+	// so "c+" completes to "c++"
+	AllowSymbols bool
+}
+
+// IsValidInput checks if input should be processed at all, with the default InputFilter.
 func IsValidInput(s string) bool {
-	return len(s) > 0 && !IsOnlyNumbers(s) && !ContainsSpecialChars(s) && !IsRepetitive(s)
+	return IsValidInputWith(s, InputFilter{})
+}
+
+// IsValidInputWith checks if input should be processed at all, relaxed as filter allows.
+// Separators and repeated patterns are treated the same with every filter.
+func IsValidInputWith(s string, filter InputFilter) bool {
+	if len(s) == 0 || IsRepetitive(s) {
+		return false
+	}
+	if !filter.AllowSymbols && ContainsSpecialChars(s) {
+		return false
+	}
+	switch filter.Digits {
+	case DigitsAny:
+		return true
+	case DigitsNone:
+		return !ContainsNumbers(s)
+	}
+	return !IsOnlyNumbers(s)
+}
+
+// InputPatterns holds optional allow and deny regexes for prefixes and suggestions.
+// A nil pattern is not checked.
+type InputPatterns struct {
+	Allow *regexp.Regexp
+	Deny  *regexp.Regexp
+}
+
+// CompileInputPatterns compiles the allow and deny expressions, empty ones are left nil.
+func CompileInputPatterns(allow, deny string) (*InputPatterns, error) {
+	patterns := &InputPatterns{}
+	var err error
+	if allow != "" {
+		if patterns.Allow, err = regexp.Compile(allow); err != nil {
+			return nil, fmt.Errorf("invalid allow pattern: %w", err)
+		}
+	}
+	if deny != "" {
+		if patterns.Deny, err = regexp.Compile(deny); err != nil {
+			return nil, fmt.Errorf("invalid deny pattern: %w", err)
+		}
+	}
+	return patterns, nil
+}
+
+// Active reports whether any pattern is set.
+func (p *InputPatterns) Active() bool {
+	return p != nil && (p.Allow != nil || p.Deny != nil)
+}
+
+// Match checks s against the allow pattern, if set, and then the deny pattern, if set.
+func (p *InputPatterns) Match(s string) bool {
+	if p == nil {
+		return true
+	}
+	if p.Allow != nil && !p.Allow.MatchString(s) {
+		return false
+	}
+	return p.Deny == nil || !p.Deny.MatchString(s)
 }
@@ -0,0 +1,98 @@
+package utils
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestIsValidInputWith(t *testing.T) {
+	strict := InputFilter{}
+	symbols := InputFilter{AllowSymbols: true}
+	anyDigits := InputFilter{Digits: DigitsAny}
+	noDigits := InputFilter{Digits: DigitsNone}
+	tests := []struct {
+		input  string
+		filter InputFilter
+		want   bool
+	}{
+		{"utf8", strict, true},
+		{"utf8", noDigits, false},
+		{"word2vec", strict, true},
+		{"2024", strict, false},
+		{"2024", anyDigits, true},
+		{"2024", noDigits, false},
+		{"c++", strict, false},
+		{"c++", symbols, true},
+		{"c++", anyDigits, false},
+		{"user-name", strict, true},
+		{"user-name", noDigits, true},
+		{"user_name.go", strict, true},
+		{"", symbols, false},
+		{"aaaa", symbols, false},
+	}
+	for _, tt := range tests {
+		if got := IsValidInputWith(tt.input, tt.filter); got != tt.want {
+			t.Errorf("IsValidInputWith(%q, %+v) = %t, want %t", tt.input, tt.filter, got, tt.want)
+		}
+	}
+}
+
+func TestParseDigitMode(t *testing.T) {
+	for input, want := range map[string]DigitMode{"": DigitsMixed, "mixed": DigitsMixed, " Any ": DigitsAny, "none": DigitsNone} {
+		got, err := ParseDigitMode(input)
+		if err != nil || got != want {
+			t.Errorf("ParseDigitMode(%q) = %v, %v, want %v", input, got, err, want)
+		}
+		if input != "" && got.String() != strings.ToLower(strings.TrimSpace(input)) {
+			t.Errorf("%v.String() = %q, want %q", got, got.String(), input)
+		}
+	}
+	if _, err := ParseDigitMode("some"); err == nil {
+		t.Error("ParseDigitMode(\"some\") succeeded, want an error")
+	}
+}
+
+func TestFilterNonLatinInput(t *testing.T) {
+	tests := []struct {
+		input                 string
+		repetitive, onlyDigit bool
+		valid                 bool
+	}{
+		{"καλημέρα", false, false, true},
+		{"ααα", true, false, false},
+		{"λόλόλό", true, false, false},
+		{"привет", false, false, true},
+		{"ммм", true, false, false},
+		{"дада", true, false, false},
+		{"日本語", false, false, true},
+		{"哈哈哈", true, false, false},
+		{"你好你好", true, false, false},
+		{"你好", false, false, true},
+		{"١٢٣", false, true, false},
+		{"１２３", false, true, false},
+		{"一二三", false, false, true},
+		{"πα-πα", false, false, true},
+	}
+	for _, tt := range tests {
+		if got := IsRepetitive(tt.input); got != tt.repetitive {
+			t.Errorf("IsRepetitive(%q) = %t, want %t", tt.input, got, tt.repetitive)
+		}
+		if got := IsOnlyNumbers(tt.input); got != tt.onlyDigit {
+			t.Errorf("IsOnlyNumbers(%q) = %t, want %t", tt.input, got, tt.onlyDigit)
+		}
+		if got := IsValidInput(tt.input); got != tt.valid {
+			t.Errorf("IsValidInput(%q) = %t, want %t", tt.input, got, tt.valid)
+		}
+	}
+}
+
+func TestFilterDoesNotAllocate(t *testing.T) {
+	for _, input := range []string{"καλημέρα", "ммм", "你好你好"} {
+		allocs := testing.AllocsPerRun(100, func() {
+			IsValidInput(input)
+		})
+		if allocs != 0 {
+			t.Errorf("IsValidInput(%q) allocates %v times, want 0", input, allocs)
+		}
+	}
+}
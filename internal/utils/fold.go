@@ -0,0 +1,80 @@
+package utils
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// foldTable maps accented Latin letters to their unaccented base.
+// It covers Latin-1 Supplement and Latin Extended-A, which is what
+// French, Spanish, German, Portuguese, Polish, Czech and friends use.
+var foldTable = map[rune]string{}
+
+func init() {
+	bases := map[string]string{
+		"àáâãäåāăą":  "a",
+		"çćĉċč":      "c",
+		"ďđð":        "d",
+		"èéêëēĕėęě":  "e",
+		"ĝğġģ":       "g",
+		"ĥħ":         "h",
+		"ìíîïĩīĭįı":  "i",
+		"ĵ":          "j",
+		"ķ":          "k",
+		"ĺļľŀł":      "l",
+		"ñńņňŉ":      "n",
+		"òóôõöøōŏő":  "o",
+		"ŕŗř":        "r",
+		"śŝşš":       "s",
+		"ţťŧ":        "t",
+		"ùúûüũūŭůűų": "u",
+		"ŵ":          "w",
+		"ýÿŷ":        "y",
+		"źżž":        "z",
+		"æ":          "ae",
+		"œ":          "oe",
+		"ß":          "ss",
+		"þ":          "th",
+	}
+	for letters, base := range bases {
+		for _, r := range letters {
+			foldTable[r] = base
+			if upper := unicode.ToUpper(r); upper != r {
+				foldTable[upper] = strings.ToUpper(base[:1]) + base[1:]
+			}
+		}
+	}
+}
+
+// FoldDiacritics strips accents from Latin letters, so "café" becomes "cafe"
+// and "naïve" becomes "naive". Combining marks from decomposed (NFD) input are
+// dropped too. Case is kept and other characters pass through unchanged.
+func FoldDiacritics(s string) string {
+	if isASCII(s) {
+		return s
+	}
+	var b strings.Builder
+	b.Grow(len(s))
+	for _, r := range s {
+		if base, ok := foldTable[r]; ok {
+			b.WriteString(base)
+			continue
+		}
+		if unicode.Is(unicode.Mn, r) {
+			continue
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+//go:inline
+func isASCII(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] >= utf8.RuneSelf {
+			return false
+		}
+	}
+	return true
+}
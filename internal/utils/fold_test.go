@@ -0,0 +1,26 @@
+package utils
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestFoldDiacritics(t *testing.T) {
+	tests := []struct {
+		typed, word string
+	}{
+		{"cafe", "café"},
+		{"resume", "resumé"},
+		{"naive", "naïve"},
+		{"naive", "nai\u0308ve"}, // decomposed, with a combining diaeresis
+		{"Cafe", "Café"},
+	}
+	for _, tt := range tests {
+		if strings.HasPrefix(tt.word, tt.typed) {
+			t.Errorf("%q starts with %q without folding", tt.word, tt.typed)
+		}
+		if folded := FoldDiacritics(tt.word); !strings.HasPrefix(folded, FoldDiacritics(tt.typed)) {
+			t.Errorf("FoldDiacritics(%q) = %q, want it to start with %q", tt.word, folded, tt.typed)
+		}
+	}
+}
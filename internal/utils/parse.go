@@ -52,3 +52,27 @@ func ExtractBool(data map[string]any, key string) (bool, bool) {
 	}
 	return false, false
 }
+
+// ExtractString safely extracts a string value from a map
+func ExtractString(data map[string]any, key string) (string, bool) {
+	if val, ok := data[key].(string); ok {
+		return val, true
+	}
+	return "", false
+}
+
+// ExtractStringMap safely extracts a table of string values from a map.
+// Entries that aren't strings are skipped.
+func ExtractStringMap(data map[string]any, key string) (map[string]string, bool) {
+	table, ok := data[key].(map[string]any)
+	if !ok {
+		return nil, false
+	}
+	values := make(map[string]string, len(table))
+	for name, raw := range table {
+		if val, ok := raw.(string); ok {
+			values[name] = val
+		}
+	}
+	return values, true
+}
@@ -2,24 +2,40 @@ package utils
 
 import (
 	"fmt"
+	"slices"
 	"strings"
+	"unicode"
+)
+
+// capitalMode is how the capitals of a prefix carry over to its suggestions
+type capitalMode int
+
+const (
+	// capitalPositions copies each capital to the same rune position, for mixed input like "hEl"
+	capitalPositions capitalMode = iota
+	// capitalTitle capitalizes the first letter only, for input like "Hel"
+	capitalTitle
+	// capitalAll uppercases the whole word, for input like "HEL"
+	capitalAll
 )
 
 // CapitalInfo holds basic info on pos and chars of capital letters in a string
 type CapitalInfo struct {
-	positions []int
+	mode      capitalMode
+	positions []int // rune positions
 	chars     []rune
 }
 
 // GetCapitalDetails extracts capital letter positions and characters from a string.
 // Returns the lowercase version and cap info since we need to apply it later.
 // lowercase return is because of actual dictionary words being all lowercase at this point.
+//
+// A prefix of two or more letters, all uppercase, is all-caps and a prefix whose only
+// capital is its first rune is title case; anything else keeps the positions it has.
 func GetCapitalDetails(s string) (string, *CapitalInfo) {
-	var info *CapitalInfo
 	hasCapitals := false
-
 	for _, r := range s {
-		if r >= 'A' && r <= 'Z' {
+		if unicode.IsUpper(r) {
 			hasCapitals = true
 			break
 		}
@@ -27,28 +43,72 @@ func GetCapitalDetails(s string) (string, *CapitalInfo) {
 	if !hasCapitals {
 		return strings.ToLower(s), nil
 	}
-	info = &CapitalInfo{
+	info := &CapitalInfo{
 		positions: make([]int, 0, 4),
 		chars:     make([]rune, 0, 4),
 	}
-	for i, r := range s {
-		if r >= 'A' && r <= 'Z' {
-			info.positions = append(info.positions, i)
+	letters, pos := 0, 0
+	for _, r := range s {
+		if unicode.IsLetter(r) {
+			letters++
+		}
+		if unicode.IsUpper(r) {
+			info.positions = append(info.positions, pos)
 			info.chars = append(info.chars, r)
 		}
+		pos++
+	}
+	switch {
+	case letters > 1 && len(info.positions) == letters:
+		info.mode = capitalAll
+	case len(info.positions) == 1 && info.positions[0] == 0:
+		info.mode = capitalTitle
 	}
 	return strings.ToLower(s), info
 }
 
-// CapitalizeAtPositions applies capitalization info to a word
-// Works by replacing characters at specified positions with the corresponding capital letters.
+// Casing names how info capitalizes suggestions: "none" for a lowercase prefix
+// (nil info), "upper" for all-caps, "title" for a leading capital, "mixed" otherwise.
+func (info *CapitalInfo) Casing() string {
+	switch {
+	case info == nil || len(info.positions) == 0:
+		return "none"
+	case info.mode == capitalAll:
+		return "upper"
+	case info.mode == capitalTitle:
+		return "title"
+	default:
+		return "mixed"
+	}
+}
+
+// Positions returns the rune positions of the capitals, nil for nil info
+func (info *CapitalInfo) Positions() []int {
+	if info == nil {
+		return nil
+	}
+	return slices.Clone(info.positions)
+}
+
+// CapitalizeAtPositions applies capitalization info to a word.
+// All-caps info uppercases the whole word and title case info its first letter,
+// otherwise the capitals are copied to the rune positions they had in the prefix.
 // If info is nil or has no positions, returns the original word.
 func CapitalizeAtPositions(word string, info *CapitalInfo) string {
 	if info == nil || len(info.positions) == 0 {
 		return word // No allocation needed
 	}
+	if info.mode == capitalAll {
+		return strings.ToUpper(word)
+	}
 
 	runes := []rune(word)
+	if info.mode == capitalTitle {
+		if len(runes) > 0 {
+			runes[0] = unicode.ToUpper(runes[0])
+		}
+		return string(runes)
+	}
 	for i, pos := range info.positions {
 		if pos < len(runes) {
 			runes[pos] = info.chars[i]
@@ -82,3 +142,21 @@ func FormatWithCommas(n int) string {
 	}
 	return result
 }
+
+// FirstWord returns the first run of letters and digits in s, lowercased.
+// Leading spaces and punctuation are skipped. Returns "" if s has no word.
+func FirstWord(s string) string {
+	start := strings.IndexFunc(s, isWordRune)
+	if start < 0 {
+		return ""
+	}
+	s = s[start:]
+	if end := strings.IndexFunc(s, func(r rune) bool { return !isWordRune(r) }); end >= 0 {
+		s = s[:end]
+	}
+	return strings.ToLower(s)
+}
+
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r)
+}
@@ -0,0 +1,33 @@
+package utils
+
+import "testing"
+
+func TestCapitalization(t *testing.T) {
+	tests := []struct {
+		prefix, word string
+		want, casing string
+	}{
+		{"HEL", "hello", "HELLO", "upper"},
+		{"Hel", "hello", "Hello", "title"},
+		{"hEl", "hello", "hEllo", "mixed"},
+		{"hEL", "hello", "hELlo", "mixed"},
+		{"HeL", "hello", "HeLlo", "mixed"},
+		{"H", "hello", "Hello", "title"},
+		{"hel", "hello", "hello", "none"},
+		{"ÉCO", "école", "ÉCOLE", "upper"},
+		{"Éc", "école", "École", "title"},
+		{"ΚΑΛ", "καλημέρα", "ΚΑΛΗΜΈΡΑ", "upper"},
+	}
+	for _, tt := range tests {
+		lower, info := GetCapitalDetails(tt.prefix)
+		if want := []rune(tt.word)[:len([]rune(tt.prefix))]; lower != string(want) {
+			t.Errorf("GetCapitalDetails(%q) lowercased to %q, want %q", tt.prefix, lower, string(want))
+		}
+		if got := CapitalizeAtPositions(tt.word, info); got != tt.want {
+			t.Errorf("%q typed as %q = %q, want %q", tt.word, tt.prefix, got, tt.want)
+		}
+		if got := info.Casing(); got != tt.casing {
+			t.Errorf("casing of %q = %q, want %q", tt.prefix, got, tt.casing)
+		}
+	}
+}
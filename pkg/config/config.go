@@ -13,34 +13,60 @@ import (
 
 // Config holds the entire config structure
 type Config struct {
-	Server ServerConfig `toml:"server"`
-	Dict   DictConfig   `toml:"dict"`
-	CLI    CliConfig    `toml:"cli"`
+	Server ServerConfig `toml:"server" json:"server"`
+	Dict   DictConfig   `toml:"dict" json:"dict"`
+	CLI    CliConfig    `toml:"cli" json:"cli"`
 }
 
 // ServerConfig has server related options.
 type ServerConfig struct {
-	MaxLimit     int  `toml:"max_limit"`
-	MinPrefix    int  `toml:"min_prefix"`
-	MaxPrefix    int  `toml:"max_prefix"`
-	EnableFilter bool `toml:"enable_filter"`
+	MaxLimit           int    `toml:"max_limit" json:"max_limit"`
+	MinPrefix          int    `toml:"min_prefix" json:"min_prefix"`
+	MaxPrefix          int    `toml:"max_prefix" json:"max_prefix"`
+	EnableFilter       bool   `toml:"enable_filter" json:"enable_filter"`
+	DigitMode          string `toml:"digit_mode" json:"digit_mode"`
+	AllowSymbols       bool   `toml:"allow_symbols" json:"allow_symbols"`
+	Workers            int    `toml:"workers" json:"workers"`
+	CORSOrigin         string `toml:"cors_origin" json:"cors_origin"`
+	AccessLog          bool   `toml:"access_log" json:"access_log"`
+	PrivacyMode        bool   `toml:"privacy_mode" json:"privacy_mode"`
+	AllowPattern       string `toml:"allow_pattern" json:"allow_pattern"`
+	DenyPattern        string `toml:"deny_pattern" json:"deny_pattern"`
+	ProfileThresholdMs int    `toml:"profile_threshold_ms" json:"profile_threshold_ms"`
+	ProfileDir         string `toml:"profile_dir" json:"profile_dir"`
+	QueueUntilReady    bool   `toml:"queue_until_ready" json:"queue_until_ready"`
+	RequestTimeoutMs   int    `toml:"request_timeout_ms" json:"request_timeout_ms"`
 }
 
 // DictConfig holds dictionary options.
 type DictConfig struct {
-	MaxWords               int `toml:"max_words"`
-	ChunkSize              int `toml:"chunk_size"`
-	MinFreqThreshold       int `toml:"min_frequency_threshold"`
-	MinFreqShortPrefix     int `toml:"min_frequency_short_prefix"`
-	MaxWordCountValidation int `toml:"max_word_count_validation"`
+	MaxWords               int    `toml:"max_words" json:"max_words"`
+	ChunkSize              int    `toml:"chunk_size" json:"chunk_size"`
+	MinFreqThreshold       int    `toml:"min_frequency_threshold" json:"min_frequency_threshold"`
+	MinFreqShortPrefix     int    `toml:"min_frequency_short_prefix" json:"min_frequency_short_prefix"`
+	MaxWordCountValidation int    `toml:"max_word_count_validation" json:"max_word_count_validation"`
+	MaxChunks              int    `toml:"max_chunks" json:"max_chunks"`
+	ReleaseURL             string `toml:"release_url" json:"release_url"`
+	UserWordsPath          string `toml:"user_words_path" json:"user_words_path"`
+	BlacklistPath          string `toml:"blacklist_path" json:"blacklist_path"`
+	FoldDiacritics         bool   `toml:"fold_diacritics" json:"fold_diacritics"`
+	SortMode               string `toml:"sort_mode" json:"sort_mode"`
+	PreferInflections      bool   `toml:"prefer_inflections" json:"prefer_inflections"`
+	WatchInterval          int    `toml:"watch_interval" json:"watch_interval"`
+	MaxMemoryBytes         int    `toml:"max_memory_bytes" json:"max_memory_bytes"`
+	RankConversion         string `toml:"rank_conversion" json:"rank_conversion"`
+	BuildWorkers           int    `toml:"build_workers" json:"build_workers"`
+	Language               string `toml:"language" json:"language"`
+	// Languages maps extra language codes to their data dirs, served next to the primary one
+	Languages map[string]string `toml:"languages" json:"languages"`
 }
 
 // CliConfig holds cli interface options.
 type CliConfig struct {
-	DefaultLimit    int  `toml:"default_limit"`
-	DefaultMinLen   int  `toml:"default_min_len"`
-	DefaultMaxLen   int  `toml:"default_max_len"`
-	DefaultNoFilter bool `toml:"default_no_filter"`
+	DefaultLimit    int  `toml:"default_limit" json:"default_limit"`
+	DefaultMinLen   int  `toml:"default_min_len" json:"default_min_len"`
+	DefaultMaxLen   int  `toml:"default_max_len" json:"default_max_len"`
+	DefaultNoFilter bool `toml:"default_no_filter" json:"default_no_filter"`
 }
 
 // GetConfigDir returns the config directory with fallback priority:
@@ -88,7 +114,19 @@ func GetDefaultConfigPath() (string, error) {
 // 1. Custom path from --config flag
 // 2. Default path: [UserConfigDir]/wordserve/config.toml
 // 3. Builtin defaults
+//
+// WORDSERVE_* environment variables override the loaded values, see applyEnvOverrides.
 func LoadConfigWithPriority(customConfigPath string) (*Config, string, error) {
+	config, configPath, err := loadConfigFile(customConfigPath)
+	if err != nil {
+		return nil, "", err
+	}
+	applyEnvOverrides(config)
+	return config, configPath, nil
+}
+
+// loadConfigFile picks the config file for LoadConfigWithPriority and loads it
+func loadConfigFile(customConfigPath string) (*Config, string, error) {
 	var config *Config
 	var err error
 
@@ -124,10 +162,22 @@ func LoadConfigWithPriority(customConfigPath string) (*Config, string, error) {
 func DefaultConfig() *Config {
 	return &Config{
 		Server: ServerConfig{
-			MaxLimit:     64,
-			MinPrefix:    1,
-			MaxPrefix:    60,
-			EnableFilter: true,
+			MaxLimit:           64,
+			MinPrefix:          1,
+			MaxPrefix:          60,
+			EnableFilter:       true,
+			DigitMode:          "mixed",
+			AllowSymbols:       false,
+			Workers:            1,
+			CORSOrigin:         "",
+			AccessLog:          false,
+			PrivacyMode:        false,
+			AllowPattern:       "",
+			DenyPattern:        "",
+			ProfileThresholdMs: 0,
+			ProfileDir:         "",
+			QueueUntilReady:    false,
+			RequestTimeoutMs:   0,
 		},
 		Dict: DictConfig{
 			MaxWords:               50000,
@@ -135,6 +185,19 @@ func DefaultConfig() *Config {
 			MinFreqThreshold:       20,
 			MinFreqShortPrefix:     24,
 			MaxWordCountValidation: 1000000,
+			MaxChunks:              0,
+			ReleaseURL:             "https://github.com/bastiangx/wordserve/releases/latest/download",
+			UserWordsPath:          "",
+			BlacklistPath:          "",
+			FoldDiacritics:         false,
+			SortMode:               "frequency",
+			PreferInflections:      false,
+			WatchInterval:          0,
+			MaxMemoryBytes:         0,
+			RankConversion:         "rank_inverse",
+			BuildWorkers:           0,
+			Language:               "en",
+			Languages:              map[string]string{},
 		},
 		CLI: CliConfig{
 			DefaultLimit:    24,
@@ -172,13 +235,17 @@ func InitConfig(configPath string) (*Config, error) {
 	return config, nil
 }
 
-// LoadConfig loads from a TOML file
+// LoadConfig loads from a TOML file.
+// Values that fail Validate are logged and replaced by their defaults.
 func LoadConfig(configPath string) (*Config, error) {
 	config := DefaultConfig()
 
 	if err := utils.LoadTOMLFile(configPath, config); err != nil {
-		return tryPartialParse(configPath)
+		if config, err = tryPartialParse(configPath); err != nil {
+			return nil, err
+		}
 	}
+	config.sanitize(configPath)
 	return config, nil
 }
 
@@ -218,6 +285,42 @@ func extractServerConfig(data map[string]any, server *ServerConfig) {
 	if val, ok := utils.ExtractBool(data, "enable_filter"); ok {
 		server.EnableFilter = val
 	}
+	if val, ok := utils.ExtractString(data, "digit_mode"); ok {
+		server.DigitMode = val
+	}
+	if val, ok := utils.ExtractBool(data, "allow_symbols"); ok {
+		server.AllowSymbols = val
+	}
+	if val, ok := utils.ExtractInt64(data, "workers"); ok {
+		server.Workers = val
+	}
+	if val, ok := utils.ExtractString(data, "cors_origin"); ok {
+		server.CORSOrigin = val
+	}
+	if val, ok := utils.ExtractBool(data, "access_log"); ok {
+		server.AccessLog = val
+	}
+	if val, ok := utils.ExtractBool(data, "privacy_mode"); ok {
+		server.PrivacyMode = val
+	}
+	if val, ok := utils.ExtractString(data, "allow_pattern"); ok {
+		server.AllowPattern = val
+	}
+	if val, ok := utils.ExtractString(data, "deny_pattern"); ok {
+		server.DenyPattern = val
+	}
+	if val, ok := utils.ExtractInt64(data, "profile_threshold_ms"); ok {
+		server.ProfileThresholdMs = val
+	}
+	if val, ok := utils.ExtractString(data, "profile_dir"); ok {
+		server.ProfileDir = val
+	}
+	if val, ok := utils.ExtractBool(data, "queue_until_ready"); ok {
+		server.QueueUntilReady = val
+	}
+	if val, ok := utils.ExtractInt64(data, "request_timeout_ms"); ok {
+		server.RequestTimeoutMs = val
+	}
 }
 
 // extractDictConfig extracts dictionary configuration from a map
@@ -237,6 +340,45 @@ func extractDictConfig(data map[string]any, dict *DictConfig) {
 	if val, ok := utils.ExtractInt64(data, "max_word_count_validation"); ok {
 		dict.MaxWordCountValidation = val
 	}
+	if val, ok := utils.ExtractInt64(data, "max_chunks"); ok {
+		dict.MaxChunks = val
+	}
+	if val, ok := utils.ExtractString(data, "release_url"); ok {
+		dict.ReleaseURL = val
+	}
+	if val, ok := utils.ExtractString(data, "user_words_path"); ok {
+		dict.UserWordsPath = val
+	}
+	if val, ok := utils.ExtractString(data, "blacklist_path"); ok {
+		dict.BlacklistPath = val
+	}
+	if val, ok := utils.ExtractBool(data, "fold_diacritics"); ok {
+		dict.FoldDiacritics = val
+	}
+	if val, ok := utils.ExtractString(data, "sort_mode"); ok {
+		dict.SortMode = val
+	}
+	if val, ok := utils.ExtractBool(data, "prefer_inflections"); ok {
+		dict.PreferInflections = val
+	}
+	if val, ok := utils.ExtractInt64(data, "watch_interval"); ok {
+		dict.WatchInterval = val
+	}
+	if val, ok := utils.ExtractInt64(data, "max_memory_bytes"); ok {
+		dict.MaxMemoryBytes = val
+	}
+	if val, ok := utils.ExtractString(data, "rank_conversion"); ok {
+		dict.RankConversion = val
+	}
+	if val, ok := utils.ExtractInt64(data, "build_workers"); ok {
+		dict.BuildWorkers = val
+	}
+	if val, ok := utils.ExtractString(data, "language"); ok {
+		dict.Language = val
+	}
+	if val, ok := utils.ExtractStringMap(data, "languages"); ok {
+		dict.Languages = val
+	}
 }
 
 // extractCliConfig extracts CLI config from a map
@@ -0,0 +1,77 @@
+package config
+
+import (
+	"os"
+	"strconv"
+
+	"github.com/charmbracelet/log"
+)
+
+// envIntOverrides maps environment variables to the int settings they override
+var envIntOverrides = []struct {
+	name  string
+	field func(c *Config) *int
+}{
+	{"WORDSERVE_MAX_LIMIT", func(c *Config) *int { return &c.Server.MaxLimit }},
+	{"WORDSERVE_MIN_PREFIX", func(c *Config) *int { return &c.Server.MinPrefix }},
+	{"WORDSERVE_MAX_PREFIX", func(c *Config) *int { return &c.Server.MaxPrefix }},
+	{"WORDSERVE_MAX_WORDS", func(c *Config) *int { return &c.Dict.MaxWords }},
+	{"WORDSERVE_CHUNK_SIZE", func(c *Config) *int { return &c.Dict.ChunkSize }},
+}
+
+// envBoolOverrides maps environment variables to the bool settings they override
+var envBoolOverrides = []struct {
+	name  string
+	field func(c *Config) *bool
+}{
+	{"WORDSERVE_ENABLE_FILTER", func(c *Config) *bool { return &c.Server.EnableFilter }},
+}
+
+// applyEnvOverrides sets config values from WORDSERVE_* environment variables,
+// so containers can change them without mounting a config file.
+// A value that doesn't parse is logged and ignored.
+func applyEnvOverrides(config *Config) {
+	overridden := false
+	for _, override := range envIntOverrides {
+		raw, ok := os.LookupEnv(override.name)
+		if !ok {
+			continue
+		}
+		val, err := strconv.Atoi(raw)
+		if err != nil {
+			log.Warnf("Ignoring %s=%q: not an integer", override.name, raw)
+			continue
+		}
+		*override.field(config) = val
+		overridden = true
+		log.Debugf("Config override from environment: %s=%d", override.name, val)
+	}
+	for _, override := range envBoolOverrides {
+		raw, ok := os.LookupEnv(override.name)
+		if !ok {
+			continue
+		}
+		val, err := strconv.ParseBool(raw)
+		if err != nil {
+			log.Warnf("Ignoring %s=%q: not a boolean", override.name, raw)
+			continue
+		}
+		*override.field(config) = val
+		overridden = true
+		log.Debugf("Config override from environment: %s=%t", override.name, val)
+	}
+	if overridden {
+		config.sanitize("environment")
+	}
+}
+
+// ReloadConfig loads the TOML file again with the environment overrides on top,
+// for servers picking up an edited config file
+func ReloadConfig(configPath string) (*Config, error) {
+	config, err := LoadConfig(configPath)
+	if err != nil {
+		return nil, err
+	}
+	applyEnvOverrides(config)
+	return config, nil
+}
@@ -0,0 +1,94 @@
+package config
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+// loadWithEnv loads a default config file from a temp dir, with the environment overrides applied
+func loadWithEnv(t *testing.T) *Config {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config.toml")
+	if err := SaveConfig(DefaultConfig(), path); err != nil {
+		t.Fatal(err)
+	}
+	cfg, loadedPath, err := LoadConfigWithPriority(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if loadedPath != path {
+		t.Fatalf("loaded %s, want %s", loadedPath, path)
+	}
+	return cfg
+}
+
+func TestEnvOverrides(t *testing.T) {
+	t.Setenv("WORDSERVE_MAX_LIMIT", "12")
+	t.Setenv("WORDSERVE_MIN_PREFIX", "2")
+	t.Setenv("WORDSERVE_MAX_PREFIX", "30")
+	t.Setenv("WORDSERVE_ENABLE_FILTER", "false")
+	t.Setenv("WORDSERVE_MAX_WORDS", "20000")
+	t.Setenv("WORDSERVE_CHUNK_SIZE", "5000")
+
+	cfg := loadWithEnv(t)
+	for _, tt := range []struct {
+		name      string
+		got, want int
+	}{
+		{"WORDSERVE_MAX_LIMIT", cfg.Server.MaxLimit, 12},
+		{"WORDSERVE_MIN_PREFIX", cfg.Server.MinPrefix, 2},
+		{"WORDSERVE_MAX_PREFIX", cfg.Server.MaxPrefix, 30},
+		{"WORDSERVE_MAX_WORDS", cfg.Dict.MaxWords, 20000},
+		{"WORDSERVE_CHUNK_SIZE", cfg.Dict.ChunkSize, 5000},
+	} {
+		if tt.got != tt.want {
+			t.Errorf("%s: got %d, want %d", tt.name, tt.got, tt.want)
+		}
+	}
+	if cfg.Server.EnableFilter {
+		t.Error("WORDSERVE_ENABLE_FILTER=false left the filter enabled")
+	}
+}
+
+func TestEnvOverridesIgnoreInvalidValues(t *testing.T) {
+	t.Setenv("WORDSERVE_MAX_LIMIT", "lots")
+	t.Setenv("WORDSERVE_ENABLE_FILTER", "maybe")
+	t.Setenv("WORDSERVE_CHUNK_SIZE", "1e4")
+
+	cfg := loadWithEnv(t)
+	defaults := DefaultConfig()
+	if cfg.Server.MaxLimit != defaults.Server.MaxLimit {
+		t.Errorf("max_limit = %d, want the file's %d", cfg.Server.MaxLimit, defaults.Server.MaxLimit)
+	}
+	if cfg.Server.EnableFilter != defaults.Server.EnableFilter {
+		t.Errorf("enable_filter = %t, want the file's %t", cfg.Server.EnableFilter, defaults.Server.EnableFilter)
+	}
+	if cfg.Dict.ChunkSize != defaults.Dict.ChunkSize {
+		t.Errorf("chunk_size = %d, want the file's %d", cfg.Dict.ChunkSize, defaults.Dict.ChunkSize)
+	}
+}
+
+func TestEnvOverridesAreValidated(t *testing.T) {
+	t.Setenv("WORDSERVE_MIN_PREFIX", "40")
+	t.Setenv("WORDSERVE_MAX_PREFIX", "4")
+
+	cfg := loadWithEnv(t)
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("Validate() = %v, want the bad overrides replaced by defaults", err)
+	}
+}
+
+func TestReloadConfigKeepsEnvOverrides(t *testing.T) {
+	t.Setenv("WORDSERVE_MAX_LIMIT", "9")
+	path := filepath.Join(t.TempDir(), "config.toml")
+	if err := SaveConfig(DefaultConfig(), path); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := ReloadConfig(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Server.MaxLimit != 9 {
+		t.Errorf("max_limit = %d, want 9 from WORDSERVE_MAX_LIMIT", cfg.Server.MaxLimit)
+	}
+}
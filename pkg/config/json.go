@@ -0,0 +1,39 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"strings"
+)
+
+// ToJSON returns the config as JSON, keyed by the same names as the TOML file
+func (c *Config) ToJSON() (string, error) {
+	data, err := json.Marshal(c)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// WithJSON returns a copy of the config with the values in data applied on top.
+// data uses the TOML key names, and any subset of sections and keys may be sent.
+// Unknown keys and invalid values are errors, and leave the config untouched.
+func (c *Config) WithJSON(data string) (*Config, error) {
+	updated := *c
+	// the copy would share the map, and decoding into it would change c too
+	updated.Dict.Languages = maps.Clone(c.Dict.Languages)
+	decoder := json.NewDecoder(strings.NewReader(data))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&updated); err != nil {
+		return nil, fmt.Errorf("invalid config JSON: %v", err)
+	}
+	if decoder.More() {
+		return nil, errors.New("invalid config JSON: data after the config object")
+	}
+	if err := updated.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid config: %v", err)
+	}
+	return &updated, nil
+}
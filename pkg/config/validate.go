@@ -0,0 +1,137 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/bastiangx/wordserve/internal/utils"
+	"github.com/charmbracelet/log"
+)
+
+// configIssue is a value that breaks one of the config invariants,
+// with the fix that falls back to the default for the offending keys
+type configIssue struct {
+	key     string
+	problem string
+	fix     func(defaults *Config)
+}
+
+// Validate checks the invariants between config values, like min_prefix <= max_prefix.
+// The returned error names every offending key, or is nil if the config is usable.
+func (c *Config) Validate() error {
+	var errs []error
+	for _, issue := range c.issues() {
+		errs = append(errs, fmt.Errorf("%s %s", issue.key, issue.problem))
+	}
+	return errors.Join(errs...)
+}
+
+// sanitize logs each invalid value and falls back to its default,
+// so a bad entry is reported when the file loads rather than by every request after
+func (c *Config) sanitize(configPath string) {
+	defaults := DefaultConfig()
+	for _, issue := range c.issues() {
+		log.Warnf("Invalid config in %s: %s %s, using the default", configPath, issue.key, issue.problem)
+		issue.fix(defaults)
+	}
+}
+
+// issues returns every broken invariant in the config
+func (c *Config) issues() []configIssue {
+	var issues []configIssue
+	positive := func(key string, value *int, defaultValue func(d *Config) int) {
+		if *value <= 0 {
+			issues = append(issues, configIssue{
+				key:     key,
+				problem: fmt.Sprintf("must be greater than 0, got %d", *value),
+				fix:     func(d *Config) { *value = defaultValue(d) },
+			})
+		}
+	}
+	nonNegative := func(key string, value *int, defaultValue func(d *Config) int) {
+		if *value < 0 {
+			issues = append(issues, configIssue{
+				key:     key,
+				problem: fmt.Sprintf("must not be negative, got %d", *value),
+				fix:     func(d *Config) { *value = defaultValue(d) },
+			})
+		}
+	}
+	ordered := func(minKey, maxKey string, minValue, maxValue *int, defaults func(d *Config) (int, int)) {
+		if *minValue > *maxValue {
+			issues = append(issues, configIssue{
+				key:     minKey,
+				problem: fmt.Sprintf("(%d) is greater than %s (%d)", *minValue, maxKey, *maxValue),
+				fix:     func(d *Config) { *minValue, *maxValue = defaults(d) },
+			})
+		}
+	}
+
+	server := &c.Server
+	positive("server.max_limit", &server.MaxLimit, func(d *Config) int { return d.Server.MaxLimit })
+	nonNegative("server.min_prefix", &server.MinPrefix, func(d *Config) int { return d.Server.MinPrefix })
+	positive("server.max_prefix", &server.MaxPrefix, func(d *Config) int { return d.Server.MaxPrefix })
+	nonNegative("server.workers", &server.Workers, func(d *Config) int { return d.Server.Workers })
+	nonNegative("server.profile_threshold_ms", &server.ProfileThresholdMs, func(d *Config) int { return d.Server.ProfileThresholdMs })
+	nonNegative("server.request_timeout_ms", &server.RequestTimeoutMs, func(d *Config) int { return d.Server.RequestTimeoutMs })
+	if server.MinPrefix >= 0 && server.MaxPrefix > 0 {
+		ordered("server.min_prefix", "server.max_prefix", &server.MinPrefix, &server.MaxPrefix, func(d *Config) (int, int) {
+			return d.Server.MinPrefix, d.Server.MaxPrefix
+		})
+	}
+	if _, err := utils.ParseDigitMode(server.DigitMode); err != nil {
+		issues = append(issues, configIssue{
+			key:     "server.digit_mode",
+			problem: fmt.Sprintf("must be mixed, any or none, got %q", server.DigitMode),
+			fix:     func(d *Config) { server.DigitMode = d.Server.DigitMode },
+		})
+	}
+
+	dict := &c.Dict
+	nonNegative("dict.max_words", &dict.MaxWords, func(d *Config) int { return d.Dict.MaxWords })
+	positive("dict.chunk_size", &dict.ChunkSize, func(d *Config) int { return d.Dict.ChunkSize })
+	nonNegative("dict.min_frequency_threshold", &dict.MinFreqThreshold, func(d *Config) int { return d.Dict.MinFreqThreshold })
+	nonNegative("dict.min_frequency_short_prefix", &dict.MinFreqShortPrefix, func(d *Config) int { return d.Dict.MinFreqShortPrefix })
+	nonNegative("dict.max_word_count_validation", &dict.MaxWordCountValidation, func(d *Config) int { return d.Dict.MaxWordCountValidation })
+	nonNegative("dict.max_chunks", &dict.MaxChunks, func(d *Config) int { return d.Dict.MaxChunks })
+	nonNegative("dict.watch_interval", &dict.WatchInterval, func(d *Config) int { return d.Dict.WatchInterval })
+	nonNegative("dict.build_workers", &dict.BuildWorkers, func(d *Config) int { return d.Dict.BuildWorkers })
+	nonNegative("dict.max_memory_bytes", &dict.MaxMemoryBytes, func(d *Config) int { return d.Dict.MaxMemoryBytes })
+
+	if dict.Language == "" {
+		issues = append(issues, configIssue{
+			key:     "dict.language",
+			problem: "must not be empty",
+			fix:     func(d *Config) { dict.Language = d.Dict.Language },
+		})
+	}
+	for code, dir := range dict.Languages {
+		problem := ""
+		switch {
+		case code == "":
+			problem = "has an empty language code"
+		case code == dict.Language:
+			problem = fmt.Sprintf("repeats the primary language %q, which is served from the data dir", code)
+		case dir == "":
+			problem = fmt.Sprintf("has no data dir for %q", code)
+		}
+		if problem != "" {
+			issues = append(issues, configIssue{
+				key:     "dict.languages",
+				problem: problem,
+				fix:     func(d *Config) { delete(dict.Languages, code) },
+			})
+		}
+	}
+
+	cli := &c.CLI
+	positive("cli.default_limit", &cli.DefaultLimit, func(d *Config) int { return d.CLI.DefaultLimit })
+	nonNegative("cli.default_min_len", &cli.DefaultMinLen, func(d *Config) int { return d.CLI.DefaultMinLen })
+	nonNegative("cli.default_max_len", &cli.DefaultMaxLen, func(d *Config) int { return d.CLI.DefaultMaxLen })
+	if cli.DefaultMinLen >= 0 && cli.DefaultMaxLen >= 0 {
+		ordered("cli.default_min_len", "cli.default_max_len", &cli.DefaultMinLen, &cli.DefaultMaxLen, func(d *Config) (int, int) {
+			return d.CLI.DefaultMinLen, d.CLI.DefaultMaxLen
+		})
+	}
+	return issues
+}
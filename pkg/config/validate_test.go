@@ -0,0 +1,91 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestDefaultConfigIsValid(t *testing.T) {
+	if err := DefaultConfig().Validate(); err != nil {
+		t.Errorf("Validate() = %v, want nil for the defaults", err)
+	}
+}
+
+func TestValidateNamesOffendingKey(t *testing.T) {
+	tests := []struct {
+		key    string
+		change func(c *Config)
+	}{
+		{"server.min_prefix", func(c *Config) { c.Server.MinPrefix, c.Server.MaxPrefix = 10, 3 }},
+		{"server.min_prefix", func(c *Config) { c.Server.MinPrefix = -1 }},
+		{"server.max_prefix", func(c *Config) { c.Server.MaxPrefix = 0 }},
+		{"server.max_limit", func(c *Config) { c.Server.MaxLimit = 0 }},
+		{"server.max_limit", func(c *Config) { c.Server.MaxLimit = -5 }},
+		{"server.workers", func(c *Config) { c.Server.Workers = -1 }},
+		{"server.digit_mode", func(c *Config) { c.Server.DigitMode = "some" }},
+		{"dict.chunk_size", func(c *Config) { c.Dict.ChunkSize = 0 }},
+		{"dict.max_words", func(c *Config) { c.Dict.MaxWords = -1 }},
+		{"dict.min_frequency_threshold", func(c *Config) { c.Dict.MinFreqThreshold = -1 }},
+		{"dict.min_frequency_short_prefix", func(c *Config) { c.Dict.MinFreqShortPrefix = -1 }},
+		{"dict.build_workers", func(c *Config) { c.Dict.BuildWorkers = -2 }},
+		{"dict.language", func(c *Config) { c.Dict.Language = "" }},
+		{"dict.languages", func(c *Config) { c.Dict.Languages = map[string]string{"de": ""} }},
+		{"cli.default_limit", func(c *Config) { c.CLI.DefaultLimit = 0 }},
+		{"cli.default_min_len", func(c *Config) { c.CLI.DefaultMinLen, c.CLI.DefaultMaxLen = 5, 2 }},
+	}
+	for _, tt := range tests {
+		cfg := DefaultConfig()
+		tt.change(cfg)
+		err := cfg.Validate()
+		if err == nil {
+			t.Errorf("%s: Validate() = nil, want an error", tt.key)
+			continue
+		}
+		if !strings.HasPrefix(err.Error(), tt.key+" ") {
+			t.Errorf("%s: Validate() = %q, want it to name the key", tt.key, err)
+		}
+	}
+}
+
+func TestValidateReportsEveryKey(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Server.MaxLimit = 0
+	cfg.Dict.ChunkSize = -1
+	err := cfg.Validate()
+	if err == nil {
+		t.Fatal("Validate() = nil, want an error")
+	}
+	for _, key := range []string{"server.max_limit", "dict.chunk_size"} {
+		if !strings.Contains(err.Error(), key) {
+			t.Errorf("Validate() = %q, want it to name %s", err, key)
+		}
+	}
+}
+
+func TestLoadConfigFallsBackToDefaults(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.toml")
+	data := "[server]\nmax_limit = 32\nmin_prefix = 10\nmax_prefix = 3\n\n[dict]\nchunk_size = 0\n"
+	if err := os.WriteFile(path, []byte(data), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := LoadConfig(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defaults := DefaultConfig()
+	if cfg.Server.MinPrefix != defaults.Server.MinPrefix || cfg.Server.MaxPrefix != defaults.Server.MaxPrefix {
+		t.Errorf("prefix range = %d..%d, want the defaults %d..%d",
+			cfg.Server.MinPrefix, cfg.Server.MaxPrefix, defaults.Server.MinPrefix, defaults.Server.MaxPrefix)
+	}
+	if cfg.Dict.ChunkSize != defaults.Dict.ChunkSize {
+		t.Errorf("chunk_size = %d, want the default %d", cfg.Dict.ChunkSize, defaults.Dict.ChunkSize)
+	}
+	if cfg.Server.MaxLimit != 32 {
+		t.Errorf("max_limit = %d, want the valid value 32 kept", cfg.Server.MaxLimit)
+	}
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("Validate() after loading = %v, want nil", err)
+	}
+}
@@ -0,0 +1,176 @@
+package dictionary
+
+import (
+	"bufio"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/charmbracelet/log"
+	"github.com/tchap/go-patricia/v2/patricia"
+)
+
+// Bigram is a pair of words seen next to each other, ranked by how common the pair is
+type Bigram struct {
+	Prev string
+	Next string
+	Rank uint16 // 1 = most frequent pair
+}
+
+// BigramStore holds next-word candidates keyed by the previous word.
+// Each previous word has its own small trie of following words, so a
+// partly typed next word can be completed with a prefix search.
+type BigramStore struct {
+	next  map[string]*patricia.Trie
+	pairs int
+	mu    sync.RWMutex
+}
+
+// NewBigramStore creates an empty bigram store
+func NewBigramStore() *BigramStore {
+	return &BigramStore{next: make(map[string]*patricia.Trie)}
+}
+
+// Add records a bigram, converting its rank to a score the same way chunk words are
+func (bs *BigramStore) Add(prev, next string, rank uint16) {
+	bs.mu.Lock()
+	defer bs.mu.Unlock()
+	trie, exists := bs.next[prev]
+	if !exists {
+		trie = patricia.NewTrie()
+		bs.next[prev] = trie
+	}
+	if trie.Get(patricia.Prefix(next)) == nil {
+		bs.pairs++
+	}
+	trie.Set(patricia.Prefix(next), RankInverse.Score(rank))
+}
+
+// Next returns the trie of words following prev, or nil if there are none
+func (bs *BigramStore) Next(prev string) *patricia.Trie {
+	bs.mu.RLock()
+	defer bs.mu.RUnlock()
+	return bs.next[prev]
+}
+
+// Len returns the number of bigrams in the store
+func (bs *BigramStore) Len() int {
+	bs.mu.RLock()
+	defer bs.mu.RUnlock()
+	return bs.pairs
+}
+
+// LoadBigrams reads every bigram_XXXX.bin (or .bin.gz) file in dirPath into a store.
+// A directory without bigram files gives an empty store, not an error.
+func LoadBigrams(dirPath string) (*BigramStore, error) {
+	store := NewBigramStore()
+	var files []string
+	for _, pattern := range []string{"bigram_*.bin", "bigram_*.bin" + gzipSuffix} {
+		matches, err := filepath.Glob(filepath.Join(dirPath, pattern))
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, matches...)
+	}
+	sort.Strings(files)
+	for _, file := range files {
+		if err := store.loadFile(file); err != nil {
+			return nil, err
+		}
+	}
+	// Predictions walk the tries concurrently, see sortTrie
+	for _, trie := range store.next {
+		sortTrie(trie)
+	}
+	if len(files) > 0 {
+		log.Debugf("Loaded %d bigrams from %d files", store.Len(), len(files))
+	}
+	return store, nil
+}
+
+// loadFile reads one bigram file into the store
+func (bs *BigramStore) loadFile(filename string) error {
+	file, err := openChunk(filename)
+	if err != nil {
+		return fmt.Errorf("failed to open bigram file %s: %w", filename, err)
+	}
+	defer file.Close()
+	reader := bufio.NewReader(file)
+
+	var totalEntries int32
+	if err := binary.Read(reader, binary.LittleEndian, &totalEntries); err != nil {
+		return fmt.Errorf("failed to read bigram header of %s: %w", filename, err)
+	}
+	for range totalEntries {
+		prev, err := readString(reader)
+		if err != nil {
+			return fmt.Errorf("failed to read bigram in %s: %w", filename, err)
+		}
+		next, err := readString(reader)
+		if err != nil {
+			return fmt.Errorf("failed to read bigram in %s: %w", filename, err)
+		}
+		var rank uint16
+		if err := binary.Read(reader, binary.LittleEndian, &rank); err != nil {
+			return fmt.Errorf("failed to read bigram rank in %s: %w", filename, err)
+		}
+		bs.Add(strings.ToLower(prev), strings.ToLower(next), rank)
+	}
+	return nil
+}
+
+// WriteBigrams writes bigrams to a file in the format LoadBigrams reads
+func WriteBigrams(filename string, bigrams []Bigram) error {
+	file, err := os.Create(filename)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	writer := bufio.NewWriter(file)
+
+	if err := binary.Write(writer, binary.LittleEndian, int32(len(bigrams))); err != nil {
+		return err
+	}
+	for _, bigram := range bigrams {
+		if err := writeString(writer, bigram.Prev); err != nil {
+			return err
+		}
+		if err := writeString(writer, bigram.Next); err != nil {
+			return err
+		}
+		if err := binary.Write(writer, binary.LittleEndian, bigram.Rank); err != nil {
+			return err
+		}
+	}
+	return writer.Flush()
+}
+
+// readString reads a uint16 length prefixed UTF-8 string
+func readString(reader io.Reader) (string, error) {
+	var length uint16
+	if err := binary.Read(reader, binary.LittleEndian, &length); err != nil {
+		return "", err
+	}
+	buf := make([]byte, length)
+	if _, err := io.ReadFull(reader, buf); err != nil {
+		return "", err
+	}
+	return string(buf), nil
+}
+
+// writeString writes a uint16 length prefixed UTF-8 string
+func writeString(writer io.Writer, s string) error {
+	if len(s) > 0xFFFF {
+		return fmt.Errorf("string too long: %d bytes", len(s))
+	}
+	if err := binary.Write(writer, binary.LittleEndian, uint16(len(s))); err != nil {
+		return err
+	}
+	_, err := io.WriteString(writer, s)
+	return err
+}
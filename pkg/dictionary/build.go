@@ -0,0 +1,197 @@
+package dictionary
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io/fs"
+	"math"
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/charmbracelet/log"
+)
+
+// rankedWord is a words.txt entry with its rank, 1 for the most frequent
+type rankedWord struct {
+	word string
+	rank uint16
+}
+
+// BuildChunks writes dict_XXXX.bin chunk files to outDir from a words.txt file.
+//
+// wordsPath holds one "word<TAB>frequency" line per word, most frequent first.
+// Words are ranked by their line order (rank 1 = most frequent, capped at 65535)
+// and split into chunks of chunkSize words, so dict_0001.bin holds the most
+// frequent ones. maxChunks limits how many chunks are written, 0 writes all.
+// Lines that don't parse and repeated words are skipped.
+//
+// The files start with the "WSD1" magic and a format version byte, then an int32
+// word count, then per word a uint16 byte length, the word and its uint16 rank,
+// all little endian and in byte order of the words. Existing chunk files are
+// replaced, and compressed copies of them removed, as the loader prefers those.
+//
+// Chunks are written in parallel, one goroutine per CPU; see [BuildChunksWithWorkers].
+func BuildChunks(wordsPath string, outDir string, chunkSize int, maxChunks int) error {
+	return buildChunks(context.Background(), wordsPath, outDir, chunkSize, maxChunks, 0)
+}
+
+// BuildChunksWithWorkers is [BuildChunks] writing up to workers chunks at once,
+// 0 uses one per CPU. The files written are the same whatever the worker count.
+func BuildChunksWithWorkers(wordsPath string, outDir string, chunkSize, maxChunks, workers int) error {
+	return buildChunks(context.Background(), wordsPath, outDir, chunkSize, maxChunks, workers)
+}
+
+// buildChunks is BuildChunksWithWorkers, stopping between chunks once ctx is done
+func buildChunks(ctx context.Context, wordsPath, outDir string, chunkSize, maxChunks, workers int) error {
+	if chunkSize <= 0 {
+		return fmt.Errorf("chunk size must be positive, got %d", chunkSize)
+	}
+	words, err := readRankedWords(wordsPath)
+	if err != nil {
+		return err
+	}
+	if len(words) == 0 {
+		return fmt.Errorf("no words found in %s", wordsPath)
+	}
+	if err := os.MkdirAll(outDir, 0o755); err != nil {
+		return err
+	}
+
+	totalChunks := (len(words) + chunkSize - 1) / chunkSize
+	if maxChunks > 0 && totalChunks > maxChunks {
+		totalChunks = maxChunks
+	}
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+	workers = min(workers, totalChunks)
+
+	// Each chunk is sorted and written on its own, from its slice of the
+	// ranked words, so the order the workers finish in doesn't matter
+	buildCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	errs := make([]error, totalChunks)
+	chunkIDs := make(chan int)
+	var wg sync.WaitGroup
+	for range workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for chunkID := range chunkIDs {
+				start := (chunkID - 1) * chunkSize
+				end := min(start+chunkSize, len(words))
+				path := filepath.Join(outDir, fmt.Sprintf("dict_%04d.bin", chunkID))
+				if err := writeChunk(path, words[start:end]); err != nil {
+					errs[chunkID-1] = err
+					cancel()
+					continue
+				}
+				log.Debugf("Built %s with %d words", path, end-start)
+			}
+		}()
+	}
+	for chunkID := 1; chunkID <= totalChunks && buildCtx.Err() == nil; chunkID++ {
+		chunkIDs <- chunkID
+	}
+	close(chunkIDs)
+	wg.Wait()
+
+	// The first failed chunk is reported, not the first to fail
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	log.Debugf("Built %d chunks from %d words in %s", totalChunks, len(words), wordsPath)
+	return nil
+}
+
+// readRankedWords reads words.txt and ranks its words by line order
+func readRankedWords(wordsPath string) ([]rankedWord, error) {
+	file, err := os.Open(wordsPath)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var words []rankedWord
+	seen := make(map[string]bool)
+	skipped := 0
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		word, freq, ok := strings.Cut(scanner.Text(), "\t")
+		if !ok || word == "" || len(word) > math.MaxUint16 {
+			skipped++
+			continue
+		}
+		if _, err := strconv.ParseUint(strings.TrimSpace(freq), 10, 64); err != nil {
+			skipped++
+			continue
+		}
+		if seen[word] {
+			skipped++
+			continue
+		}
+		seen[word] = true
+		words = append(words, rankedWord{word: word, rank: uint16(min(len(words)+1, math.MaxUint16))})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", wordsPath, err)
+	}
+	if skipped > 0 {
+		log.Debugf("Skipped %d malformed or repeated lines in %s", skipped, wordsPath)
+	}
+	return words, nil
+}
+
+// writeChunk writes one chunk file. The file is replaced atomically, so a
+// loader never reads it half written. A compressed copy of an older chunk at
+// the same path is removed, so it can't be loaded in place of the new one.
+func writeChunk(path string, words []rankedWord) error {
+	sorted := slices.Clone(words)
+	slices.SortStableFunc(sorted, func(a, b rankedWord) int {
+		return strings.Compare(a.word, b.word)
+	})
+
+	tmpPath := path + ".tmp"
+	file, err := os.Create(tmpPath)
+	if err != nil {
+		return err
+	}
+	// bufio.Writer errors are sticky, so Flush reports any failed write
+	writer := bufio.NewWriter(file)
+	buf := appendChunkHeader(nil, len(sorted))
+	writer.Write(buf)
+	for _, entry := range sorted {
+		buf = binary.LittleEndian.AppendUint16(buf[:0], uint16(len(entry.word)))
+		buf = append(buf, entry.word...)
+		buf = binary.LittleEndian.AppendUint16(buf, entry.rank)
+		writer.Write(buf)
+	}
+	err = writer.Flush()
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tmpPath, path)
+	}
+	if err != nil {
+		os.Remove(tmpPath)
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	if err := os.Remove(path + gzipSuffix); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("failed to remove the older %s: %w", path+gzipSuffix, err)
+	}
+	return nil
+}
@@ -0,0 +1,146 @@
+package dictionary
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/tchap/go-patricia/v2/patricia"
+)
+
+func TestBuildChunksSameForAnyWorkerCount(t *testing.T) {
+	src := buildTestChunks(t, testWords(1050), 100)
+	wordsPath := filepath.Join(src, "words.txt")
+
+	var want [][]byte
+	for _, workers := range []int{1, 3, 16} {
+		dir := t.TempDir()
+		if err := BuildChunksWithWorkers(wordsPath, dir, 100, 0, workers); err != nil {
+			t.Fatal(err)
+		}
+		chunks, err := filepath.Glob(filepath.Join(dir, "dict_*.bin"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(chunks) != 11 {
+			t.Fatalf("%d workers wrote %d chunks, want 11", workers, len(chunks))
+		}
+		for i := range chunks {
+			data, err := os.ReadFile(filepath.Join(dir, fmt.Sprintf("dict_%04d.bin", i+1)))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(want) <= i {
+				want = append(want, data)
+				continue
+			}
+			if !bytes.Equal(data, want[i]) {
+				t.Errorf("chunk %d built by %d workers differs from the one built by 1", i+1, workers)
+			}
+		}
+	}
+}
+
+func TestBuildChunksMaxChunks(t *testing.T) {
+	src := buildTestChunks(t, testWords(1050), 100)
+	dir := t.TempDir()
+	if err := BuildChunksWithWorkers(filepath.Join(src, "words.txt"), dir, 100, 4, 2); err != nil {
+		t.Fatal(err)
+	}
+	chunks, err := filepath.Glob(filepath.Join(dir, "dict_*.bin"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(chunks) != 4 {
+		t.Errorf("wrote %d chunks, want 4", len(chunks))
+	}
+}
+
+func TestBuildChunksReportsFailure(t *testing.T) {
+	src := buildTestChunks(t, testWords(300), 100)
+	dir := t.TempDir()
+	// A directory in the way of a chunk file makes writing it fail
+	if err := os.Mkdir(filepath.Join(dir, "dict_0002.bin"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := BuildChunksWithWorkers(filepath.Join(src, "words.txt"), dir, 100, 0, 3); err == nil {
+		t.Fatal("BuildChunksWithWorkers returned no error for a chunk it couldn't write")
+	}
+}
+
+func TestBuildChunksRoundTrip(t *testing.T) {
+	// Mixed lengths and scripts, with a malformed line and a repeated word that are skipped
+	lines := "the\t900\nof\t800\nnot a line\nquantum\t700\nthe\t600\nnaïve\t500\n日本\t400\nz\t300\n"
+	dir := t.TempDir()
+	wordsPath := filepath.Join(dir, "words.txt")
+	if err := os.WriteFile(wordsPath, []byte(lines), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := BuildChunks(wordsPath, dir, 4, 0); err != nil {
+		t.Fatal(err)
+	}
+
+	loader := NewLoader(dir, 0)
+	for chunkID := 1; chunkID <= 2; chunkID++ {
+		if err := loader.Load(chunkID); err != nil {
+			t.Fatal(err)
+		}
+	}
+	trie := loader.GetTrie()
+	want := []string{"the", "of", "quantum", "naïve", "日本", "z"}
+	if count := countPrefix(trie, ""); count != len(want) {
+		t.Errorf("loaded %d words, want %d", count, len(want))
+	}
+	for i, word := range want {
+		item := trie.Get(patricia.Prefix(word))
+		if item == nil {
+			t.Errorf("%q missing after the round trip", word)
+			continue
+		}
+		if score, want := item.(int), RankInverse.Score(uint16(i+1)); score != want {
+			t.Errorf("%q scored %d, want rank %d scored %d", word, score, i+1, want)
+		}
+		chunkID, _ := loader.WordChunk(word)
+		if wantChunk := i/4 + 1; chunkID != wantChunk {
+			t.Errorf("%q loaded from chunk %d, want %d", word, chunkID, wantChunk)
+		}
+	}
+}
+
+func TestBuildChunksHeader(t *testing.T) {
+	dir := buildTestChunks(t, testWords(3), 10)
+	data, err := os.ReadFile(filepath.Join(dir, "dict_0001.bin"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.HasPrefix(data, []byte("WSD1")) {
+		t.Fatalf("chunk starts with %q, want the WSD1 magic", data[:min(len(data), 4)])
+	}
+	loader := NewLoader(dir, 0)
+	if count, err := loader.getWordCount(filepath.Join(dir, "dict_0001.bin")); err != nil || count != 3 {
+		t.Errorf("getWordCount = %d, %v, want 3", count, err)
+	}
+}
+
+func TestBuildChunksReplacesCompressedChunk(t *testing.T) {
+	dir := buildTestChunks(t, testWords(100), 100)
+	// An older compressed chunk, which the loader would prefer over the new one
+	old := buildTestChunks(t, []string{"stale"}, 100)
+	gzipChunk(t, filepath.Join(old, "dict_0001.bin"), dir)
+
+	if err := BuildChunks(filepath.Join(dir, "words.txt"), dir, 100, 0); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "dict_0001.bin"+gzipSuffix)); !os.IsNotExist(err) {
+		t.Fatalf("compressed chunk left next to the rebuilt one: %v", err)
+	}
+	loader := NewLoader(dir, 0)
+	if err := loader.Load(1); err != nil {
+		t.Fatal(err)
+	}
+	if trie := loader.GetTrie(); hasWord(trie, "stale") || !hasWord(trie, "word00000") {
+		t.Error("loaded the old chunk instead of the rebuilt one")
+	}
+}
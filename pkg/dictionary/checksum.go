@@ -0,0 +1,92 @@
+package dictionary
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+)
+
+// DefaultChecksumURL is where the SHA-256 of the release data.zip is published
+const DefaultChecksumURL = GHReleaseURL + "/data.zip.sha256"
+
+// SetChecksumURL sets where the expected SHA-256 of data.zip is fetched from.
+// The file may hold just the hex digest or sha256sum output ("<hash>  data.zip").
+// Self-hosted mirrors should point this at their own checksum file.
+// An empty URL skips verification.
+func (cl *Loader) SetChecksumURL(url string) {
+	cl.urlMu.Lock()
+	defer cl.urlMu.Unlock()
+	cl.checksumURL = url
+}
+
+// verifyDownload checks path against the published checksum, if any.
+// A file that does not match is deleted so a later attempt starts clean.
+func (cl *Loader) verifyDownload(ctx context.Context, path string) error {
+	cl.urlMu.RLock()
+	checksumURL := cl.checksumURL
+	cl.urlMu.RUnlock()
+	if checksumURL == "" {
+		return nil
+	}
+
+	expected, err := fetchChecksum(ctx, checksumURL)
+	if err != nil {
+		os.Remove(path)
+		return fmt.Errorf("failed to fetch checksum from %s: %w", checksumURL, err)
+	}
+	actual, err := fileSHA256(path)
+	if err != nil {
+		os.Remove(path)
+		return err
+	}
+	if !strings.EqualFold(expected, actual) {
+		os.Remove(path)
+		return fmt.Errorf("checksum mismatch for %s: expected %s, got %s", path, expected, actual)
+	}
+	return nil
+}
+
+// fetchChecksum downloads a checksum file and returns the hex digest in it
+func fetchChecksum(ctx context.Context, url string) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return "", err
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("HTTP %d: %s", resp.StatusCode, resp.Status)
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1024))
+	if err != nil {
+		return "", err
+	}
+	fields := strings.Fields(string(body))
+	if len(fields) == 0 || len(fields[0]) != sha256.Size*2 {
+		return "", fmt.Errorf("malformed checksum file")
+	}
+	return fields[0], nil
+}
+
+// fileSHA256 returns the hex SHA-256 digest of a file
+func fileSHA256(path string) (string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+	hash := sha256.New()
+	if _, err := io.Copy(hash, file); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
@@ -0,0 +1,93 @@
+package dictionary
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// gzipSuffix marks a gzip-compressed chunk file (dict_0001.bin.gz)
+const gzipSuffix = ".gz"
+
+// gzipFile closes both the gzip stream and the file underneath it
+type gzipFile struct {
+	*gzip.Reader
+	file *os.File
+}
+
+func (g *gzipFile) Close() error {
+	gzErr := g.Reader.Close()
+	if err := g.file.Close(); err != nil {
+		return err
+	}
+	return gzErr
+}
+
+// openChunk opens a chunk file for reading, decompressing .gz files on the fly.
+// The binary format inside a compressed chunk is the same as an uncompressed one.
+func openChunk(filename string) (io.ReadCloser, error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	if !strings.HasSuffix(filename, gzipSuffix) {
+		return file, nil
+	}
+	gz, err := gzip.NewReader(file)
+	if err != nil {
+		file.Close()
+		return nil, fmt.Errorf("failed to open gzip chunk %s: %w", filename, err)
+	}
+	return &gzipFile{Reader: gz, file: file}, nil
+}
+
+// chunkFilename returns the path of a chunk file, preferring the
+// compressed variant when both dict_XXXX.bin.gz and dict_XXXX.bin exist
+func (cl *Loader) chunkFilename(chunkID int) string {
+	filename := filepath.Join(cl.dirPath, fmt.Sprintf("dict_%04d.bin", chunkID))
+	if _, err := os.Stat(filename + gzipSuffix); err == nil {
+		return filename + gzipSuffix
+	}
+	return filename
+}
+
+// parseChunkID extracts the chunk ID from a chunk file name
+// (dict_0001.bin -> 1, dict_0001.bin.gz -> 1)
+func parseChunkID(basename string) (int, bool) {
+	name := strings.TrimSuffix(basename, gzipSuffix)
+	if !strings.HasPrefix(name, "dict_") || !strings.HasSuffix(name, ".bin") {
+		return 0, false
+	}
+	idStr := strings.TrimSuffix(strings.TrimPrefix(name, "dict_"), ".bin")
+	chunkID, err := strconv.Atoi(idStr)
+	if err != nil {
+		return 0, false
+	}
+	return chunkID, true
+}
+
+// globChunks returns chunk files in dir keyed by ID, preferring compressed files
+func globChunks(dir string) (map[int]string, error) {
+	chunks := make(map[int]string)
+	for _, pattern := range []string{"dict_*.bin", "dict_*.bin" + gzipSuffix} {
+		files, err := filepath.Glob(filepath.Join(dir, pattern))
+		if err != nil {
+			return nil, err
+		}
+		for _, file := range files {
+			chunkID, ok := parseChunkID(filepath.Base(file))
+			if !ok {
+				continue
+			}
+			if existing, found := chunks[chunkID]; found && strings.HasSuffix(existing, gzipSuffix) {
+				continue
+			}
+			chunks[chunkID] = file
+		}
+	}
+	return chunks, nil
+}
@@ -0,0 +1,80 @@
+package dictionary
+
+import (
+	"compress/gzip"
+	"maps"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// gzipChunk writes a gzip-compressed copy of the chunk file src to dir
+func gzipChunk(t *testing.T, src, dir string) string {
+	t.Helper()
+	data, err := os.ReadFile(src)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dst := filepath.Join(dir, filepath.Base(src)+gzipSuffix)
+	file, err := os.Create(dst)
+	if err != nil {
+		t.Fatal(err)
+	}
+	gz := gzip.NewWriter(file)
+	if _, err := gz.Write(data); err != nil {
+		t.Fatal(err)
+	}
+	if err := gz.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := file.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return dst
+}
+
+func TestLoadGzipChunk(t *testing.T) {
+	plainDir := buildTestChunks(t, testWords(300), 300)
+	gzipDir := t.TempDir()
+	plainFile := filepath.Join(plainDir, "dict_0001.bin")
+	gzipFile := gzipChunk(t, plainFile, gzipDir)
+
+	plain, compressed := NewLoader(plainDir, 0), NewLoader(gzipDir, 0)
+	if got := compressed.chunkFilename(1); got != gzipFile {
+		t.Fatalf("chunkFilename(1) = %s, want %s", got, gzipFile)
+	}
+	for _, loader := range []*Loader{plain, compressed} {
+		if err := loader.Load(1); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	plainCount, err := plain.getWordCount(plainFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	gzipCount, err := compressed.getWordCount(gzipFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if plainCount != 300 || gzipCount != plainCount {
+		t.Errorf("header word counts: .bin %d, .bin.gz %d, want 300 both", plainCount, gzipCount)
+	}
+	if !maps.Equal(compressed.GetWordFreqs(), plain.GetWordFreqs()) {
+		t.Errorf("words loaded from .bin.gz differ from .bin: %d and %d words",
+			len(compressed.GetWordFreqs()), len(plain.GetWordFreqs()))
+	}
+}
+
+func TestGlobChunksPrefersGzip(t *testing.T) {
+	dir := buildTestChunks(t, testWords(20), 10)
+	compressed := gzipChunk(t, filepath.Join(dir, "dict_0002.bin"), dir)
+
+	chunks, err := globChunks(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(chunks) != 2 || chunks[1] != filepath.Join(dir, "dict_0001.bin") || chunks[2] != compressed {
+		t.Errorf("globChunks = %v, want dict_0001.bin and dict_0002.bin.gz", chunks)
+	}
+}
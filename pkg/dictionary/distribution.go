@@ -0,0 +1,59 @@
+package dictionary
+
+import (
+	"math"
+	"slices"
+	"sort"
+)
+
+// FrequencyDistribution is the sorted scores of every loaded word, for ranking
+// one word's frequency against the whole dictionary rather than a result set.
+type FrequencyDistribution struct {
+	scores  []int // ascending
+	version uint64
+}
+
+// newFrequencyDistribution sorts the scores of freqs
+func newFrequencyDistribution(freqs map[string]int, version uint64) *FrequencyDistribution {
+	scores := make([]int, 0, len(freqs))
+	for _, score := range freqs {
+		scores = append(scores, score)
+	}
+	slices.Sort(scores)
+	return &FrequencyDistribution{scores: scores, version: version}
+}
+
+// Percentile returns the share of words less frequent than freq, from 0 to 100
+// with two decimals. 99.5 means freq is in the top 0.5% of the dictionary.
+// An empty distribution returns 0.
+func (d *FrequencyDistribution) Percentile(freq int) float64 {
+	if d == nil || len(d.scores) == 0 {
+		return 0
+	}
+	below := sort.SearchInts(d.scores, freq)
+	return math.Round(float64(below)*10000/float64(len(d.scores))) / 100
+}
+
+// Size returns the number of words in the distribution
+func (d *FrequencyDistribution) Size() int {
+	if d == nil {
+		return 0
+	}
+	return len(d.scores)
+}
+
+// Distribution returns the frequency distribution of the loaded words.
+// It is built on first use after chunks load or evict and reused until the next change,
+// so percentiles stay stable while the dictionary size does.
+func (cl *Loader) Distribution() *FrequencyDistribution {
+	version := cl.Version()
+	cl.distMu.Lock()
+	defer cl.distMu.Unlock()
+	if cl.distribution != nil && cl.distribution.version == version {
+		return cl.distribution
+	}
+	cl.mu.RLock()
+	cl.distribution = newFrequencyDistribution(cl.wordFreqs, version)
+	cl.mu.RUnlock()
+	return cl.distribution
+}
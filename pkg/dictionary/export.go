@@ -0,0 +1,122 @@
+package dictionary
+
+import (
+	"cmp"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/charmbracelet/log"
+	"github.com/tchap/go-patricia/v2/patricia"
+)
+
+// scoredWord is a trie word with its score
+type scoredWord struct {
+	word  string
+	score int
+}
+
+// ExportChunks writes the words in the active trie, loaded chunks and runtime
+// words alike, to outDir as dict_XXXX.bin chunk files another Loader can read.
+//
+// Words are re-ranked by score, highest first (rank 1, capped at 65535), and
+// split into chunks of chunkSize words in the format BuildChunks writes.
+// A matching words.txt, "word<TAB>score" by rank, is written next to them, so
+// the directory is a complete data dir and BuildChunks can rebuild the chunks.
+// Other chunk files in outDir, compressed ones included, are removed after
+// the export so the directory holds exactly the exported set. outDir can't be
+// the loader's own directory, whose chunks may still be loaded from.
+func (cl *Loader) ExportChunks(outDir string, chunkSize int) error {
+	if chunkSize <= 0 {
+		return fmt.Errorf("chunk size must be positive, got %d", chunkSize)
+	}
+	if sameDir(outDir, cl.dirPath) {
+		return fmt.Errorf("can't export into the loaded dictionary dir %s", cl.dirPath)
+	}
+
+	var scored []scoredWord
+	cl.mu.RLock()
+	cl.trie.Visit(func(prefix patricia.Prefix, item patricia.Item) error {
+		if score, ok := item.(int); ok {
+			scored = append(scored, scoredWord{word: string(prefix), score: score})
+		}
+		return nil
+	})
+	cl.mu.RUnlock()
+	if len(scored) == 0 {
+		return fmt.Errorf("no loaded words to export")
+	}
+
+	slices.SortFunc(scored, func(a, b scoredWord) int {
+		if c := cmp.Compare(b.score, a.score); c != 0 {
+			return c
+		}
+		return strings.Compare(a.word, b.word)
+	})
+	words := make([]rankedWord, len(scored))
+	for i, entry := range scored {
+		words[i] = rankedWord{word: entry.word, rank: uint16(min(i+1, math.MaxUint16))}
+	}
+
+	if err := os.MkdirAll(outDir, 0o755); err != nil {
+		return err
+	}
+	if err := writeWordList(filepath.Join(outDir, "words.txt"), scored); err != nil {
+		return err
+	}
+	existing, err := globChunks(outDir)
+	if err != nil {
+		return err
+	}
+	totalChunks := (len(words) + chunkSize - 1) / chunkSize
+	for chunkID := 1; chunkID <= totalChunks; chunkID++ {
+		start := (chunkID - 1) * chunkSize
+		end := min(start+chunkSize, len(words))
+		path := filepath.Join(outDir, fmt.Sprintf("dict_%04d.bin", chunkID))
+		if err := writeChunk(path, words[start:end]); err != nil {
+			return err
+		}
+	}
+	for chunkID, file := range existing {
+		if chunkID > totalChunks {
+			os.Remove(file)
+			os.Remove(strings.TrimSuffix(file, gzipSuffix))
+		}
+	}
+	log.Debugf("Exported %d words in %d chunks to %s", len(words), totalChunks, outDir)
+	return nil
+}
+
+// writeWordList writes words.txt lines for words in their given order.
+// The file is replaced atomically like the chunks.
+func writeWordList(path string, words []scoredWord) error {
+	var content strings.Builder
+	for _, entry := range words {
+		fmt.Fprintf(&content, "%s\t%d\n", entry.word, entry.score)
+	}
+	tmpPath := path + ".tmp"
+	if err := os.WriteFile(tmpPath, []byte(content.String()), 0o644); err != nil {
+		os.Remove(tmpPath)
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	if err := os.Rename(tmpPath, path); err != nil {
+		os.Remove(tmpPath)
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	return nil
+}
+
+// sameDir reports whether two paths name the same directory
+func sameDir(a, b string) bool {
+	aInfo, aErr := os.Stat(a)
+	bInfo, bErr := os.Stat(b)
+	if aErr == nil && bErr == nil {
+		return os.SameFile(aInfo, bInfo)
+	}
+	aAbs, aErr := filepath.Abs(a)
+	bAbs, bErr := filepath.Abs(b)
+	return aErr == nil && bErr == nil && aAbs == bAbs
+}
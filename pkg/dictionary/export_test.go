@@ -0,0 +1,128 @@
+package dictionary
+
+import (
+	"bytes"
+	"cmp"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/tchap/go-patricia/v2/patricia"
+)
+
+// loadAll returns a loader that has loaded every chunk in dir
+func loadAll(t *testing.T, dir string) *Loader {
+	t.Helper()
+	loader := NewLoader(dir, 0)
+	files, err := globChunks(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for chunkID := range files {
+		if err := loader.Load(chunkID); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return loader
+}
+
+// rankedWords returns the words of trie, highest score first, ties A to Z
+func rankedWords(trie Trie) []string {
+	var scored []scoredWord
+	trie.Visit(func(prefix patricia.Prefix, item patricia.Item) error {
+		scored = append(scored, scoredWord{word: string(prefix), score: item.(int)})
+		return nil
+	})
+	slices.SortFunc(scored, func(a, b scoredWord) int {
+		if c := cmp.Compare(b.score, a.score); c != 0 {
+			return c
+		}
+		return strings.Compare(a.word, b.word)
+	})
+	words := make([]string, len(scored))
+	for i, entry := range scored {
+		words[i] = entry.word
+	}
+	return words
+}
+
+func TestExportChunksRoundTrip(t *testing.T) {
+	loader := loadAll(t, buildTestChunks(t, testWords(300), 100))
+	if err := loader.AddWord("kubernetes", 1_000_000); err != nil {
+		t.Fatal(err)
+	}
+	loader.RemoveWord("word00007")
+	want := rankedWords(loader.GetTrie())
+
+	outDir := t.TempDir()
+	if err := loader.ExportChunks(outDir, 70); err != nil {
+		t.Fatal(err)
+	}
+	exported := loadAll(t, outDir)
+	if got := rankedWords(exported.GetTrie()); !slices.Equal(got, want) {
+		t.Fatalf("exported %d words, want the %d loaded in the same order", len(got), len(want))
+	}
+	if got := exported.GetTrie().Get(patricia.Prefix("kubernetes")); got != RankInverse.Score(1) {
+		t.Errorf("runtime word scored %v, want rank 1", got)
+	}
+
+	// Exporting the export again gives the same chunks. words.txt differs, it
+	// holds the scores, which the re-ranking changed
+	again := t.TempDir()
+	if err := exported.ExportChunks(again, 70); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"dict_0001.bin", "dict_0005.bin"} {
+		first, err := os.ReadFile(filepath.Join(outDir, name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		second, err := os.ReadFile(filepath.Join(again, name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(first, second) {
+			t.Errorf("%s differs between an export and the export of it", name)
+		}
+	}
+
+	// A loader reading the export finds its chunks without rebuilding them
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	t.Setenv("WORDSERVE_MAX_WORDS", strconv.Itoa(len(want)))
+	t.Setenv("WORDSERVE_CHUNK_SIZE", "70")
+	reader := NewLoader(outDir, 0)
+	reader.SetReleaseURL(offlineRelease(t))
+	chunks, err := reader.GetAvailable()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(chunks) != 5 {
+		t.Errorf("GetAvailable found %d chunks, want 5", len(chunks))
+	}
+}
+
+func TestExportChunksRemovesOtherChunks(t *testing.T) {
+	loader := loadAll(t, buildTestChunks(t, testWords(100), 100))
+	outDir := buildTestChunks(t, testWords(500), 100)
+	if err := loader.ExportChunks(outDir, 50); err != nil {
+		t.Fatal(err)
+	}
+	files, err := globChunks(outDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != 2 {
+		t.Errorf("%d chunks left after exporting 2, want 2: %v", len(files), files)
+	}
+}
+
+func TestExportChunksIntoLoadedDirFails(t *testing.T) {
+	dir := buildTestChunks(t, testWords(100), 100)
+	loader := loadAll(t, dir)
+	if err := loader.ExportChunks(dir, 100); err == nil {
+		t.Error("exporting into the loaded dir returned no error")
+	}
+}
@@ -1,8 +1,8 @@
 package dictionary
 
 import (
-	"encoding/binary"
 	"errors"
+	"fmt"
 	"os"
 	"path/filepath"
 	"slices"
@@ -22,6 +22,47 @@ const (
 	FormatText
 )
 
+// RankConversion selects how the uint16 value stored with each chunk word becomes its score.
+// Higher scores rank first either way.
+type RankConversion int
+
+const (
+	// RankInverse reads the value as a rank, 1 = most frequent, and scores it 65535 - rank + 1.
+	// This is what BuildChunks writes and the default.
+	RankInverse RankConversion = iota
+	// RawFrequency reads the value as a frequency and uses it as the score as is,
+	// for dictionaries built from counts rather than ranks.
+	RawFrequency
+)
+
+// String returns the config name of the conversion.
+func (rc RankConversion) String() string {
+	if rc == RawFrequency {
+		return "raw_frequency"
+	}
+	return "rank_inverse"
+}
+
+// Score converts a stored value to a score
+func (rc RankConversion) Score(value uint16) int {
+	if rc == RawFrequency {
+		return int(value)
+	}
+	return int(65535 - value + 1)
+}
+
+// ParseRankConversion parses a config value ("rank_inverse" or "raw_frequency").
+// An empty string is the default RankInverse.
+func ParseRankConversion(s string) (RankConversion, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "", "rank_inverse":
+		return RankInverse, nil
+	case "raw_frequency":
+		return RawFrequency, nil
+	}
+	return RankInverse, fmt.Errorf("unknown rank conversion: %q", s)
+}
+
 // FormatInfo has the metadata for each file format
 type FormatInfo struct {
 	Format      FileFormat
@@ -34,8 +75,8 @@ var supportedFormats = map[FileFormat]FormatInfo{
 	FormatBinary: {
 		Format:      FormatBinary,
 		Description: "Binary Dictionary",
-		Extensions:  []string{".bin"},
-		MinSize:     4, // At least word count header
+		Extensions:  []string{".bin"}, // optionally gzip-compressed (.bin.gz)
+		MinSize:     4,                // At least the legacy word count header
 	},
 	FormatText: {
 		Format:      FormatText,
@@ -69,7 +110,7 @@ func ValidateFileFormat(filename string, expectedFormat FileFormat) error {
 		return errors.New("file too small")
 	}
 	// extension
-	ext := strings.ToLower(filepath.Ext(filename))
+	ext := strings.ToLower(filepath.Ext(strings.TrimSuffix(filename, gzipSuffix)))
 	if !slices.Contains(formatInfo.Extensions, ext) {
 		log.Errorf("file %s has invalid extension %s for format %s (expected: %v)",
 			filename, ext, formatInfo.Description, formatInfo.Extensions)
@@ -86,16 +127,16 @@ func ValidateFileFormat(filename string, expectedFormat FileFormat) error {
 
 // validateBinaryFormat checks if binary files are in the expected format
 func validateBinaryFormat(filename string) error {
-	file, err := os.Open(filename)
+	file, err := openChunk(filename)
 	if err != nil {
 		log.Errorf("failed to open file %s: %v", filename, err)
 		return err
 	}
 	defer file.Close()
 
-	// check if we can read the header (word count)
-	var wordCount int32
-	if err := binary.Read(file, binary.LittleEndian, &wordCount); err != nil {
+	// check if we can read the header (word count), legacy or versioned
+	wordCount, version, err := readChunkHeader(file)
+	if err != nil {
 		log.Errorf("failed to read header from %s: %v", filename, err)
 		return err
 	}
@@ -110,7 +151,7 @@ func validateBinaryFormat(filename string) error {
 		log.Errorf("questionable word count in %s: %d (too large, max: %d)", filename, wordCount, cfg.Dict.MaxWordCountValidation)
 		return errors.New("word count too large")
 	}
-	log.Debugf("Binary file %s validated: %d words", filename, wordCount)
+	log.Debugf("Binary file %s validated: %d words, format version %d", filename, wordCount, version)
 	return nil
 }
 
@@ -138,7 +179,7 @@ func validateTextFormat(filename string) error {
 
 // DetectFileFormat attempts to detect the format of a file
 func DetectFileFormat(filename string) (FileFormat, error) {
-	ext := strings.ToLower(filepath.Ext(filename))
+	ext := strings.ToLower(filepath.Ext(strings.TrimSuffix(filename, gzipSuffix)))
 
 	if ext == ".bin" {
 		if err := ValidateFileFormat(filename, FormatBinary); err == nil {
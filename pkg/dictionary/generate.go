@@ -0,0 +1,164 @@
+package dictionary
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/charmbracelet/log"
+)
+
+// GenerationState describes where a chunk generation job is at
+type GenerationState string
+
+const (
+	GenerationRunning   GenerationState = "running"
+	GenerationDone      GenerationState = "done"
+	GenerationFailed    GenerationState = "failed"
+	GenerationCancelled GenerationState = "cancelled"
+)
+
+// GenerationStatus reports the progress of a chunk generation job
+type GenerationStatus struct {
+	ID           int
+	TargetChunks int
+	State        GenerationState
+	Stage        string // "build" (from words.txt) or "download"
+	StartedAt    time.Time
+	FinishedAt   time.Time
+	Error        string
+}
+
+// generation is a chunk generation job running in the background
+type generation struct {
+	status GenerationStatus
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// StartGeneration generates chunk files up to targetChunks in the background
+// and returns the job ID. Only one job runs at a time, so if one is already
+// running its ID is returned instead of starting another.
+func (cl *Loader) StartGeneration(targetChunks int) int {
+	cl.genMu.Lock()
+	defer cl.genMu.Unlock()
+
+	for id, g := range cl.generations {
+		if g.status.State == GenerationRunning {
+			log.Debugf("Generation %d already running, not starting another", id)
+			return id
+		}
+	}
+
+	cl.nextGeneration++
+	ctx, cancel := context.WithCancel(context.Background())
+	g := &generation{
+		status: GenerationStatus{
+			ID:           cl.nextGeneration,
+			TargetChunks: targetChunks,
+			State:        GenerationRunning,
+			StartedAt:    time.Now(),
+		},
+		cancel: cancel,
+		done:   make(chan struct{}),
+	}
+	cl.generations[g.status.ID] = g
+
+	go cl.runGeneration(ctx, g)
+	return g.status.ID
+}
+
+// runGeneration runs a generation job and records how it ended
+func (cl *Loader) runGeneration(ctx context.Context, g *generation) {
+	defer close(g.done)
+	defer g.cancel()
+
+	err := cl.checkChunkCount(ctx, g.status.TargetChunks, func(stage string) {
+		cl.genMu.Lock()
+		g.status.Stage = stage
+		cl.genMu.Unlock()
+	})
+
+	cl.genMu.Lock()
+	defer cl.genMu.Unlock()
+	g.status.FinishedAt = time.Now()
+	switch {
+	case errors.Is(err, context.Canceled):
+		g.status.State = GenerationCancelled
+		g.status.Error = err.Error()
+		log.Infof("Chunk generation %d cancelled", g.status.ID)
+	case err != nil:
+		g.status.State = GenerationFailed
+		g.status.Error = err.Error()
+		log.Errorf("Chunk generation %d failed: %v", g.status.ID, err)
+	default:
+		g.status.State = GenerationDone
+		log.Debugf("Chunk generation %d done", g.status.ID)
+	}
+}
+
+// WaitGeneration blocks until the job finishes or ctx is done.
+// It returns the job's error, if any.
+func (cl *Loader) WaitGeneration(ctx context.Context, id int) error {
+	cl.genMu.Lock()
+	g, exists := cl.generations[id]
+	cl.genMu.Unlock()
+	if !exists {
+		return fmt.Errorf("unknown generation: %d", id)
+	}
+
+	select {
+	case <-g.done:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+
+	cl.genMu.Lock()
+	defer cl.genMu.Unlock()
+	if g.status.State != GenerationDone {
+		return fmt.Errorf("chunk generation %s: %s", g.status.State, g.status.Error)
+	}
+	return nil
+}
+
+// CancelGeneration stops a running generation job
+func (cl *Loader) CancelGeneration(id int) error {
+	cl.genMu.Lock()
+	defer cl.genMu.Unlock()
+	g, exists := cl.generations[id]
+	if !exists {
+		return fmt.Errorf("unknown generation: %d", id)
+	}
+	if g.status.State != GenerationRunning {
+		return fmt.Errorf("generation %d is not running (%s)", id, g.status.State)
+	}
+	g.cancel()
+	return nil
+}
+
+// GetGeneration returns the status of a single generation job
+func (cl *Loader) GetGeneration(id int) (GenerationStatus, bool) {
+	cl.genMu.Lock()
+	defer cl.genMu.Unlock()
+	g, exists := cl.generations[id]
+	if !exists {
+		return GenerationStatus{}, false
+	}
+	return g.status, true
+}
+
+// ListGenerations returns the status of all generation jobs, oldest first
+func (cl *Loader) ListGenerations() []GenerationStatus {
+	cl.genMu.Lock()
+	defer cl.genMu.Unlock()
+	statuses := make([]GenerationStatus, 0, len(cl.generations))
+	for _, g := range cl.generations {
+		statuses = append(statuses, g.status)
+	}
+	sort.Slice(statuses, func(i, j int) bool {
+		return statuses[i].ID < statuses[j].ID
+	})
+	return statuses
+}
@@ -0,0 +1,38 @@
+package dictionary
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// offlineRelease serves 404 for every release file, so downloads fail fast
+func offlineRelease(t testing.TB) string {
+	t.Helper()
+	server := httptest.NewServer(http.NotFoundHandler())
+	t.Cleanup(server.Close)
+	return server.URL
+}
+
+func TestGenerationFailureIsReported(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	// No words.txt to build from and no release to download, generation has to fail
+	loader := NewLoader(t.TempDir(), 0)
+	loader.SetReleaseURL(offlineRelease(t))
+
+	id := loader.StartGeneration(3)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := loader.WaitGeneration(ctx, id); err == nil {
+		t.Fatal("WaitGeneration returned no error for a failed generation")
+	}
+	status, ok := loader.GetGeneration(id)
+	if !ok {
+		t.Fatalf("generation %d not found", id)
+	}
+	if status.State != GenerationFailed || status.Error == "" {
+		t.Errorf("generation ended %q with error %q, want %q with an error", status.State, status.Error, GenerationFailed)
+	}
+}
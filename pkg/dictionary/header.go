@@ -0,0 +1,48 @@
+package dictionary
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+)
+
+// chunkMagic starts chunk files written since the format got a version byte.
+// Read as a legacy little endian word count it would be 826,561,367 words,
+// far past any real chunk, so the two layouts can't be confused.
+const chunkMagic = "WSD1"
+
+// chunkFormatVersion is the version byte written after chunkMagic.
+// Readers reject newer versions rather than misparse them.
+const chunkFormatVersion = 1
+
+// readChunkHeader reads the header of a chunk file and returns its word count
+// and format version. Files starting with chunkMagic carry a version byte before
+// the count; legacy files start straight with the int32 count and report version 0.
+func readChunkHeader(r io.Reader) (wordCount int32, version int, err error) {
+	var head [4]byte
+	if _, err := io.ReadFull(r, head[:]); err != nil {
+		return 0, 0, err
+	}
+	if string(head[:]) != chunkMagic {
+		return int32(binary.LittleEndian.Uint32(head[:])), 0, nil
+	}
+	var versionByte [1]byte
+	if _, err := io.ReadFull(r, versionByte[:]); err != nil {
+		return 0, 0, fmt.Errorf("failed to read chunk format version: %w", err)
+	}
+	version = int(versionByte[0])
+	if version != chunkFormatVersion {
+		return 0, version, fmt.Errorf("unsupported chunk format version %d (this build reads up to %d)", version, chunkFormatVersion)
+	}
+	if err := binary.Read(r, binary.LittleEndian, &wordCount); err != nil {
+		return 0, version, fmt.Errorf("failed to read word count: %w", err)
+	}
+	return wordCount, version, nil
+}
+
+// appendChunkHeader appends the current chunk header for wordCount words
+func appendChunkHeader(buf []byte, wordCount int) []byte {
+	buf = append(buf, chunkMagic...)
+	buf = append(buf, chunkFormatVersion)
+	return binary.LittleEndian.AppendUint32(buf, uint32(wordCount))
+}
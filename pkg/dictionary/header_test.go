@@ -0,0 +1,112 @@
+package dictionary
+
+import (
+	"bytes"
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/tchap/go-patricia/v2/patricia"
+)
+
+// chunkBody appends the entries of words, in the given order, as they follow a chunk header
+func chunkBody(buf []byte, words []rankedWord) []byte {
+	for _, entry := range words {
+		buf = binary.LittleEndian.AppendUint16(buf, uint16(len(entry.word)))
+		buf = append(buf, entry.word...)
+		buf = binary.LittleEndian.AppendUint16(buf, entry.rank)
+	}
+	return buf
+}
+
+// legacyChunk returns a headerless chunk, starting straight with the word count
+func legacyChunk(words []rankedWord) []byte {
+	buf := binary.LittleEndian.AppendUint32(nil, uint32(len(words)))
+	return chunkBody(buf, words)
+}
+
+// versionedChunk returns a chunk with the WSD1 magic and the given format version
+func versionedChunk(version byte, words []rankedWord) []byte {
+	buf := append([]byte(chunkMagic), version)
+	buf = binary.LittleEndian.AppendUint32(buf, uint32(len(words)))
+	return chunkBody(buf, words)
+}
+
+var headerWords = []rankedWord{{"apple", 2}, {"banana", 3}, {"cherry", 1}}
+
+func TestReadChunkHeader(t *testing.T) {
+	tests := []struct {
+		name        string
+		data        []byte
+		wantCount   int32
+		wantVersion int
+		wantErr     bool
+	}{
+		{"legacy", legacyChunk(headerWords), 3, 0, false},
+		{"v1", versionedChunk(1, headerWords), 3, 1, false},
+		{"future version", versionedChunk(2, headerWords), 0, 2, true},
+		{"magic without version", []byte(chunkMagic), 0, 0, true},
+		{"magic without count", append([]byte(chunkMagic), 1, 0), 0, 1, true},
+		{"too short", []byte{3, 0}, 0, 0, true},
+	}
+	for _, tt := range tests {
+		count, version, err := readChunkHeader(bytes.NewReader(tt.data))
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%s: error = %v, want error %t", tt.name, err, tt.wantErr)
+			continue
+		}
+		if err == nil && (count != tt.wantCount || version != tt.wantVersion) {
+			t.Errorf("%s: got %d words in version %d, want %d in version %d", tt.name, count, version, tt.wantCount, tt.wantVersion)
+		}
+	}
+}
+
+func TestLoadChunkFormats(t *testing.T) {
+	for name, data := range map[string][]byte{
+		"legacy": legacyChunk(headerWords),
+		"v1":     versionedChunk(chunkFormatVersion, headerWords),
+	} {
+		t.Run(name, func(t *testing.T) {
+			dir := t.TempDir()
+			path := filepath.Join(dir, "dict_0001.bin")
+			if err := os.WriteFile(path, data, 0o644); err != nil {
+				t.Fatal(err)
+			}
+			if err := validateBinaryFormat(path); err != nil {
+				t.Errorf("validateBinaryFormat = %v", err)
+			}
+			loader := NewLoader(dir, 0)
+			if count, err := loader.getWordCount(path); err != nil || count != len(headerWords) {
+				t.Errorf("getWordCount = %d, %v, want %d", count, err, len(headerWords))
+			}
+			if err := loader.Load(1); err != nil {
+				t.Fatal(err)
+			}
+			trie := loader.GetTrie()
+			for _, entry := range headerWords {
+				if got := trie.Get(patricia.Prefix(entry.word)); got != RankInverse.Score(entry.rank) {
+					t.Errorf("%s scored %v, want rank %d scored %d", entry.word, got, entry.rank, RankInverse.Score(entry.rank))
+				}
+			}
+		})
+	}
+}
+
+func TestLoadRejectsNewerFormat(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "dict_0001.bin")
+	if err := os.WriteFile(path, versionedChunk(chunkFormatVersion+1, headerWords), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := validateBinaryFormat(path); err == nil {
+		t.Error("validateBinaryFormat accepted a newer format version")
+	}
+	loader := NewLoader(dir, 0)
+	if err := loader.Load(1); err == nil {
+		t.Error("Load accepted a newer format version")
+	}
+	if countPrefix(loader.GetTrie(), "") != 0 {
+		t.Error("words loaded from a newer format version")
+	}
+}
@@ -0,0 +1,185 @@
+package dictionary
+
+import (
+	"maps"
+	"slices"
+
+	"github.com/tchap/go-patricia/v2/patricia"
+)
+
+// Trie is a read-only set of words and their frequencies, searched by prefix.
+// *patricia.Trie implements it, and so does the trie a Loader publishes.
+type Trie interface {
+	Visit(visitor patricia.VisitorFunc) error
+	VisitSubtree(prefix patricia.Prefix, visitor patricia.VisitorFunc) error
+	Get(key patricia.Prefix) patricia.Item
+}
+
+// layeredTrie is the trie a Loader publishes: a patricia trie for each loaded
+// chunk and a small one for the runtime words, walked as one. It is never
+// changed once published. A change builds a new layeredTrie that shares the
+// layers it doesn't touch, so loading a chunk builds only that chunk's trie
+// and a runtime word copies only the runtime words.
+//
+// Walks visit the runtime words first, then each chunk in ID order.
+type layeredTrie struct {
+	user      *patricia.Trie // runtime words, which take precedence over the chunks'
+	userCount int
+	chunks    []*chunkLayer     // by chunk ID, no word is in two of them
+	removed   map[string]uint64 // words removed at runtime, with the version of the removal
+}
+
+// chunkLayer is the trie of one loaded chunk
+type chunkLayer struct {
+	id       int
+	trie     *patricia.Trie
+	version  uint64 // when it was published, a word removed later is hidden in it
+	maxScore int
+}
+
+// newLayeredTrie returns a trie with no words
+func newLayeredTrie() *layeredTrie {
+	return &layeredTrie{user: patricia.NewTrie()}
+}
+
+// newChunkLayer builds a sorted trie of a chunk's words, ready to be published
+func newChunkLayer(chunkID int, words map[string]int) *chunkLayer {
+	layer := &chunkLayer{id: chunkID, trie: patricia.NewTrie()}
+	for word, score := range words {
+		layer.trie.Insert(patricia.Prefix(word), score)
+		layer.maxScore = max(layer.maxScore, score)
+	}
+	sortTrie(layer.trie)
+	return layer
+}
+
+// hidden reports whether a word of a chunk layer is left out of walks,
+// because a runtime word replaces it or it was removed after the layer was built
+func (t *layeredTrie) hidden(layer *chunkLayer, word patricia.Prefix) bool {
+	if len(t.removed) > 0 {
+		if removedAt, ok := t.removed[string(word)]; ok && removedAt > layer.version {
+			return true
+		}
+	}
+	return t.userCount > 0 && t.user.Get(word) != nil
+}
+
+// Visit calls visitor on every word
+func (t *layeredTrie) Visit(visitor patricia.VisitorFunc) error {
+	return t.VisitSubtree(patricia.Prefix{}, visitor)
+}
+
+// VisitSubtree calls visitor on every word starting with prefix.
+// A visitor returning patricia.SkipSubtree skips the words under the current
+// one in its layer only.
+func (t *layeredTrie) VisitSubtree(prefix patricia.Prefix, visitor patricia.VisitorFunc) error {
+	if t.userCount > 0 {
+		if err := t.user.VisitSubtree(prefix, visitor); err != nil {
+			return err
+		}
+	}
+	for _, layer := range t.chunks {
+		err := layer.trie.VisitSubtree(prefix, func(word patricia.Prefix, item patricia.Item) error {
+			if t.hidden(layer, word) {
+				return nil
+			}
+			return visitor(word, item)
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Get returns the frequency of a word, or nil if it isn't in the trie
+func (t *layeredTrie) Get(key patricia.Prefix) patricia.Item {
+	if item := t.user.Get(key); item != nil {
+		return item
+	}
+	for _, layer := range t.chunks {
+		if item := layer.trie.Get(key); item != nil && !t.hidden(layer, key) {
+			return item
+		}
+	}
+	return nil
+}
+
+// layer returns the layer of a chunk, or nil if it isn't loaded
+func (t *layeredTrie) layer(chunkID int) *chunkLayer {
+	if i, found := t.layerIndex(chunkID); found {
+		return t.chunks[i]
+	}
+	return nil
+}
+
+// layerIndex returns where a chunk's layer is, or would go, in t.chunks
+func (t *layeredTrie) layerIndex(chunkID int) (int, bool) {
+	return slices.BinarySearchFunc(t.chunks, chunkID, func(layer *chunkLayer, id int) int {
+		return layer.id - id
+	})
+}
+
+// withLayer returns a copy of t with layer added, or replacing the layer of the same chunk
+func (t *layeredTrie) withLayer(layer *chunkLayer) *layeredTrie {
+	next := *t
+	next.chunks = slices.Clone(t.chunks)
+	if i, found := t.layerIndex(layer.id); found {
+		next.chunks[i] = layer
+	} else {
+		next.chunks = slices.Insert(next.chunks, i, layer)
+	}
+	return &next
+}
+
+// withoutLayer returns a copy of t without a chunk's layer
+func (t *layeredTrie) withoutLayer(chunkID int) *layeredTrie {
+	next := *t
+	next.chunks = slices.Clone(t.chunks)
+	if i, found := t.layerIndex(chunkID); found {
+		next.chunks = slices.Delete(next.chunks, i, i+1)
+	}
+	return &next
+}
+
+// withUserWords returns a copy of t with the runtime words replaced by user
+func (t *layeredTrie) withUserWords(user *patricia.Trie, userCount int) *layeredTrie {
+	next := *t
+	next.user = user
+	next.userCount = userCount
+	return &next
+}
+
+// withRemoved returns a copy of t with word hidden in the layers published before version
+func (t *layeredTrie) withRemoved(word string, version uint64) *layeredTrie {
+	next := *t
+	next.removed = maps.Clone(t.removed)
+	if next.removed == nil {
+		next.removed = make(map[string]uint64, 1)
+	}
+	next.removed[word] = version
+	return &next
+}
+
+// addVisibleWords adds the words of a layer that weren't removed after it was built to words
+func (t *layeredTrie) addVisibleWords(layer *chunkLayer, words map[string]int) {
+	layer.trie.Visit(func(word patricia.Prefix, item patricia.Item) error {
+		removedAt, removed := t.removed[string(word)]
+		if score, ok := item.(int); ok && (!removed || removedAt < layer.version) {
+			words[string(word)] = score
+		}
+		return nil
+	})
+}
+
+// maxScore returns the highest frequency of the chunk layers and userWords
+func (t *layeredTrie) maxScore(userWords map[string]int) int {
+	maxScore := 0
+	for _, layer := range t.chunks {
+		maxScore = max(maxScore, layer.maxScore)
+	}
+	for _, freq := range userWords {
+		maxScore = max(maxScore, freq)
+	}
+	return maxScore
+}
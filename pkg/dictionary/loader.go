@@ -2,7 +2,7 @@
 Package dictionary manages chunked binary dictionary files with lazy loading and runtime memory management.
 
 The dictionary package provides infrastructure for handling large word frequency datasets through a chunked file system. Words are stored in binary files with a specific format:
->> Each chunk contains a header with word count followed by word entries with their frequency rankings. The package supports both validation of file formats and dynamic loading/unloading of chunks during runtime.
+>> Each chunk contains a header with word count followed by word entries with their frequency rankings. Current chunks start the header with a "WSD1" magic and a format version byte; legacy chunks without them are still read. The package supports both validation of file formats and dynamic loading/unloading of chunks during runtime.
 
 Core functionality revolves around the Loader type, which manages concurrent access to multiple dictionary chunks. Each chunk file follows the naming pattern
 
@@ -11,6 +11,9 @@ Core functionality revolves around the Loader type, which manages concurrent acc
 
 and contains a subset of the total dictionary. Enables applications to load only the most relevant words based on frequency ranking, and mem usage controlled.
 
+Chunks may also be gzip-compressed as dict_XXXX.bin.gz. The format inside is the same,
+and the compressed file is used when both exist.
+
 The binary format stores words with their rank values rather than raw frequencies. During init, words are ranked by frequency
 
 	(rank 1 = most freq)
@@ -21,17 +24,35 @@ The loader converts these ranks back to frequency scores using the formula:
 
 	score = 65535 - rank + 1
 
-higher freq words receive higher scores for sorting.
+higher freq words receive higher scores for sorting. Dictionaries that store raw
+frequencies instead can be read as is with SetRankConversion(RawFrequency).
 
 # Chunk
 
-The loader operates with a goroutine that processes loading requests from a buffered channel. Prevents blocking the main thread.
+The loader operates with goroutines that process loading requests from a buffered channel. Prevents blocking the main thread.
+With several CPUs, several chunks load at once.
 Error handling includes automatic retry with exponential backoff for failed chunk loads.
 
 	loader := dictionary.NewLoader("data/", 50000)
 	err := loader.StartLoading()
 	trie := loader.GetTrie()
 
+Chunks are queued in ID order by default (most frequent first). SetChunkSelector swaps in
+another strategy, e.g. RoundRobin to spread a partial load across the frequency range.
+
+	loader.SetChunkSelector(dictionary.RoundRobin)
+
+# Building
+
+BuildChunks writes the chunk files from a frequency sorted words.txt, one "word<TAB>frequency" line per word.
+The loader runs it on its own when chunks are missing.
+
+	err := dictionary.BuildChunks("data/words.txt", "data/", 10000, 0)
+
+ExportChunks goes the other way, writing the loaded words, runtime ones included, to a new data dir.
+
+	err := loader.ExportChunks("export/", 10000)
+
 # Runtime
 
 RuntimeLoader gives control over loaded dictionary size during execution.
@@ -40,12 +61,21 @@ Works with the base Loader to add or remove chunks based on target word counts o
 	runtimeLoader := dictionary.NewRuntimeLoader(loader)
 	err := runtimeLoader.SetDictionarySize(3)
 	options, err := runtimeLoader.GetDictionarySizeOptions()
+
+# User words
+
+Words added at runtime with AddWord sit on top of the chunks and survive loads and evictions.
+SaveUserWords and LoadUserWords keep them in a small TOML file between sessions.
+
+	loader.AddWord("kubernetes", 50000)
+	err := loader.SaveUserWords(filepath.Join(configDir, "user_words.toml"))
 */
 package dictionary
 
 import (
 	"archive/zip"
 	"bufio"
+	"context"
 	"encoding/binary"
 	"errors"
 	"fmt"
@@ -53,12 +83,13 @@ import (
 	"maps"
 	"net/http"
 	"os"
-	"os/exec"
 	"path/filepath"
+	"runtime"
+	"slices"
 	"sort"
-	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/bastiangx/wordserve/internal/utils"
@@ -67,11 +98,13 @@ import (
 	"github.com/tchap/go-patricia/v2/patricia"
 )
 
+// errNoLocalBuild means the dictionary can't be built locally at all
+// (no words.txt to build from), so downloading is the only option
+var errNoLocalBuild = errors.New("local build unavailable")
+
 const (
-	// GHReleaseURL is the placeholder URL for downloading pre-built dictionary files
+	// GHReleaseURL is the default base URL for downloading pre-built dictionary files
 	GHReleaseURL = "https://github.com/bastiangx/wordserve/releases/latest/download"
-	// MaxRetries for luajit script execution
-	MaxRetries = 3
 )
 
 // Loader manages lazy loading of dictionary chunks
@@ -82,19 +115,41 @@ const (
 type Loader struct {
 	chunkWords      map[int]map[string]int
 	loadedChunks    map[int]bool
+	partialChunks   map[int]bool // loaded chunks cut short by maxWords
 	errorCount      map[int]int
 	wordFreqs       map[string]int
+	userWords       map[string]int
+	bigrams         *BigramStore
 	availableChunks []ChunkInfo
 	chunksCached    bool
 	done            chan struct{}
-	trie            *patricia.Trie
+	stopOnce        sync.Once
+	generations     map[int]*generation
+	nextGeneration  int
+	genMu           sync.Mutex
+	trie            *layeredTrie
 	mu              sync.RWMutex
+	writeMu         sync.Mutex // serializes changes to the words; held alone, they can be read without mu
 	loadingCh       chan int
 	dirPath         string
 	maxWords        int
 	totalWords      int
 	maxFrequency    int
 	maxRetries      int
+	maxMemory       int64
+	rankConversion  RankConversion
+	progress        func(loadedChunks, totalChunks, loadedWords int)
+	queuedChunks    int                  // chunks ever queued for background loading
+	initialChunks   map[int]initialChunk // chunks queued by StartLoading that haven't finished yet
+	ready           chan struct{}
+	readyOnce       sync.Once
+	selector        ChunkSelector
+	releaseURL      string
+	checksumURL     string
+	urlMu           sync.RWMutex // guards the URLs, which are read while mu is held
+	version         atomic.Uint64
+	distribution    *FrequencyDistribution
+	distMu          sync.Mutex
 }
 
 // ChunkInfo contains metadata about a chunk file
@@ -104,6 +159,12 @@ type ChunkInfo struct {
 	WordCount int
 }
 
+// initialChunk is a chunk queued by StartLoading, with its place in the queue
+type initialChunk struct {
+	position  int
+	wordCount int
+}
+
 // LoaderStats provides statistics about the loading process
 type LoaderStats struct {
 	TotalWords      int
@@ -117,26 +178,66 @@ type LoaderStats struct {
 // NewLoader creates a new default lazy loader
 func NewLoader(dirPath string, maxWords int) *Loader {
 	return &Loader{
-		dirPath:      dirPath,
-		maxWords:     maxWords,
-		loadedChunks: make(map[int]bool),
-		chunkWords:   make(map[int]map[string]int),
-		trie:         patricia.NewTrie(),
-		wordFreqs:    make(map[string]int),
-		loadingCh:    make(chan int, 10),
-		done:         make(chan struct{}),
-		errorCount:   make(map[int]int),
-		totalWords:   0,
-		maxFrequency: 0,
-		maxRetries:   3,
-	}
-}
-
-// GetAvailable scans the directory for available chunk files
+		dirPath:       dirPath,
+		maxWords:      maxWords,
+		loadedChunks:  make(map[int]bool),
+		partialChunks: make(map[int]bool),
+		chunkWords:    make(map[int]map[string]int),
+		trie:          newLayeredTrie(),
+		wordFreqs:     make(map[string]int),
+		userWords:     make(map[string]int),
+		bigrams:       NewBigramStore(),
+		loadingCh:     make(chan int, 10),
+		done:          make(chan struct{}),
+		ready:         make(chan struct{}),
+		initialChunks: make(map[int]initialChunk),
+		errorCount:    make(map[int]int),
+		generations:   make(map[int]*generation),
+		totalWords:    0,
+		maxFrequency:  0,
+		maxRetries:    3,
+		selector:      FrequencyFirst,
+		releaseURL:    GHReleaseURL,
+		checksumURL:   DefaultChecksumURL,
+	}
+}
+
+// SetReleaseURL sets the base URL words.txt and data.zip are downloaded from,
+// for mirrors of the GitHub release. An empty URL restores the default.
+// If the checksum URL still points at the old base, it follows the new one.
+func (cl *Loader) SetReleaseURL(url string) {
+	url = strings.TrimSuffix(url, "/")
+	if url == "" {
+		url = GHReleaseURL
+	}
+	cl.urlMu.Lock()
+	defer cl.urlMu.Unlock()
+	if cl.checksumURL == cl.releaseURL+"/data.zip.sha256" {
+		cl.checksumURL = url + "/data.zip.sha256"
+	}
+	cl.releaseURL = url
+}
+
+// releaseFileURL returns the download URL of a release file
+func (cl *Loader) releaseFileURL(name string) string {
+	cl.urlMu.RLock()
+	defer cl.urlMu.RUnlock()
+	return cl.releaseURL + "/" + name
+}
+
+// GetAvailable scans the directory for available chunk files.
+// The scan is done once, later calls return its result.
 func (cl *Loader) GetAvailable() ([]ChunkInfo, error) {
+	cl.mu.RLock()
+	if cl.chunksCached {
+		chunks := cl.availableChunks
+		cl.mu.RUnlock()
+		return chunks, nil
+	}
+	cl.mu.RUnlock()
+
 	cl.mu.Lock()
 	defer cl.mu.Unlock()
-
 	if cl.chunksCached {
 		return cl.availableChunks, nil
 	}
@@ -144,34 +245,31 @@ func (cl *Loader) GetAvailable() ([]ChunkInfo, error) {
 	if err := cl.checkDictFiles(); err != nil {
 		return nil, err
 	}
+	return cl.scanChunks()
+}
 
-	pattern := filepath.Join(cl.dirPath, "dict_*.bin")
-	files, err := filepath.Glob(pattern)
+// scanChunks lists the chunk files in the data dir and caches the list for
+// GetAvailable. Unlike GetAvailable it never builds or downloads chunks, so it
+// is what runtime changes use to refresh the list. Callers must hold cl.mu.
+func (cl *Loader) scanChunks() ([]ChunkInfo, error) {
+	files, err := globChunks(cl.dirPath)
 	if err != nil {
 		log.Errorf("failed to scan for chunk files: %v", err)
 		return nil, err
 	}
 
 	var chunks []ChunkInfo
-	for _, file := range files {
-		basename := filepath.Base(file)
-		// Extract ID from filename (dict_0001.bin -> 1)
-		if strings.HasPrefix(basename, "dict_") && strings.HasSuffix(basename, ".bin") {
-			idStr := strings.TrimPrefix(basename, "dict_")
-			idStr = strings.TrimSuffix(idStr, ".bin")
-			if chunkID, err := strconv.Atoi(idStr); err == nil {
-				wordCount, err := cl.getWordCount(file)
-				if err != nil {
-					log.Warnf("Failed to get word count for block %s: %v", file, err)
-					wordCount = 0
-				}
-				chunks = append(chunks, ChunkInfo{
-					ID:        chunkID,
-					Filename:  file,
-					WordCount: wordCount,
-				})
-			}
+	for chunkID, file := range files {
+		wordCount, err := cl.getWordCount(file)
+		if err != nil {
+			log.Warnf("Failed to get word count for block %s: %v", file, err)
+			wordCount = 0
 		}
+		chunks = append(chunks, ChunkInfo{
+			ID:        chunkID,
+			Filename:  file,
+			WordCount: wordCount,
+		})
 	}
 	// Sort by ID
 	sort.Slice(chunks, func(i, j int) bool {
@@ -185,14 +283,13 @@ func (cl *Loader) GetAvailable() ([]ChunkInfo, error) {
 
 // getWordCount reads the word count from file's header
 func (cl *Loader) getWordCount(filename string) (int, error) {
-	file, err := os.Open(filename)
+	file, err := openChunk(filename)
 	if err != nil {
 		return 0, err
 	}
 	defer file.Close()
 
-	var wordCount int32
-	err = binary.Read(file, binary.LittleEndian, &wordCount)
+	wordCount, _, err := readChunkHeader(file)
 	if err != nil {
 		return 0, err
 	}
@@ -210,11 +307,21 @@ func (cl *Loader) StartLoading() error {
 
 	if len(fl) == 0 {
 		log.Errorf("no files found in %s", cl.dirPath)
-		return err
+		return fmt.Errorf("no dictionary chunks found in %s", cl.dirPath)
 	}
 	log.Debugf("Found %d files", len(fl))
 
-	go cl.backgroundLoader()
+	if bigrams, err := LoadBigrams(cl.dirPath); err != nil {
+		log.Warnf("Continuing without next-word prediction: %v", err)
+	} else {
+		cl.mu.Lock()
+		cl.bigrams = bigrams
+		cl.mu.Unlock()
+	}
+
+	for range loaderCount() {
+		go cl.backgroundLoader()
+	}
 
 	// calc how many words to load based on maxWords limit
 	wordsToLoad := cl.maxWords
@@ -223,29 +330,122 @@ func (cl *Loader) StartLoading() error {
 			wordsToLoad += chunk.WordCount
 		}
 	}
-	// Queue initial chunk to load
+	// Pick the initial chunks to load
+	var initial []ChunkInfo
 	loadedWords := 0
-	for _, chunk := range fl {
+	for _, chunk := range cl.orderChunks(fl) {
 		if loadedWords >= wordsToLoad {
 			break
 		}
+		initial = append(initial, chunk)
+		loadedWords += chunk.WordCount
+	}
+	// All counted before sending any, a loader may finish the first chunk
+	// before the next is sent and must not find no initial chunks left
+	cl.mu.Lock()
+	for position, chunk := range initial {
+		cl.initialChunks[chunk.ID] = initialChunk{position: position, wordCount: chunk.WordCount}
+	}
+	cl.queuedChunks += len(initial)
+	cl.mu.Unlock()
+	for _, chunk := range initial {
 		select {
 		case cl.loadingCh <- chunk.ID:
 			log.Debugf("Queued  %d for loading", chunk.ID)
 		case <-time.After(100 * time.Millisecond):
 			log.Warnf("Loading queue full")
+			cl.mu.Lock()
+			cl.queuedChunks--
+			cl.mu.Unlock()
+			cl.finishInitial(chunk.ID)
 		}
-		loadedWords += chunk.WordCount
 	}
+	cl.checkReady()
 	return nil
 }
 
-// backgroundLoader runs in a goroutine and loads blocks from the queue
+// SetRankConversion sets how stored chunk values become scores.
+// It applies to chunks loaded afterwards, so set it before StartLoading.
+func (cl *Loader) SetRankConversion(conversion RankConversion) {
+	cl.mu.Lock()
+	defer cl.mu.Unlock()
+	cl.rankConversion = conversion
+}
+
+// loaderCount returns the number of background loader goroutines to start, one
+// for every two CPUs Go may use. Chunks are read and their tries built in parallel,
+// so loading scales with the CPUs; with fewer than four there is one loader.
+func loaderCount() int {
+	return max(runtime.GOMAXPROCS(0)/2, 1)
+}
+
+// SetProgressCallback sets a function called after each chunk the background loader
+// loads, with the loaded and queued chunk counts and the words now loaded.
+// Set it before StartLoading; it runs on the loader goroutine and should return quickly.
+func (cl *Loader) SetProgressCallback(progress func(loadedChunks, totalChunks, loadedWords int)) {
+	cl.mu.Lock()
+	defer cl.mu.Unlock()
+	cl.progress = progress
+}
+
+// WaitUntilReady blocks until every chunk queued by StartLoading has loaded or
+// given up after its retries, or ctx is done. It returns ctx's error on timeout,
+// and an error if the loader is stopped first.
+func (cl *Loader) WaitUntilReady(ctx context.Context) error {
+	select {
+	case <-cl.ready:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-cl.done:
+		return errors.New("loader stopped before the dictionary was ready")
+	}
+}
+
+// finishInitial records that a chunk queued by StartLoading is done with, loaded or not
+func (cl *Loader) finishInitial(chunkID int) {
+	cl.mu.Lock()
+	delete(cl.initialChunks, chunkID)
+	cl.mu.Unlock()
+	cl.checkReady()
+}
+
+// checkReady releases WaitUntilReady once no initial chunks are left
+func (cl *Loader) checkReady() {
+	cl.mu.RLock()
+	remaining := len(cl.initialChunks)
+	cl.mu.RUnlock()
+	if remaining == 0 {
+		cl.readyOnce.Do(func() { close(cl.ready) })
+	}
+}
+
+// reportProgress calls the progress callback, if any, with the current counts
+func (cl *Loader) reportProgress() {
+	cl.mu.RLock()
+	progress := cl.progress
+	loadedChunks := len(cl.loadedChunks)
+	totalChunks := max(cl.queuedChunks, loadedChunks)
+	loadedWords := cl.totalWords
+	cl.mu.RUnlock()
+	if progress != nil {
+		progress(loadedChunks, totalChunks, loadedWords)
+	}
+}
+
+// backgroundLoader runs in a goroutine and loads blocks from the queue.
+// Several may run at once, each taking the next queued chunk.
 func (cl *Loader) backgroundLoader() {
 	for {
 		select {
 		case chunkID := <-cl.loadingCh:
 			if err := cl.Load(chunkID); err != nil {
+				// Retrying can't free memory, the chunk stays unloaded
+				if errors.Is(err, ErrMemoryLimit) {
+					log.Warnf("Skipping chunk %d: %v", chunkID, err)
+					cl.finishInitial(chunkID)
+					continue
+				}
 				log.Errorf("Failed to load chunk %d: %v", chunkID, err)
 				cl.mu.Lock()
 				cl.errorCount[chunkID]++
@@ -263,9 +463,12 @@ func (cl *Loader) backgroundLoader() {
 					}(chunkID)
 				} else {
 					log.Errorf("Loading %d failed %d times, aborting.", chunkID, cl.maxRetries)
+					cl.finishInitial(chunkID)
 				}
 			} else {
 				log.Debugf("Loaded dict file %d", chunkID)
+				cl.reportProgress()
+				cl.finishInitial(chunkID)
 			}
 		case <-cl.done:
 			return
@@ -273,131 +476,377 @@ func (cl *Loader) backgroundLoader() {
 	}
 }
 
-// Load loads a specific chunk into memory
+// Load loads a specific chunk into memory.
+// The file is read and parsed, and the chunk's own trie built, without holding
+// any lock, so completions keep running and other chunks keep loading until
+// the trie is published next to the ones already loaded.
+//
+// Once maxWords words are loaded the rest of the chunk is left out, keeping its
+// most frequent words. Loading the chunk again after the limit is raised adds the rest.
 func (cl *Loader) Load(chunkID int) error {
+	cl.mu.RLock()
+	loaded := cl.loadedChunks[chunkID]
+	partial := cl.partialChunks[chunkID]
+	conversion := cl.rankConversion
+	cl.mu.RUnlock()
+	if loaded && !partial {
+		return nil
+	}
+
+	entries, err := readChunk(cl.chunkFilename(chunkID), conversion, func(totalEntries int) error {
+		cl.mu.RLock()
+		defer cl.mu.RUnlock()
+		return cl.checkMemory(chunkID, totalEntries)
+	})
+	if err != nil {
+		return err
+	}
+	// Built for the usual case of a chunk loaded whole, with no word found in
+	// another chunk; otherwise it is rebuilt below with the words that made it
+	var layer *chunkLayer
+	if !loaded {
+		words := make(map[string]int, len(entries))
+		for _, entry := range entries {
+			if _, exists := words[entry.word]; !exists {
+				words[entry.word] = entry.score
+			}
+		}
+		layer = newChunkLayer(chunkID, words)
+	}
+
+	cl.writeMu.Lock()
+	defer cl.writeMu.Unlock()
+	// Another loader may have finished the same chunk while this one was reading
+	if cl.loadedChunks[chunkID] && !cl.partialChunks[chunkID] {
+		return nil
+	}
+	cl.mu.RLock()
+	err = cl.checkMemory(chunkID, len(entries))
+	maxWords := cl.maxWords
+	limit := maxWords
+	if maxWords > 0 {
+		limit = max(maxWords-cl.reservedWords(chunkID), 0)
+	}
+	cl.mu.RUnlock()
+	if err != nil {
+		return err
+	}
+	totalWords := cl.totalWords
+	if maxWords > 0 && totalWords+len(entries) > limit {
+		// Only part of the chunk fits, make sure it is the most frequent part
+		sort.SliceStable(entries, func(i, j int) bool {
+			return entries[i].score > entries[j].score
+		})
+	}
+	// A partly loaded chunk keeps the words it has and gets the rest added
+	chunkWords := maps.Clone(cl.chunkWords[chunkID])
+	if chunkWords == nil {
+		chunkWords = make(map[string]int, len(entries))
+	}
+	layerWords := make(map[string]int, len(entries))
+	var added []chunkEntry
+	complete := true
+	for _, entry := range entries {
+		if _, exists := chunkWords[entry.word]; exists {
+			continue
+		}
+		if maxWords > 0 && totalWords >= limit {
+			complete = false
+			break
+		}
+		chunkWords[entry.word] = entry.score
+		// Words added at runtime keep their own frequency, the layer's is hidden
+		if _, isUserWord := cl.userWords[entry.word]; isUserWord {
+			layerWords[entry.word] = entry.score
+			continue
+		}
+		// A word already loaded from another chunk stays in that chunk's layer
+		if _, exists := cl.wordFreqs[entry.word]; exists {
+			continue
+		}
+		layerWords[entry.word] = entry.score
+		added = append(added, entry)
+		totalWords++
+	}
+	previous := cl.trie.layer(chunkID)
+	if layer == nil || previous != nil || !complete || len(layerWords) != len(chunkWords) {
+		if previous != nil {
+			cl.trie.addVisibleWords(previous, layerWords)
+		}
+		layer = newChunkLayer(chunkID, layerWords)
+	}
+	layer.version = cl.version.Load() + 1
+
 	cl.mu.Lock()
 	defer cl.mu.Unlock()
-	if cl.loadedChunks[chunkID] {
-		return nil
+	for _, entry := range added {
+		cl.wordFreqs[entry.word] = entry.score
+		cl.maxFrequency = max(cl.maxFrequency, entry.score)
+	}
+	cl.totalWords = totalWords
+	cl.trie = cl.trie.withLayer(layer)
+	cl.chunkWords[chunkID] = chunkWords
+	cl.loadedChunks[chunkID] = true
+	if complete {
+		delete(cl.partialChunks, chunkID)
+		log.Debugf("dict file %d loaded: %d words", chunkID, len(chunkWords))
+	} else {
+		cl.partialChunks[chunkID] = true
+		log.Debugf("dict file %d loaded: %d of %d words, reached the %d word limit", chunkID, len(chunkWords), len(entries), maxWords)
 	}
+	cl.version.Add(1)
+	return nil
+}
+
+// reservedWords returns the words kept free for the chunks StartLoading queued
+// ahead of chunkID that haven't loaded yet, so a chunk another loader finishes
+// first doesn't take their place under maxWords. Call it with mu held.
+func (cl *Loader) reservedWords(chunkID int) int {
+	own, ok := cl.initialChunks[chunkID]
+	if !ok {
+		return 0
+	}
+	reserved := 0
+	for id, chunk := range cl.initialChunks {
+		if chunk.position < own.position && !cl.loadedChunks[id] {
+			reserved += chunk.wordCount
+		}
+	}
+	return reserved
+}
+
+// isLoaded reports whether a chunk is loaded, fully or in part
+func (cl *Loader) isLoaded(chunkID int) bool {
+	cl.mu.RLock()
+	defer cl.mu.RUnlock()
+	return cl.loadedChunks[chunkID]
+}
 
-	filename := filepath.Join(cl.dirPath, fmt.Sprintf("dict_%04d.bin", chunkID))
+// isPartial reports whether a loaded chunk was cut short by the word limit
+func (cl *Loader) isPartial(chunkID int) bool {
+	cl.mu.RLock()
+	defer cl.mu.RUnlock()
+	return cl.partialChunks[chunkID]
+}
 
-	file, err := os.Open(filename)
+// SetMaxWords changes the most words Load keeps, 0 means no limit.
+// Lowering it doesn't unload anything, it only stops further words from loading.
+func (cl *Loader) SetMaxWords(maxWords int) {
+	cl.mu.Lock()
+	defer cl.mu.Unlock()
+	cl.maxWords = maxWords
+}
+
+// raiseMaxWords grows a word limit that is set, so at least words words fit.
+// An unlimited loader stays unlimited.
+func (cl *Loader) raiseMaxWords(words int) {
+	cl.mu.Lock()
+	defer cl.mu.Unlock()
+	if cl.maxWords > 0 && words > cl.maxWords {
+		log.Debugf("Raising the word limit from %d to %d", cl.maxWords, words)
+		cl.maxWords = words
+	}
+}
+
+// chunkEntry is a word read from a chunk file with its score
+type chunkEntry struct {
+	word  string
+	score int
+}
+
+// readChunk parses a chunk file, converting each stored value to a score.
+// checkHeader is given the word count from the header before the words are read,
+// and stops the read if it returns an error.
+func readChunk(filename string, conversion RankConversion, checkHeader func(totalEntries int) error) ([]chunkEntry, error) {
+	file, err := openChunk(filename)
 	if err != nil {
 		log.Errorf("failed to open chunk file %s: %v", filename, err)
-		return err
+		return nil, err
 	}
 	defer file.Close()
 	reader := bufio.NewReader(file)
 
-	// word count header
-	var totalEntries int32
-	if err := binary.Read(reader, binary.LittleEndian, &totalEntries); err != nil {
+	// word count header, after the magic and version in current files
+	totalEntries, _, err := readChunkHeader(reader)
+	if err != nil {
 		log.Errorf("failed to read chunk header: %v", err)
-		return err
+		return nil, err
+	}
+	if err := checkHeader(int(totalEntries)); err != nil {
+		return nil, err
 	}
-	count := 0
-	for count < int(totalEntries) {
+	// The header isn't trusted for the allocation, a corrupt one could ask for gigabytes
+	entries := make([]chunkEntry, 0, min(max(int(totalEntries), 0), 1<<16))
+	for len(entries) < int(totalEntries) {
 		var wordLen uint16
 		if err := binary.Read(reader, binary.LittleEndian, &wordLen); err != nil {
 			if err == io.EOF {
 				break
 			}
 			log.Errorf("failed to read word length: %v", err)
-			return err
+			return nil, err
 		}
 		wordBytes := make([]byte, wordLen)
 		if _, err := io.ReadFull(reader, wordBytes); err != nil {
 			log.Errorf("failed to read word: %v", err)
-			return err
+			return nil, err
 		}
-		word := string(wordBytes)
 		var rank uint16
 		if err := binary.Read(reader, binary.LittleEndian, &rank); err != nil {
 			log.Errorf("failed to read rank: %v", err)
-			return err
-		}
-
-		// Convert rank to inverse score for sorting (rank 1 = highest score)
-		// Use (max_uint16 + 1) - rank so rank 1 becomes 65535, rank 2 becomes 65534, etc.
-		score := int(65535 - rank + 1)
-		cl.trie.Insert(patricia.Prefix(word), score)
-		cl.wordFreqs[word] = score
-
-		if cl.chunkWords[chunkID] == nil {
-			cl.chunkWords[chunkID] = make(map[string]int)
-		}
-		cl.chunkWords[chunkID][word] = score
-		cl.totalWords++
-		if score > cl.maxFrequency {
-			cl.maxFrequency = score
+			return nil, err
 		}
-		count++
+		// By default the value is a rank, inverted so rank 1 becomes 65535, rank 2 becomes 65534, etc.
+		entries = append(entries, chunkEntry{word: string(wordBytes), score: conversion.Score(rank)})
 	}
-	cl.loadedChunks[chunkID] = true
-	log.Debugf("dict file %d loaded: %d words", chunkID, count)
-	return nil
+	return entries, nil
 }
 
 // Evict removes a specific chunk from memory
 func (cl *Loader) Evict(chunkID int) error {
-	cl.mu.Lock()
-	defer cl.mu.Unlock()
+	cl.writeMu.Lock()
+	defer cl.writeMu.Unlock()
 	if !cl.loadedChunks[chunkID] {
 		log.Errorf("%d is not loaded", chunkID)
 		return errors.New("file not loaded")
 	}
 	log.Debugf("Unloading %d", chunkID)
-	delete(cl.loadedChunks, chunkID)
 	chunkWords, exists := cl.chunkWords[chunkID]
-
 	if !exists {
+		cl.mu.Lock()
+		delete(cl.loadedChunks, chunkID)
+		delete(cl.partialChunks, chunkID)
+		cl.mu.Unlock()
 		log.Errorf("%d word data not found", chunkID)
 		return errors.New("file's word data not found")
 	}
 
+	// Words the chunk shares with other loaded chunks were left out of their
+	// layers when they loaded, and move to the first of them
+	trie := cl.trie
+	evicted := trie.layer(chunkID)
+	freqs := make(map[string]int)
+	moved := make(map[int]map[string]int)
 	for word := range chunkWords {
-		delete(cl.wordFreqs, word)
-		cl.totalWords--
+		if _, isUserWord := cl.userWords[word]; isUserWord {
+			continue
+		}
+		if evicted == nil || evicted.trie.Get(patricia.Prefix(word)) == nil {
+			continue
+		}
+		owner := 0
+		for otherID, otherWords := range cl.chunkWords {
+			if _, shared := otherWords[word]; shared && otherID != chunkID && (owner == 0 || otherID < owner) {
+				owner = otherID
+			}
+		}
+		if owner == 0 {
+			freqs[word] = -1
+			continue
+		}
+		if moved[owner] == nil {
+			moved[owner] = make(map[string]int)
+		}
+		moved[owner][word] = cl.chunkWords[owner][word]
+		freqs[word] = cl.chunkWords[owner][word]
+	}
+	trie = trie.withoutLayer(chunkID)
+	for owner, words := range moved {
+		if layer := trie.layer(owner); layer != nil {
+			trie.addVisibleWords(layer, words)
+		}
+		layer := newChunkLayer(owner, words)
+		layer.version = cl.version.Load() + 1
+		trie = trie.withLayer(layer)
+	}
+
+	cl.mu.Lock()
+	defer cl.mu.Unlock()
+	delete(cl.loadedChunks, chunkID)
+	delete(cl.partialChunks, chunkID)
+	for word, freq := range freqs {
+		if freq < 0 {
+			delete(cl.wordFreqs, word)
+			cl.totalWords--
+		} else {
+			cl.wordFreqs[word] = freq
+		}
 	}
 	delete(cl.chunkWords, chunkID)
-	cl.rebuildTrie()
+	cl.trie = trie
+	cl.maxFrequency = trie.maxScore(cl.userWords)
+	cl.version.Add(1)
 	log.Debugf("Successfully unloaded %d", chunkID)
 	return nil
 }
 
-// rebuildTrie reconstructs the trie from currently loaded chunks
-func (cl *Loader) rebuildTrie() {
-	cl.trie = patricia.NewTrie()
-	cl.maxFrequency = 0
+// copyTrie returns a new trie holding the words of trie, for changes that must
+// not touch a published trie. patricia's Trie.Clone loses words once a clone
+// is cloned again, so the words are inserted one by one. Visit reuses the
+// prefix buffer, which Insert would keep, so each key is copied.
+func copyTrie(trie *patricia.Trie) *patricia.Trie {
+	clone := patricia.NewTrie()
+	trie.Visit(func(prefix patricia.Prefix, item patricia.Item) error {
+		clone.Insert(slices.Clone(prefix), item)
+		return nil
+	})
+	return clone
+}
+
+// sortTrie readies a trie for concurrent walks before it is published.
+// patricia sorts a node's children in place the first time they are walked
+// after a change, so walking it once here leaves later walks only reading.
+func sortTrie(trie *patricia.Trie) {
+	trie.Visit(func(patricia.Prefix, patricia.Item) error { return nil })
+}
 
-	for chunkID, loaded := range cl.loadedChunks {
-		if !loaded {
-			continue
-		}
-		chunkWords, exists := cl.chunkWords[chunkID]
-		if !exists {
+// WordChunk returns the ID of the loaded chunk a word was read from.
+// Words added at runtime, or not in any loaded chunk, report false; a missing
+// common word with no chunk usually means its chunk isn't loaded yet.
+func (cl *Loader) WordChunk(word string) (int, bool) {
+	cl.mu.RLock()
+	defer cl.mu.RUnlock()
+	if _, isUserWord := cl.userWords[word]; isUserWord {
+		return 0, false
+	}
+	origin := 0
+	for chunkID, chunkWords := range cl.chunkWords {
+		if !cl.loadedChunks[chunkID] {
 			continue
 		}
-		for word, freq := range chunkWords {
-			cl.trie.Insert(patricia.Prefix(word), freq)
-			if freq > cl.maxFrequency {
-				cl.maxFrequency = freq
-			}
+		if _, exists := chunkWords[word]; exists && (origin == 0 || chunkID < origin) {
+			origin = chunkID
 		}
 	}
+	return origin, origin != 0
+}
 
-	log.Debugf("Trie rebuilt with %d loaded chunks", len(cl.loadedChunks))
+// Version returns a counter that increases every time the loaded word set changes.
+// Anything derived from the dictionary (cached results, indexes) is stale once it moves.
+func (cl *Loader) Version() uint64 {
+	return cl.version.Load()
 }
 
-// GetTrie returns the loaded trie
-func (cl *Loader) GetTrie() *patricia.Trie {
+// GetTrie returns the loaded trie.
+//
+// A returned trie is never changed: loads, evictions and runtime words publish
+// a new one in its place, which shares the tries of the chunks they didn't touch.
+// Callers can walk it without holding any lock, and see the dictionary as it
+// was when they got it. Its walks give the runtime words first, then the words
+// of each chunk in ID order, each in prefix order.
+func (cl *Loader) GetTrie() Trie {
 	cl.mu.RLock()
 	defer cl.mu.RUnlock()
 	return cl.trie
 }
 
+// Bigrams returns the next-word store, empty when the data dir has no bigram files
+func (cl *Loader) Bigrams() *BigramStore {
+	cl.mu.RLock()
+	defer cl.mu.RUnlock()
+	return cl.bigrams
+}
+
 // GetWordFreqs returns the word frequency map
 func (cl *Loader) GetWordFreqs() map[string]int {
 	cl.mu.RLock()
@@ -435,27 +884,91 @@ func (cl *Loader) GetStats() LoaderStats {
 	return stats
 }
 
+// ChunkStat describes one chunk, for seeing where the loaded words come from
+type ChunkStat struct {
+	ID        int
+	FileWords int  // words in the chunk file, from its header
+	WordCount int  // words loaded from the chunk, 0 while unloaded
+	Loaded    bool // loaded fully or in part
+	Partial   bool // loaded only up to the word limit
+	MinScore  int  // lowest score among the loaded words
+	MaxScore  int  // highest score among the loaded words
+}
+
+// GetChunkStats returns a stat for every chunk on disk or in memory, sorted by ID.
+// A word found in several chunks counts toward each of them, so the counts can add
+// up to more than the loaded total.
+func (cl *Loader) GetChunkStats() []ChunkStat {
+	available, _ := cl.GetAvailable()
+
+	cl.mu.RLock()
+	defer cl.mu.RUnlock()
+	stats := make(map[int]*ChunkStat)
+	for _, chunk := range available {
+		stats[chunk.ID] = &ChunkStat{ID: chunk.ID, FileWords: chunk.WordCount}
+	}
+	for chunkID, loaded := range cl.loadedChunks {
+		if !loaded {
+			continue
+		}
+		stat, exists := stats[chunkID]
+		if !exists {
+			stat = &ChunkStat{ID: chunkID}
+			stats[chunkID] = stat
+		}
+		stat.Loaded = true
+		stat.Partial = cl.partialChunks[chunkID]
+		stat.WordCount = len(cl.chunkWords[chunkID])
+		first := true
+		for _, score := range cl.chunkWords[chunkID] {
+			if first || score < stat.MinScore {
+				stat.MinScore = score
+			}
+			if first || score > stat.MaxScore {
+				stat.MaxScore = score
+			}
+			first = false
+		}
+	}
+
+	result := make([]ChunkStat, 0, len(stats))
+	for _, stat := range stats {
+		result = append(result, *stat)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].ID < result[j].ID
+	})
+	return result
+}
+
 // Stop kills the background loading process
 func (cl *Loader) Stop() {
-	close(cl.done)
+	cl.stopOnce.Do(func() { close(cl.done) })
 }
 
-// RequestMore queues additional files for loading
+// RequestMore queues additional files for loading, raising the word limit to make room for them
 func (cl *Loader) RequestMore(additionalWords int) error {
 	chunks, err := cl.GetAvailable()
 	if err != nil {
 		return err
 	}
+	cl.mu.RLock()
+	loadedWords := cl.totalWords
+	cl.mu.RUnlock()
+	cl.raiseMaxWords(loadedWords + additionalWords)
 	wordsToLoad := 0
-	for _, chunk := range chunks {
+	for _, chunk := range cl.orderChunks(chunks) {
 		cl.mu.RLock()
-		alreadyLoaded := cl.loadedChunks[chunk.ID]
+		alreadyLoaded := cl.loadedChunks[chunk.ID] && !cl.partialChunks[chunk.ID]
 		cl.mu.RUnlock()
 
 		if !alreadyLoaded {
 			select {
 			case cl.loadingCh <- chunk.ID:
 				log.Debugf("Queued additional %d for loading", chunk.ID)
+				cl.mu.Lock()
+				cl.queuedChunks++
+				cl.mu.Unlock()
 				wordsToLoad += chunk.WordCount
 				if wordsToLoad >= additionalWords {
 					break
@@ -468,6 +981,26 @@ func (cl *Loader) RequestMore(additionalWords int) error {
 	return nil
 }
 
+// HasUnloaded reports whether any available chunk is not loaded or was cut short by the word limit,
+// so loading more could add words. It doesn't know which words those chunks hold.
+// It only checks the chunks already found by GetAvailable, without scanning the
+// data dir, so it is cheap enough to call on every completion.
+func (cl *Loader) HasUnloaded() bool {
+	cl.mu.RLock()
+	defer cl.mu.RUnlock()
+	for _, chunk := range cl.availableChunks {
+		if !cl.loadedChunks[chunk.ID] || cl.partialChunks[chunk.ID] {
+			return true
+		}
+	}
+	return false
+}
+
+// DirPath returns the data dir the chunks are read from
+func (cl *Loader) DirPath() string {
+	return cl.dirPath
+}
+
 // GetLoadedIDs returns a slice of currently loaded chunk IDs
 func (cl *Loader) GetLoadedIDs() []int {
 	cl.mu.RLock()
@@ -493,7 +1026,7 @@ func (cl *Loader) checkDictFiles() error {
 	}
 	log.Info("not enough dictionary files found, attempting to generate them...")
 	if err := cl.buildLocalDict(); err != nil {
-		log.Warnf("Local generation failed: %v", err)
+		logLocalBuildError(err)
 		if err := cl.dlReleaseDict(); err != nil {
 			log.Errorf("Remote download failed: %v", err)
 			cl.logInitError()
@@ -508,8 +1041,8 @@ func (cl *Loader) checkWordFile() error {
 	wordsPath := filepath.Join(cl.dirPath, "words.txt")
 	if _, err := os.Stat(wordsPath); os.IsNotExist(err) {
 		log.Info("words.txt not found, attempting to download...")
-		url := GHReleaseURL + "/words.txt"
-		if err := cl.dlFile(url, wordsPath); err != nil {
+		url := cl.releaseFileURL("words.txt")
+		if err := cl.dlFile(context.Background(), url, wordsPath); err != nil {
 			log.Errorf("Failed to download words.txt: %v", err)
 			return fmt.Errorf("failed to download words.txt: %w", err)
 		}
@@ -518,32 +1051,6 @@ func (cl *Loader) checkWordFile() error {
 	return nil
 }
 
-// findScriptPath attempts to locate the build-data.lua
-func (cl *Loader) findScriptPath() (string, error) {
-	possiblePaths := []string{
-		filepath.Join("scripts", "build-data.lua"),
-		filepath.Join("..", "scripts", "build-data.lua"),
-	}
-	if execDir, err := utils.GetExecutableDir(); err == nil {
-		projectRoot := filepath.Dir(execDir)
-		if filepath.Base(execDir) == "cmd" || filepath.Base(execDir) == "bin" {
-			projectRoot = filepath.Dir(execDir)
-		}
-		possiblePaths = append(possiblePaths, []string{
-			filepath.Join(execDir, "scripts", "build-data.lua"),
-			filepath.Join(projectRoot, "scripts", "build-data.lua"),
-			filepath.Join(filepath.Dir(projectRoot), "scripts", "build-data.lua"),
-		}...)
-	}
-	for _, path := range possiblePaths {
-		if _, err := os.Stat(path); err == nil {
-			log.Debugf("Found script at: %s", path)
-			return path, nil
-		}
-	}
-	return "", fmt.Errorf("build-data.lua not found in any of the expected locations: %v", possiblePaths)
-}
-
 // checkDictNum checks if the needed number of .bin files exist
 // If requiredChunks is 0, uses config
 func (cl *Loader) checkDictNum(requiredChunks ...int) bool {
@@ -559,8 +1066,7 @@ func (cl *Loader) checkDictNum(requiredChunks ...int) bool {
 		neededChunks = cl.computeChunkAmount(cfg)
 	}
 
-	pattern := filepath.Join(cl.dirPath, "dict_*.bin")
-	existingFiles, err := filepath.Glob(pattern)
+	existingFiles, err := globChunks(cl.dirPath)
 	if err != nil {
 		log.Errorf("Failed to check existing files: %v", err)
 		return false
@@ -577,15 +1083,16 @@ func (cl *Loader) computeChunkAmount(cfg *config.Config) int {
 	return (cfg.Dict.MaxWords + cfg.Dict.ChunkSize - 1) / cfg.Dict.ChunkSize
 }
 
-// buildLocalDict attempts to run the luajit script to generate dictionary files
+// buildLocalDict builds the dictionary files from words.txt
 func (cl *Loader) buildLocalDict() error {
-	return cl.buildLocalDictWithConfig(nil)
+	return cl.buildLocalDictWithConfig(context.Background(), nil)
 }
 
-// buildLocalDictWithConfig attempts to run the luajit script with specific config
-func (cl *Loader) buildLocalDictWithConfig(cfg *config.Config) error {
-	if _, err := exec.LookPath("luajit"); err != nil {
-		return errors.New("luajit not found in PATH")
+// buildLocalDictWithConfig builds enough chunks from words.txt for the configured word count
+func (cl *Loader) buildLocalDictWithConfig(ctx context.Context, cfg *config.Config) error {
+	wordsPath := filepath.Join(cl.dirPath, "words.txt")
+	if !utils.FileExists(wordsPath) {
+		return fmt.Errorf("%w: %s not found", errNoLocalBuild, wordsPath)
 	}
 	if cfg == nil {
 		var err error
@@ -595,54 +1102,38 @@ func (cl *Loader) buildLocalDictWithConfig(cfg *config.Config) error {
 			cfg = config.DefaultConfig()
 		}
 	}
-	scriptPath, err := cl.findScriptPath()
-	if err != nil {
-		return fmt.Errorf("luajit script not found: %w", err)
-	}
 	maxChunks := cl.computeChunkAmount(cfg)
-	args := []string{
-		scriptPath,
-		"--chunk-size", fmt.Sprintf("%d", cfg.Dict.ChunkSize),
-		"--max-chunks", fmt.Sprintf("%d", maxChunks),
-	}
-	for attempt := 1; attempt <= MaxRetries; attempt++ {
-		log.Infof("Running luajit script (attempt %d/%d)...", attempt, MaxRetries)
-		cmd := exec.Command("luajit", args...)
-		cmd.Stdout = os.Stdout
-		cmd.Stderr = os.Stderr
-
-		if err := cmd.Run(); err != nil {
-			log.Errorf("Luajit script failed (attempt %d): %v", attempt, err)
-			if attempt < MaxRetries {
-				time.Sleep(time.Duration(attempt) * time.Second)
-				continue
-			}
-			return fmt.Errorf("luajit script failed after %d attempts: %w", MaxRetries, err)
-		}
-		log.Info("Dictionary files generated successfully")
-		return nil
+	log.Infof("Building up to %d dictionary chunks from %s...", maxChunks, wordsPath)
+	if err := buildChunks(ctx, wordsPath, cl.dirPath, cfg.Dict.ChunkSize, maxChunks, cfg.Dict.BuildWorkers); err != nil {
+		return err
 	}
-	return errors.New("should not reach here")
+	log.Info("Dictionary files generated successfully")
+	return nil
 }
 
 // dlReleaseDict downloads dict files from GitHub release
 func (cl *Loader) dlReleaseDict() error {
-	return cl.dlReleaseDictWithConfig(nil)
+	return cl.dlReleaseDictWithConfig(context.Background(), nil)
 }
 
 // dlReleaseDictWithConfig downloads and extracts data.zip with dictionary files
-func (cl *Loader) dlReleaseDictWithConfig(cfg *config.Config) error {
+func (cl *Loader) dlReleaseDictWithConfig(ctx context.Context, cfg *config.Config) error {
 	log.Info("Attempting to download pre-built dictionary files...")
 
 	// Download data.zip (config not needed since we download the full package)
-	zipURL := fmt.Sprintf("%s/data.zip", GHReleaseURL)
+	zipURL := cl.releaseFileURL("data.zip")
 	zipPath := filepath.Join(cl.dirPath, "data.zip")
 
 	log.Infof("Downloading data.zip from %s", zipURL)
-	if err := cl.dlFile(zipURL, zipPath); err != nil {
+	if err := cl.dlFile(ctx, zipURL, zipPath); err != nil {
 		return fmt.Errorf("failed to download data.zip: %w", err)
 	}
 
+	// Verify it before extracting, a truncated archive would give a broken dictionary
+	if err := cl.verifyDownload(ctx, zipPath); err != nil {
+		return fmt.Errorf("failed to verify data.zip: %w", err)
+	}
+
 	// Extract the zip file
 	if err := cl.extractZip(zipPath, cl.dirPath); err != nil {
 		return fmt.Errorf("failed to extract data.zip: %w", err)
@@ -658,13 +1149,17 @@ func (cl *Loader) dlReleaseDictWithConfig(cfg *config.Config) error {
 }
 
 // dlFile downloads a file from a URL to a local path
-func (cl *Loader) dlFile(url, localPath string) error {
+func (cl *Loader) dlFile(ctx context.Context, url, localPath string) error {
 	dir := filepath.Dir(localPath)
 	if err := os.MkdirAll(dir, 0755); err != nil {
 		return fmt.Errorf("failed to create directory %s: %w", dir, err)
 	}
 
-	resp, err := http.Get(url)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := http.DefaultClient.Do(req)
 	if err != nil {
 		return err
 	}
@@ -698,8 +1193,8 @@ func (cl *Loader) extractZip(zipPath, destDir string) error {
 			continue
 		}
 
-		// Only extract .bin files
-		if !strings.HasSuffix(file.Name, ".bin") {
+		// Only extract .bin and .bin.gz files
+		if !strings.HasSuffix(strings.TrimSuffix(file.Name, gzipSuffix), ".bin") {
 			continue
 		}
 
@@ -734,7 +1229,8 @@ func (cl *Loader) extractZip(zipPath, destDir string) error {
 }
 
 // checkChunkCount checks if the needed number of chunks exists
-func (cl *Loader) checkChunkCount(rc int) error {
+// onStage, if set, is told when generation moves to the "build" or "download" stage
+func (cl *Loader) checkChunkCount(ctx context.Context, rc int, onStage func(stage string)) error {
 	if cl.checkDictNum(rc) {
 		return nil
 	}
@@ -747,24 +1243,44 @@ func (cl *Loader) checkChunkCount(rc int) error {
 	originalMaxWords := cfg.Dict.MaxWords
 	cfg.Dict.MaxWords = rc * cfg.Dict.ChunkSize
 
-	if err := cl.buildLocalDictWithConfig(cfg); err != nil {
-		log.Warnf("Local generation failed: %v", err)
-		if err := cl.dlReleaseDictWithConfig(cfg); err != nil {
+	if onStage == nil {
+		onStage = func(string) {}
+	}
+	onStage("build")
+	if err := cl.buildLocalDictWithConfig(ctx, cfg); err != nil {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		logLocalBuildError(err)
+		onStage("download")
+		if err := cl.dlReleaseDictWithConfig(ctx, cfg); err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			// Unlike at startup this must not exit, the job reports the failure
 			log.Errorf("Remote download failed: %v", err)
-			cl.logInitError()
-			return err
+			return fmt.Errorf("failed to build or download %d chunks: %w", rc, err)
 		}
 	}
 	cfg.Dict.MaxWords = originalMaxWords
 	cl.mu.Lock()
-	cl.chunksCached = false
-	cl.availableChunks = nil
-	cl.mu.Unlock()
+	defer cl.mu.Unlock()
+	_, err = cl.scanChunks()
+	return err
+}
 
-	return nil
+// logLocalBuildError explains why the download fallback is used.
+// A missing words.txt is expected when its download failed too, so it's not a warning.
+func logLocalBuildError(err error) {
+	if errors.Is(err, errNoLocalBuild) {
+		log.Infof("Skipping local generation (%v), downloading instead", err)
+		return
+	}
+	log.Warnf("Local generation failed, downloading instead: %v", err)
 }
 
-// logInitError logs a fatal with user guide
+// logInitError logs a fatal with user guide. It exits, so it is only for
+// startup, where WordServe can't run without a dictionary.
 func (cl *Loader) logInitError() {
 	log.Fatal(`
 Failed to initialize dictionary files!
@@ -772,11 +1288,11 @@ WordServe could not create or download the required data files.
 
 To resolve this issue, you can:
 
-1. Run the LuaJIT script manually:
-   cd scripts && luajit build-data.lua --chunk-size 10000
+1. Place a words.txt ("word<TAB>frequency" per line, most frequent first)
+   in the data directory and start WordServe again to build the chunks from it.
 
 2. Download pre-built files from:
-   ` + GHReleaseURL + `
+   ` + cl.releaseFileURL("data.zip") + `
 
 then place the downloaded .bin files in the 'data' directory.`)
 }
@@ -0,0 +1,377 @@
+package dictionary
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/tchap/go-patricia/v2/patricia"
+)
+
+// testWords returns n distinct words, most frequent first
+func testWords(n int) []string {
+	words := make([]string, n)
+	for i := range words {
+		words[i] = fmt.Sprintf("word%05d", i)
+	}
+	return words
+}
+
+// buildTestChunks writes words.txt for words, most frequent first, and builds
+// chunks of chunkSize words from it in a temp dir, which it returns
+func buildTestChunks(t testing.TB, words []string, chunkSize int) string {
+	t.Helper()
+	dir := t.TempDir()
+	var lines strings.Builder
+	for i, word := range words {
+		fmt.Fprintf(&lines, "%s\t%d\n", word, len(words)-i)
+	}
+	wordsPath := filepath.Join(dir, "words.txt")
+	if err := os.WriteFile(wordsPath, []byte(lines.String()), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := BuildChunks(wordsPath, dir, chunkSize, 0); err != nil {
+		t.Fatal(err)
+	}
+	return dir
+}
+
+// countPrefix counts the words under prefix in trie
+func countPrefix(trie Trie, prefix string) int {
+	count := 0
+	trie.VisitSubtree(patricia.Prefix(prefix), func(patricia.Prefix, patricia.Item) error {
+		count++
+		return nil
+	})
+	return count
+}
+
+func TestAddWordWhileWalking(t *testing.T) {
+	loader := NewLoader(buildTestChunks(t, testWords(500), 500), 0)
+	if err := loader.Load(1); err != nil {
+		t.Fatal(err)
+	}
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for i := range 200 {
+			if err := loader.AddWord(fmt.Sprintf("user%03d", i), 1000+i); err != nil {
+				t.Error(err)
+				return
+			}
+			if i%4 == 0 {
+				loader.RemoveWord(fmt.Sprintf("word%05d", i))
+			}
+		}
+	}()
+	for range 4 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for range 200 {
+				trie := loader.GetTrie()
+				before := countPrefix(trie, "")
+				countPrefix(trie, "user")
+				// A trie once returned never changes under its reader
+				if after := countPrefix(trie, ""); after != before {
+					t.Errorf("trie changed during the walk: %d words, then %d", before, after)
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	trie := loader.GetTrie()
+	if got := countPrefix(trie, "user"); got != 200 {
+		t.Errorf("user words in the trie = %d, want 200", got)
+	}
+	if got := countPrefix(trie, "word"); got != 450 {
+		t.Errorf("chunk words in the trie = %d, want 450", got)
+	}
+}
+
+func TestAddWordUpdatesFrequency(t *testing.T) {
+	loader := NewLoader(buildTestChunks(t, testWords(10), 10), 0)
+	if err := loader.Load(1); err != nil {
+		t.Fatal(err)
+	}
+	if err := loader.AddWord("word00009", 1<<20); err != nil {
+		t.Fatal(err)
+	}
+	if item := loader.GetTrie().Get(patricia.Prefix("word00009")); item != 1<<20 {
+		t.Errorf("frequency in the trie = %v, want %d", item, 1<<20)
+	}
+}
+
+func TestLoadWhileSearching(t *testing.T) {
+	const chunks, chunkSize = 4, 20000
+	loader := NewLoader(buildTestChunks(t, testWords(chunks*chunkSize), chunkSize), 0)
+	if err := loader.Load(1); err != nil {
+		t.Fatal(err)
+	}
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for chunkID := 2; chunkID <= chunks; chunkID++ {
+			if err := loader.Load(chunkID); err != nil {
+				t.Error(err)
+			}
+		}
+		if err := loader.Evict(chunks); err != nil {
+			t.Error(err)
+		}
+	}()
+
+	// Searches only wait for a new trie to be swapped in, never for the file
+	// to be read or the trie to be built
+	const bound = 250 * time.Millisecond
+	searches, slowest := 0, time.Duration(0)
+	for running := true; running; searches++ {
+		select {
+		case <-done:
+			running = false
+		default:
+		}
+		start := time.Now()
+		countPrefix(loader.GetTrie(), "word0001")
+		slowest = max(slowest, time.Since(start))
+	}
+	if slowest > bound {
+		t.Errorf("slowest of %d searches during loading took %v, want under %v", searches, slowest, bound)
+	}
+	if got, want := countPrefix(loader.GetTrie(), ""), (chunks-1)*chunkSize; got != want {
+		t.Errorf("words in the trie = %d, want %d", got, want)
+	}
+}
+
+func TestChunksSharingWords(t *testing.T) {
+	dir := t.TempDir()
+	chunks := map[string][]rankedWord{
+		"dict_0001.bin": {{"apple", 1}, {"banana", 2}},
+		"dict_0002.bin": {{"banana", 5}, {"cherry", 3}},
+	}
+	for name, words := range chunks {
+		if err := os.WriteFile(filepath.Join(dir, name), versionedChunk(chunkFormatVersion, words), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	loader := NewLoader(dir, 0)
+	load := func(chunkIDs ...int) {
+		t.Helper()
+		for _, chunkID := range chunkIDs {
+			if err := loader.Load(chunkID); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	evict := func(chunkID int) {
+		t.Helper()
+		if err := loader.Evict(chunkID); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	load(1, 2)
+	if got := countPrefix(loader.GetTrie(), ""); got != 3 {
+		t.Errorf("words after loading both chunks = %d, want 3, banana once", got)
+	}
+	if got := loader.GetTrie().Get(patricia.Prefix("banana")); got != RankInverse.Score(2) {
+		t.Errorf("banana = %v, want the first loaded chunk's %d", got, RankInverse.Score(2))
+	}
+	// The shared word stays loaded from the other chunk
+	evict(1)
+	if got := loader.GetTrie().Get(patricia.Prefix("banana")); got != RankInverse.Score(5) {
+		t.Errorf("banana after evicting chunk 1 = %v, want chunk 2's %d", got, RankInverse.Score(5))
+	}
+	if got := len(loader.GetWordFreqs()); got != 2 {
+		t.Errorf("word frequencies after evicting chunk 1 = %d, want 2", got)
+	}
+	// A removed word stays out until its chunk is loaded again
+	loader.RemoveWord("cherry")
+	load(1)
+	if trie := loader.GetTrie(); hasWord(trie, "cherry") || countPrefix(trie, "") != 2 {
+		t.Errorf("words after removing cherry and reloading chunk 1 = %d, want apple and banana", countPrefix(trie, ""))
+	}
+	evict(2)
+	load(2)
+	if trie := loader.GetTrie(); !hasWord(trie, "cherry") || countPrefix(trie, "") != 3 {
+		t.Errorf("words after reloading chunk 2 = %d, want 3 with cherry back", countPrefix(trie, ""))
+	}
+}
+
+// BenchmarkStartLoading measures a cold start, from StartLoading until every
+// chunk of a 100k word dictionary in 10 chunks is loaded. The loader count
+// follows GOMAXPROCS, so -cpu 2,8 compares one loader against four.
+func BenchmarkStartLoading(b *testing.B) {
+	const totalWords, chunkSize = 100000, 10000
+	dir := buildTestChunks(b, testWords(totalWords), chunkSize)
+	releaseURL := offlineRelease(b)
+	b.Setenv("XDG_CONFIG_HOME", b.TempDir())
+	b.Setenv("WORDSERVE_MAX_WORDS", strconv.Itoa(totalWords))
+	b.Setenv("WORDSERVE_CHUNK_SIZE", strconv.Itoa(chunkSize))
+
+	for b.Loop() {
+		loader := NewLoader(dir, 0)
+		loader.SetReleaseURL(releaseURL)
+		if err := loader.StartLoading(); err != nil {
+			b.Fatal(err)
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+		err := loader.WaitUntilReady(ctx)
+		cancel()
+		loader.Stop()
+		if err != nil {
+			b.Fatal(err)
+		}
+		if words := loader.GetStats().TotalWords; words != totalWords {
+			b.Fatalf("loaded %d words, want %d", words, totalWords)
+		}
+	}
+}
+
+// hasWord reports whether word is in trie
+func hasWord(trie Trie, word string) bool {
+	return trie.Get(patricia.Prefix(word)) != nil
+}
+
+func TestLoadStopsAtMaxWords(t *testing.T) {
+	dir := buildTestChunks(t, testWords(300), 100)
+	loader := NewLoader(dir, 150)
+	for chunkID := 1; chunkID <= 3; chunkID++ {
+		if err := loader.Load(chunkID); err != nil {
+			t.Fatal(err)
+		}
+	}
+	trie := loader.GetTrie()
+	if words := countPrefix(trie, ""); words != 150 {
+		t.Errorf("loaded %d words, want maxWords 150", words)
+	}
+	// The most frequent words of the chunk cut short are the ones kept
+	for _, word := range []string{"word00000", "word00100", "word00149"} {
+		if !hasWord(trie, word) {
+			t.Errorf("%s missing, want it loaded", word)
+		}
+	}
+	for _, word := range []string{"word00150", "word00199", "word00200"} {
+		if hasWord(trie, word) {
+			t.Errorf("%s loaded past maxWords", word)
+		}
+	}
+}
+
+func TestStartLoadingStopsAtMaxWords(t *testing.T) {
+	dir := buildTestChunks(t, testWords(300), 100)
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	t.Setenv("WORDSERVE_MAX_WORDS", "300")
+	t.Setenv("WORDSERVE_CHUNK_SIZE", "100")
+
+	// Between the second and third chunk boundaries
+	const maxWords = 250
+	loader := NewLoader(dir, maxWords)
+	loader.SetReleaseURL(offlineRelease(t))
+	defer loader.Stop()
+	if err := loader.StartLoading(); err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := loader.WaitUntilReady(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if words := loader.GetStats().TotalWords; words > maxWords {
+		t.Errorf("loaded %d words, want at most maxWords %d", words, maxWords)
+	}
+	if trie := loader.GetTrie(); !hasWord(trie, "word00249") || hasWord(trie, "word00250") {
+		t.Error("the third chunk's most frequent words weren't the ones kept")
+	}
+}
+
+func TestChunkStatsAddUpToTotalWords(t *testing.T) {
+	const totalWords, chunkSize = 300, 100
+	dir := buildTestChunks(t, testWords(totalWords), chunkSize)
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	t.Setenv("WORDSERVE_MAX_WORDS", strconv.Itoa(totalWords))
+	t.Setenv("WORDSERVE_CHUNK_SIZE", strconv.Itoa(chunkSize))
+	loader := NewLoader(dir, 0)
+	loader.SetReleaseURL(offlineRelease(t))
+	for chunkID := 1; chunkID <= 2; chunkID++ {
+		if err := loader.Load(chunkID); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	stats := loader.GetChunkStats()
+	if len(stats) != 3 {
+		t.Fatalf("got %d chunk stats, want 3", len(stats))
+	}
+	sum := 0
+	for _, stat := range stats {
+		sum += stat.WordCount
+		if stat.FileWords != chunkSize {
+			t.Errorf("chunk %d has %d words in its file, want %d", stat.ID, stat.FileWords, chunkSize)
+		}
+		if loaded := stat.ID <= 2; stat.Loaded != loaded || (stat.WordCount == chunkSize) != loaded {
+			t.Errorf("chunk %d: loaded %t with %d words, want loaded %t", stat.ID, stat.Loaded, stat.WordCount, loaded)
+		}
+	}
+	if total := loader.GetStats().TotalWords; sum != total {
+		t.Errorf("chunk word counts add up to %d, want the %d loaded words", sum, total)
+	}
+}
+
+func TestHasUnloaded(t *testing.T) {
+	dir := buildTestChunks(t, testWords(300), 100)
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	t.Setenv("WORDSERVE_MAX_WORDS", "300")
+	t.Setenv("WORDSERVE_CHUNK_SIZE", "100")
+
+	loader := NewLoader(dir, 150)
+	loader.SetReleaseURL(offlineRelease(t))
+	defer loader.Stop()
+	if loader.HasUnloaded() {
+		t.Error("HasUnloaded() = true before the chunks were scanned")
+	}
+	if err := loader.StartLoading(); err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := loader.WaitUntilReady(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if !loader.HasUnloaded() {
+		t.Error("HasUnloaded() = false with a chunk cut short and one not loaded")
+	}
+
+	// Completions ask on every request, so it must not wait for the write lock
+	loader.mu.RLock()
+	answered := make(chan bool)
+	go func() { answered <- loader.HasUnloaded() }()
+	select {
+	case <-answered:
+	case <-time.After(5 * time.Second):
+		t.Fatal("HasUnloaded blocked while the loader was read locked")
+	}
+	loader.mu.RUnlock()
+
+	if err := loader.RequestMore(150); err != nil {
+		t.Fatal(err)
+	}
+	deadline := time.Now().Add(10 * time.Second)
+	for loader.HasUnloaded() {
+		if time.Now().After(deadline) {
+			t.Fatal("HasUnloaded() still true after loading every chunk")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
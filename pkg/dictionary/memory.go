@@ -0,0 +1,48 @@
+package dictionary
+
+import (
+	"errors"
+	"fmt"
+)
+
+// estimatedWordBytes is the rough heap cost of one loaded word across the trie,
+// the frequency map and the per chunk word map, measured with words of about 8 letters
+const estimatedWordBytes = 320
+
+// ErrMemoryLimit is returned by Load when a chunk would take the dictionary over the limit set with SetMaxMemory
+var ErrMemoryLimit = errors.New("dictionary memory limit reached")
+
+// SetMaxMemory caps the estimated memory of the loaded words, 0 means no limit.
+// Chunks already loaded stay loaded when the limit is lowered.
+func (cl *Loader) SetMaxMemory(maxBytes int64) {
+	cl.mu.Lock()
+	defer cl.mu.Unlock()
+	cl.maxMemory = maxBytes
+}
+
+// EstimatedMemory returns the estimated heap bytes used by the loaded words
+func (cl *Loader) EstimatedMemory() int64 {
+	cl.mu.RLock()
+	defer cl.mu.RUnlock()
+	return cl.estimatedMemory()
+}
+
+// estimatedMemory is EstimatedMemory for callers already holding mu
+func (cl *Loader) estimatedMemory() int64 {
+	return int64(len(cl.wordFreqs)) * estimatedWordBytes
+}
+
+// checkMemory returns ErrMemoryLimit if adding words from chunkID would exceed the limit.
+// Callers hold mu.
+func (cl *Loader) checkMemory(chunkID, words int) error {
+	if cl.maxMemory <= 0 {
+		return nil
+	}
+	current := cl.estimatedMemory()
+	needed := int64(words) * estimatedWordBytes
+	if current+needed > cl.maxMemory {
+		return fmt.Errorf("%w: chunk %d needs about %d bytes, %d of %d in use (dict.max_memory_bytes)",
+			ErrMemoryLimit, chunkID, needed, current, cl.maxMemory)
+	}
+	return nil
+}
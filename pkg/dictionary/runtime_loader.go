@@ -1,8 +1,10 @@
 package dictionary
 
 import (
+	"context"
+	"errors"
 	"fmt"
-	"sort"
+	"slices"
 	"sync"
 
 	"github.com/charmbracelet/log"
@@ -12,6 +14,7 @@ import (
 type RuntimeLoader struct {
 	chunkLoader  *Loader
 	targetChunks int
+	maxChunks    int
 	mu           sync.RWMutex
 }
 
@@ -23,6 +26,13 @@ func NewRuntimeLoader(chunkLoader *Loader) *RuntimeLoader {
 	}
 }
 
+// SetMaxChunks sets the most chunks SetDictionarySize may load, 0 means no limit
+func (rl *RuntimeLoader) SetMaxChunks(maxChunks int) {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+	rl.maxChunks = maxChunks
+}
+
 // GetAvailableChunkCount returns the total number of available chunk files
 func (rl *RuntimeLoader) GetAvailableChunkCount() (int, error) {
 	chunks, err := rl.chunkLoader.GetAvailable()
@@ -32,6 +42,18 @@ func (rl *RuntimeLoader) GetAvailableChunkCount() (int, error) {
 	return len(chunks), nil
 }
 
+// Status returns the chunk count last asked for with SetDictionarySize, the chunks
+// actually loaded and the chunk files available. target is 0 until a size is set.
+// target and actual differ when a resize only partly succeeded, e.g. a chunk failed
+// to load, so clients can detect it and retry.
+func (rl *RuntimeLoader) Status() (target, actual, available int) {
+	rl.mu.RLock()
+	target = rl.targetChunks
+	rl.mu.RUnlock()
+	stats := rl.chunkLoader.GetStats()
+	return target, stats.LoadedChunks, stats.AvailableChunks
+}
+
 // GetMaxWordsAvailable returns the maximum number of words that can be loaded
 func (rl *RuntimeLoader) GetMaxWordsAvailable() (int, error) {
 	chunks, err := rl.chunkLoader.GetAvailable()
@@ -50,14 +72,33 @@ func (rl *RuntimeLoader) GetMaxWordsAvailable() (int, error) {
 // SetDictionarySize updates the dictionary to load the specified number of chunks
 // Automatically generates required chunks if not enough are available
 func (rl *RuntimeLoader) SetDictionarySize(targetChunks int) error {
+	return rl.SetDictionarySizeContext(context.Background(), targetChunks)
+}
+
+// NeedsGeneration reports whether loading targetChunks requires generating chunk files first
+func (rl *RuntimeLoader) NeedsGeneration(targetChunks int) bool {
+	return !rl.chunkLoader.checkDictNum(targetChunks)
+}
+
+// SetDictionarySizeContext works like SetDictionarySize but stops waiting on
+// chunk generation when ctx is done. The generation job itself keeps running
+// until it finishes or is cancelled with Loader.CancelGeneration.
+func (rl *RuntimeLoader) SetDictionarySizeContext(ctx context.Context, targetChunks int) error {
 	if targetChunks < 1 {
 		return fmt.Errorf("minimum dictionary size is 1 chunk")
 	}
+	rl.mu.RLock()
+	maxChunks := rl.maxChunks
+	rl.mu.RUnlock()
+	if maxChunks > 0 && targetChunks > maxChunks {
+		return fmt.Errorf("requested %d chunks exceeds the configured maximum of %d (dict.max_chunks)", targetChunks, maxChunks)
+	}
 
 	// Check if we have enough chunks
 	if !rl.chunkLoader.checkDictNum(targetChunks) {
 		log.Infof("Insufficient chunks available. Generating missing chunks for a total of %d.", targetChunks)
-		if err := rl.chunkLoader.checkChunkCount(targetChunks); err != nil {
+		id := rl.chunkLoader.StartGeneration(targetChunks)
+		if err := rl.chunkLoader.WaitGeneration(ctx, id); err != nil {
 			return fmt.Errorf("failed to generate required chunks: %w", err)
 		}
 	} else {
@@ -66,6 +107,9 @@ func (rl *RuntimeLoader) SetDictionarySize(targetChunks int) error {
 
 	currentStats := rl.chunkLoader.GetStats()
 	currentChunks := currentStats.LoadedChunks
+	if err := rl.fitWordLimit(targetChunks); err != nil {
+		return err
+	}
 
 	log.Debugf("Setting dictionary size: current=%d chunks, target=%d chunks", currentChunks, targetChunks)
 
@@ -87,43 +131,84 @@ func (rl *RuntimeLoader) SetDictionarySize(targetChunks int) error {
 	return nil
 }
 
+// fitWordLimit raises the loader's word limit so targetChunks whole chunks fit,
+// otherwise the last chunks of a bigger dictionary would only load in part
+func (rl *RuntimeLoader) fitWordLimit(targetChunks int) error {
+	chunks, err := rl.chunkLoader.GetAvailable()
+	if err != nil {
+		return err
+	}
+	words := 0
+	for i, chunk := range rl.chunkLoader.orderChunks(chunks) {
+		if i >= targetChunks {
+			break
+		}
+		words += chunk.WordCount
+	}
+	rl.chunkLoader.raiseMaxWords(words)
+	return nil
+}
+
 // loadAdditionalChunks loads the specified number of additional chunks
 func (rl *RuntimeLoader) loadAdditionalChunks(additionalChunks int) error {
 	chunks, err := rl.chunkLoader.GetAvailable()
 	if err != nil {
 		return err
 	}
-	sort.Slice(chunks, func(i, j int) bool {
-		return chunks[i].ID < chunks[j].ID
-	})
 	currentStats := rl.chunkLoader.GetStats()
 	currentChunks := currentStats.LoadedChunks
 	targetTotal := currentChunks + additionalChunks
 
 	loadedCount := 0
-	for _, chunk := range chunks {
+	for _, chunk := range rl.chunkLoader.orderChunks(chunks) {
 		if loadedCount >= additionalChunks {
 			break
 		}
+		// Loaded chunks don't count towards the additional ones, partly loaded ones get completed
+		partial := rl.chunkLoader.isPartial(chunk.ID)
+		if rl.chunkLoader.isLoaded(chunk.ID) && !partial {
+			continue
+		}
 		if err := rl.chunkLoader.Load(chunk.ID); err != nil {
+			if errors.Is(err, ErrMemoryLimit) {
+				rl.targetChunks = currentChunks + loadedCount
+				return err
+			}
 			log.Warnf("Failed to load chunk %d: %v", chunk.ID, err)
 			continue
 		}
-		loadedCount++
+		if !partial {
+			loadedCount++
+		}
 	}
 	rl.targetChunks = targetTotal
 	log.Debugf("Loaded %d additional chunks", loadedCount)
 	return nil
 }
 
-// unloadExcessChunks unloads the specified number of chunks from the highest numbers first
+// unloadExcessChunks unloads the specified number of chunks,
+// starting with the ones the chunk selector would load last
 func (rl *RuntimeLoader) unloadExcessChunks(excessChunks int) error {
 	// Get currently loaded chunk IDs
 	loadedChunkIDs := rl.chunkLoader.GetLoadedIDs()
 	if len(loadedChunkIDs) == 0 {
 		return nil
 	}
-	sort.Sort(sort.Reverse(sort.IntSlice(loadedChunkIDs)))
+	chunks, err := rl.chunkLoader.GetAvailable()
+	if err != nil {
+		return err
+	}
+	loaded := make(map[int]bool, len(loadedChunkIDs))
+	for _, id := range loadedChunkIDs {
+		loaded[id] = true
+	}
+	loadedChunkIDs = loadedChunkIDs[:0]
+	ordered := rl.chunkLoader.orderChunks(chunks)
+	for _, chunk := range slices.Backward(ordered) {
+		if loaded[chunk.ID] {
+			loadedChunkIDs = append(loadedChunkIDs, chunk.ID)
+		}
+	}
 	unloadedCount := 0
 	for _, chunkID := range loadedChunkIDs {
 		if unloadedCount >= excessChunks {
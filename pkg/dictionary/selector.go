@@ -0,0 +1,66 @@
+package dictionary
+
+import (
+	"math"
+	"slices"
+)
+
+// ChunkSelector decides the order in which chunks are queued for loading.
+// It receives the candidate chunks sorted by ID and returns them in the
+// order they should be loaded. Selectors may reorder the given slice in place.
+type ChunkSelector func(chunks []ChunkInfo) []ChunkInfo
+
+// FrequencyFirst keeps chunks in ID order, so the most frequent words load first.
+// This is the default selector.
+func FrequencyFirst(chunks []ChunkInfo) []ChunkInfo {
+	return chunks
+}
+
+// RoundRobin spreads loading across the frequency range.
+// Chunks are split into ~sqrt(n) contiguous bands and picked one band at a time,
+// so a partial load covers rare words as well as common ones.
+func RoundRobin(chunks []ChunkInfo) []ChunkInfo {
+	n := len(chunks)
+	if n <= 2 {
+		return chunks
+	}
+	bands := int(math.Ceil(math.Sqrt(float64(n))))
+	bandSize := (n + bands - 1) / bands
+
+	ordered := make([]ChunkInfo, 0, n)
+	for offset := range bandSize {
+		for band := range bands {
+			if i := band*bandSize + offset; i < n {
+				ordered = append(ordered, chunks[i])
+			}
+		}
+	}
+	return ordered
+}
+
+// SetChunkSelector replaces the strategy used to pick which chunks load next.
+// A nil selector restores [FrequencyFirst].
+func (cl *Loader) SetChunkSelector(selector ChunkSelector) {
+	cl.mu.Lock()
+	defer cl.mu.Unlock()
+	if selector == nil {
+		selector = FrequencyFirst
+	}
+	cl.selector = selector
+}
+
+// orderChunks returns a copy of chunks in the order given by the selector.
+func (cl *Loader) orderChunks(chunks []ChunkInfo) []ChunkInfo {
+	cl.mu.RLock()
+	selector := cl.selector
+	cl.mu.RUnlock()
+
+	ordered := slices.Clone(chunks)
+	slices.SortFunc(ordered, func(a, b ChunkInfo) int {
+		return a.ID - b.ID
+	})
+	if selector == nil {
+		return ordered
+	}
+	return selector(ordered)
+}
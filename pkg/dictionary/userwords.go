@@ -0,0 +1,171 @@
+package dictionary
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+
+	"github.com/bastiangx/wordserve/internal/utils"
+	"github.com/charmbracelet/log"
+	"github.com/tchap/go-patricia/v2/patricia"
+)
+
+// userWordsFile is the TOML layout of a saved user dictionary:
+//
+//	[words]
+//	kubernetes = 50000
+type userWordsFile struct {
+	Words map[string]int `toml:"words"`
+}
+
+// AddWord adds a word at runtime, on top of the loaded chunks.
+// The word stays when chunks are loaded or evicted, and its frequency
+// takes precedence over the one in any chunk that has the same word.
+//
+// Runtime words have a trie of their own, copied to publish each change (see
+// [Loader.GetTrie]), so adding many words is faster with [Loader.AddWords].
+func (cl *Loader) AddWord(word string, frequency int) error {
+	if err := checkUserWord(word, frequency); err != nil {
+		return err
+	}
+	cl.addWords(map[string]int{word: frequency})
+	return nil
+}
+
+// AddWords adds several words at runtime like [Loader.AddWord], copying the
+// runtime words' trie once for all of them. Words that can't be added are logged and
+// skipped; it returns how many were added.
+func (cl *Loader) AddWords(words map[string]int) int {
+	valid := make(map[string]int, len(words))
+	for word, frequency := range words {
+		if err := checkUserWord(word, frequency); err != nil {
+			log.Warnf("Skipping user word %q: %v", word, err)
+			continue
+		}
+		valid[word] = frequency
+	}
+	cl.addWords(valid)
+	return len(valid)
+}
+
+// checkUserWord reports why a word can't be added at runtime, if it can't
+func checkUserWord(word string, frequency int) error {
+	if word == "" {
+		return errors.New("empty word")
+	}
+	if frequency <= 0 {
+		return errors.New("frequency must be positive")
+	}
+	return nil
+}
+
+// addWords adds valid runtime words. Their trie is copied and changed without
+// holding cl.mu, so searches keep running until the new one is published.
+func (cl *Loader) addWords(words map[string]int) {
+	if len(words) == 0 {
+		return
+	}
+	cl.writeMu.Lock()
+	defer cl.writeMu.Unlock()
+
+	user := copyTrie(cl.trie.user)
+	userCount := len(cl.userWords)
+	for word, frequency := range words {
+		if _, exists := cl.userWords[word]; !exists {
+			userCount++
+		}
+		user.Set(patricia.Prefix(word), frequency)
+	}
+	sortTrie(user)
+
+	cl.mu.Lock()
+	defer cl.mu.Unlock()
+	for word, frequency := range words {
+		if _, exists := cl.wordFreqs[word]; !exists {
+			cl.totalWords++
+		}
+		cl.userWords[word] = frequency
+		cl.wordFreqs[word] = frequency
+		cl.maxFrequency = max(cl.maxFrequency, frequency)
+	}
+	cl.trie = cl.trie.withUserWords(user, userCount)
+	cl.version.Add(1)
+}
+
+// RemoveWord deletes a word from the dictionary, whether it was added at
+// runtime or came from a chunk. A removed chunk word comes back only when its
+// chunk is loaded again. It reports whether the word was present.
+func (cl *Loader) RemoveWord(word string) bool {
+	cl.writeMu.Lock()
+	defer cl.writeMu.Unlock()
+
+	if _, exists := cl.wordFreqs[word]; !exists {
+		return false
+	}
+	// Chunk words are hidden rather than deleted, leaving the chunks' tries as they are
+	trie := cl.trie
+	if _, isUserWord := cl.userWords[word]; isUserWord {
+		user := copyTrie(trie.user)
+		user.Delete(patricia.Prefix(word))
+		sortTrie(user)
+		trie = trie.withUserWords(user, len(cl.userWords)-1)
+	}
+	trie = trie.withRemoved(word, cl.version.Load()+1)
+
+	cl.mu.Lock()
+	defer cl.mu.Unlock()
+	delete(cl.userWords, word)
+	delete(cl.wordFreqs, word)
+	for _, chunkWords := range cl.chunkWords {
+		delete(chunkWords, word)
+	}
+	cl.trie = trie
+	cl.totalWords--
+	cl.version.Add(1)
+	return true
+}
+
+// UserWords returns a copy of the words added at runtime with their frequencies
+func (cl *Loader) UserWords() map[string]int {
+	cl.mu.RLock()
+	defer cl.mu.RUnlock()
+	words := make(map[string]int, len(cl.userWords))
+	maps.Copy(words, cl.userWords)
+	return words
+}
+
+// SaveUserWords writes the words added at runtime to a TOML file at path.
+// The file is replaced atomically, so a crash never leaves it half written.
+func (cl *Loader) SaveUserWords(path string) error {
+	if err := utils.EnsureDir(filepath.Dir(path)); err != nil {
+		return err
+	}
+	tmpPath := path + ".tmp"
+	if err := utils.SaveTOMLFile(userWordsFile{Words: cl.UserWords()}, tmpPath); err != nil {
+		os.Remove(tmpPath)
+		return fmt.Errorf("failed to save user words: %w", err)
+	}
+	if err := os.Rename(tmpPath, path); err != nil {
+		os.Remove(tmpPath)
+		return fmt.Errorf("failed to save user words: %w", err)
+	}
+	return nil
+}
+
+// LoadUserWords adds the words saved at path by SaveUserWords, on top of the
+// loaded chunks. A missing file is not an error, there is just nothing to load yet.
+func (cl *Loader) LoadUserWords(path string) error {
+	if !utils.FileExists(path) {
+		log.Debugf("No user words file at %s", path)
+		return nil
+	}
+	var file userWordsFile
+	if err := utils.LoadTOMLFile(path, &file); err != nil {
+		return fmt.Errorf("failed to load user words from %s: %w", path, err)
+	}
+	loaded := cl.AddWords(file.Words)
+	log.Debugf("Loaded %d user words from %s", loaded, path)
+	return nil
+}
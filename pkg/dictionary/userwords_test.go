@@ -0,0 +1,112 @@
+package dictionary
+
+import (
+	"maps"
+	"path/filepath"
+	"testing"
+
+	"github.com/tchap/go-patricia/v2/patricia"
+)
+
+func TestUserWordsRoundTrip(t *testing.T) {
+	words := map[string]int{"kubernetes": 50000, "wordserve": 1200, "ñandú": 7, "o'clock": 300}
+	saved := NewLoader(t.TempDir(), 0)
+	for word, freq := range words {
+		if err := saved.AddWord(word, freq); err != nil {
+			t.Fatal(err)
+		}
+	}
+	path := filepath.Join(t.TempDir(), "nested", "user_words.toml")
+	if err := saved.SaveUserWords(path); err != nil {
+		t.Fatal(err)
+	}
+
+	loaded := NewLoader(t.TempDir(), 0)
+	if err := loaded.LoadUserWords(path); err != nil {
+		t.Fatal(err)
+	}
+	if got := loaded.UserWords(); !maps.Equal(got, words) {
+		t.Errorf("UserWords after reload = %v, want %v", got, words)
+	}
+	for word, freq := range words {
+		if item := loaded.GetTrie().Get(patricia.Prefix(word)); item != freq {
+			t.Errorf("trie frequency of %q = %v, want %d", word, item, freq)
+		}
+	}
+}
+
+func TestUserWordsOverrideChunks(t *testing.T) {
+	loader := NewLoader(buildTestChunks(t, testWords(10), 10), 0)
+	if err := loader.Load(1); err != nil {
+		t.Fatal(err)
+	}
+	// word00009 is the least frequent chunk word, the user file makes it the most frequent
+	path := filepath.Join(t.TempDir(), "user_words.toml")
+	saved := NewLoader(t.TempDir(), 0)
+	if err := saved.AddWord("word00009", 1<<20); err != nil {
+		t.Fatal(err)
+	}
+	if err := saved.AddWord("extra", 5); err != nil {
+		t.Fatal(err)
+	}
+	if err := saved.SaveUserWords(path); err != nil {
+		t.Fatal(err)
+	}
+	if err := loader.LoadUserWords(path); err != nil {
+		t.Fatal(err)
+	}
+
+	trie := loader.GetTrie()
+	if item := trie.Get(patricia.Prefix("word00009")); item != 1<<20 {
+		t.Errorf("word00009 frequency = %v, want the user file's %d", item, 1<<20)
+	}
+	if item := trie.Get(patricia.Prefix("extra")); item != 5 {
+		t.Errorf("extra frequency = %v, want 5", item)
+	}
+	if got := countPrefix(trie, ""); got != 11 {
+		t.Errorf("words in the trie = %d, want the 10 chunk words and 1 new one", got)
+	}
+	// User words survive the chunk being evicted
+	if err := loader.Evict(1); err != nil {
+		t.Fatal(err)
+	}
+	if got := countPrefix(loader.GetTrie(), ""); got != 2 {
+		t.Errorf("words in the trie after evicting = %d, want the 2 user words", got)
+	}
+}
+
+func TestUserWordsLeaveChunkTriesShared(t *testing.T) {
+	loader := NewLoader(buildTestChunks(t, testWords(1000), 500), 0)
+	for chunkID := 1; chunkID <= 2; chunkID++ {
+		if err := loader.Load(chunkID); err != nil {
+			t.Fatal(err)
+		}
+	}
+	chunks := loader.trie.chunks
+
+	if err := loader.AddWord("kubernetes", 50000); err != nil {
+		t.Fatal(err)
+	}
+	if !loader.RemoveWord("word00001") || !loader.RemoveWord("kubernetes") {
+		t.Fatal("RemoveWord didn't find a loaded word")
+	}
+	for i, layer := range loader.trie.chunks {
+		if layer != chunks[i] {
+			t.Errorf("chunk %d's trie was replaced by a runtime word change", layer.id)
+		}
+	}
+	trie := loader.GetTrie()
+	if hasWord(trie, "word00001") || hasWord(trie, "kubernetes") {
+		t.Error("a removed word is still in the trie")
+	}
+	if got := countPrefix(trie, ""); got != 999 {
+		t.Errorf("words in the trie = %d, want 999", got)
+	}
+}
+
+func TestLoadUserWordsMissingFile(t *testing.T) {
+	loader := NewLoader(t.TempDir(), 0)
+	if err := loader.LoadUserWords(filepath.Join(t.TempDir(), "absent.toml")); err != nil {
+		t.Errorf("LoadUserWords on a missing file = %v, want nil", err)
+	}
+}
@@ -0,0 +1,131 @@
+package dictionary
+
+import (
+	"context"
+	"maps"
+	"os"
+	"slices"
+	"time"
+
+	"github.com/charmbracelet/log"
+)
+
+// chunkFileState is what the watcher compares to notice a rewritten chunk file
+type chunkFileState struct {
+	filename string
+	size     int64
+	modTime  time.Time
+}
+
+// WatchChunks polls the data dir every interval for chunk files that were added,
+// removed or rewritten by another process, until ctx is done.
+//
+// Changed chunks that are loaded get reloaded from disk, removed ones are evicted,
+// and new ones show up in GetAvailable. onChange, if set, is called with the IDs
+// of every chunk that changed after they have been handled.
+// Polling is used instead of filesystem events so it works the same everywhere.
+func (cl *Loader) WatchChunks(ctx context.Context, interval time.Duration, onChange func(chunkIDs []int)) {
+	states := cl.snapshotChunks()
+	retry := make(map[int]bool)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-cl.done:
+			return
+		case <-ticker.C:
+		}
+
+		current := cl.snapshotChunks()
+		changed := diffChunkStates(states, current)
+		if len(changed) == 0 {
+			continue
+		}
+		states = current
+		// A chunk still being written fails to load, forget it so the next poll retries
+		failed := cl.refreshChunks(changed, current, retry)
+		clear(retry)
+		for _, chunkID := range failed {
+			delete(states, chunkID)
+			retry[chunkID] = true
+		}
+		if onChange != nil {
+			onChange(changed)
+		}
+	}
+}
+
+// snapshotChunks stats every chunk file in the data dir
+func (cl *Loader) snapshotChunks() map[int]chunkFileState {
+	files, err := globChunks(cl.dirPath)
+	if err != nil {
+		log.Warnf("Failed to scan %s for chunk changes: %v", cl.dirPath, err)
+		return nil
+	}
+	states := make(map[int]chunkFileState, len(files))
+	for chunkID, filename := range files {
+		info, err := os.Stat(filename)
+		if err != nil {
+			continue
+		}
+		states[chunkID] = chunkFileState{filename: filename, size: info.Size(), modTime: info.ModTime()}
+	}
+	return states
+}
+
+// diffChunkStates returns the sorted IDs of chunks added, removed or modified between two snapshots
+func diffChunkStates(before, after map[int]chunkFileState) []int {
+	var changed []int
+	for chunkID, state := range after {
+		if previous, exists := before[chunkID]; !exists || previous != state {
+			changed = append(changed, chunkID)
+		}
+	}
+	for chunkID := range before {
+		if _, exists := after[chunkID]; !exists {
+			changed = append(changed, chunkID)
+		}
+	}
+	slices.Sort(changed)
+	return changed
+}
+
+// refreshChunks reloads or evicts changed chunks that are loaded, or that failed
+// to reload last time, and refreshes the available chunk list.
+// It returns the IDs that failed to reload.
+func (cl *Loader) refreshChunks(changed []int, current map[int]chunkFileState, retry map[int]bool) []int {
+	cl.mu.Lock()
+	if _, err := cl.scanChunks(); err != nil {
+		log.Warnf("Failed to refresh the chunk list: %v", err)
+	}
+	loaded := maps.Clone(cl.loadedChunks)
+	cl.mu.Unlock()
+
+	var failed []int
+	for _, chunkID := range changed {
+		if !loaded[chunkID] && !retry[chunkID] {
+			log.Infof("Chunk %d changed on disk", chunkID)
+			continue
+		}
+		if loaded[chunkID] {
+			if err := cl.Evict(chunkID); err != nil {
+				log.Warnf("Failed to evict changed chunk %d: %v", chunkID, err)
+				continue
+			}
+		}
+		if _, exists := current[chunkID]; !exists {
+			log.Infof("Chunk %d was removed from disk, evicted it", chunkID)
+			continue
+		}
+		if err := cl.Load(chunkID); err != nil {
+			log.Warnf("Failed to reload changed chunk %d, will retry: %v", chunkID, err)
+			failed = append(failed, chunkID)
+			continue
+		}
+		log.Infof("Chunk %d changed on disk, reloaded it", chunkID)
+	}
+	return failed
+}
@@ -0,0 +1,37 @@
+package server
+
+import (
+	"time"
+	"unicode/utf8"
+
+	"github.com/charmbracelet/log"
+)
+
+// accessEntry is one completion served over a network mode.
+// It holds the prefix length only, never the prefix, so logs don't leak what users type.
+type accessEntry struct {
+	client    string
+	prefixLen int
+	count     int
+	status    int
+	latency   time.Duration
+}
+
+// newAccessEntry starts an entry for a request from client with the given prefix
+func newAccessEntry(client, prefix string) accessEntry {
+	return accessEntry{client: client, prefixLen: utf8.RuneCountInString(prefix)}
+}
+
+// logAccess writes a timestamped access log line when server.access_log is on.
+// It logs regardless of the log level, operators turn it on explicitly.
+func (s *Server) logAccess(entry accessEntry) {
+	if !s.currentConfig().Server.AccessLog {
+		return
+	}
+	log.Print("access",
+		"client", entry.client,
+		"prefix_len", entry.prefixLen,
+		"count", entry.count,
+		"status", entry.status,
+		"latency", entry.latency)
+}
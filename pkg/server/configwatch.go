@@ -0,0 +1,78 @@
+package server
+
+import (
+	"path/filepath"
+	"time"
+
+	"github.com/bastiangx/wordserve/internal/utils"
+	"github.com/charmbracelet/log"
+	"github.com/fsnotify/fsnotify"
+)
+
+const (
+	// configDebounce is how long the file must stay unchanged before it is reloaded,
+	// editors often save in several writes
+	configDebounce = 200 * time.Millisecond
+	// configReloadEvery is the request count between reloads when the file can't be watched
+	configReloadEvery = 100
+)
+
+// watchConfig starts watching the config file and reloads it only when it changes.
+// The file's directory is watched rather than the file, so editors that save by
+// replacing the file are followed too. If the watcher can't be set up, the server
+// keeps reloading every configReloadEvery requests instead.
+func (s *Server) watchConfig() {
+	if s.configPath == "" {
+		return
+	}
+	if !s.configWatched.CompareAndSwap(false, true) {
+		return
+	}
+	watcher, err := fsnotify.NewWatcher()
+	if err == nil {
+		err = watcher.Add(filepath.Dir(s.configPath))
+		if err != nil {
+			watcher.Close()
+		}
+	}
+	if err != nil {
+		s.configWatched.Store(false)
+		log.Debugf("Not watching config file, reloading every %d requests instead: %v", configReloadEvery, err)
+		return
+	}
+	log.Debugf("Watching config file: %s", s.configPath)
+	go s.followConfig(watcher)
+}
+
+// followConfig reloads the config once the file has settled after each change,
+// until the server stops
+func (s *Server) followConfig(watcher *fsnotify.Watcher) {
+	defer watcher.Close()
+	configPath := filepath.Clean(s.configPath)
+	debounce := time.NewTimer(configDebounce)
+	debounce.Stop()
+	defer debounce.Stop()
+	for {
+		select {
+		case <-s.done:
+			return
+		case event, ok := <-watcher.Events:
+			if !ok {
+				return
+			}
+			if filepath.Clean(event.Name) == configPath && event.Op != fsnotify.Chmod {
+				debounce.Reset(configDebounce)
+			}
+		case err, ok := <-watcher.Errors:
+			if !ok {
+				return
+			}
+			log.Warnf("Config watcher: %v", err)
+		case <-debounce.C:
+			// Gone mid way through an atomic save, the file's creation brings it back
+			if utils.FileExists(configPath) {
+				s.reloadConfig()
+			}
+		}
+	}
+}
@@ -0,0 +1,73 @@
+package server
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/bastiangx/wordserve/pkg/config"
+	completion "github.com/bastiangx/wordserve/pkg/suggest"
+)
+
+// saveMaxLimit writes a config with server.max_limit set to maxLimit to path
+func saveMaxLimit(t *testing.T, path string, maxLimit int) {
+	t.Helper()
+	cfg := config.DefaultConfig()
+	cfg.Server.MaxLimit = maxLimit
+	if err := config.SaveConfig(cfg, path); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// waitMaxLimit waits for the server's config to have server.max_limit set to maxLimit
+func waitMaxLimit(t *testing.T, s *Server, maxLimit int) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for s.currentConfig().Server.MaxLimit != maxLimit {
+		if time.Now().After(deadline) {
+			t.Fatalf("max_limit = %d, want %d", s.currentConfig().Server.MaxLimit, maxLimit)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestConfigReloadsWhenFileChanges(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "config.toml")
+	saveMaxLimit(t, configPath, 64)
+	cfg, err := config.LoadConfig(configPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := NewServer(completion.NewCompleter(), cfg, configPath)
+	t.Cleanup(s.Shutdown)
+	s.watchConfig()
+	if !s.configWatched.Load() {
+		t.Fatal("config file is not watched")
+	}
+
+	saveMaxLimit(t, configPath, 12)
+	waitMaxLimit(t, s, 12)
+
+	// Saved the way editors do, to a new file renamed over the old one
+	tmpPath := configPath + ".swp"
+	saveMaxLimit(t, tmpPath, 7)
+	if err := os.Rename(tmpPath, configPath); err != nil {
+		t.Fatal(err)
+	}
+	waitMaxLimit(t, s, 7)
+
+	if count := s.requestCount.Load(); count != 0 {
+		t.Errorf("request count = %d, want the reloads to happen without requests", count)
+	}
+}
+
+func TestConfigWatchFallsBackToRequestCount(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "missing", "config.toml")
+	s := NewServer(completion.NewCompleter(), config.DefaultConfig(), configPath)
+	t.Cleanup(s.Shutdown)
+	s.watchConfig()
+	if s.configWatched.Load() {
+		t.Error("config file in a missing directory is watched, want reloads every request count instead")
+	}
+}
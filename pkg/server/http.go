@@ -0,0 +1,138 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/log"
+)
+
+// StartHTTP serves completions as JSON over HTTP on addr until the listener fails.
+// It replaces the stdin/stdout loop and shares the completer and config checks with it.
+func (s *Server) StartHTTP(addr string) error {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /complete", s.handleHTTPComplete)
+
+	httpServer := &http.Server{
+		Addr:              addr,
+		Handler:           s.withCORS(mux),
+		ReadHeaderTimeout: 5 * time.Second,
+	}
+	s.watchConfig()
+	s.watchDictionary()
+	log.Infof("Serving HTTP on %s", addr)
+	return httpServer.ListenAndServe()
+}
+
+// withCORS adds CORS headers for the origins in server.cors_origin and answers
+// OPTIONS preflights. The setting is either "*" or a comma separated list of origins,
+// empty leaves CORS off so browsers only allow same-origin calls.
+func (s *Server) withCORS(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		allowed := s.currentConfig().Server.CORSOrigin
+		origin := r.Header.Get("Origin")
+		if allowed != "" && origin != "" {
+			header := w.Header()
+			if allowed == "*" {
+				header.Set("Access-Control-Allow-Origin", "*")
+			} else {
+				header.Add("Vary", "Origin")
+				origins := strings.Split(allowed, ",")
+				for i := range origins {
+					origins[i] = strings.TrimSpace(origins[i])
+				}
+				if slices.Contains(origins, origin) {
+					header.Set("Access-Control-Allow-Origin", origin)
+				}
+			}
+			header.Set("Access-Control-Allow-Methods", "GET, OPTIONS")
+			header.Set("Access-Control-Allow-Headers", "Content-Type")
+			header.Set("Access-Control-Max-Age", "600")
+		}
+		if r.Method == http.MethodOptions {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// handleHTTPComplete answers GET /complete?p=<prefix>&l=<limit>[&tail=1][&h=1][&after=<text>][&lang=<code>]
+func (s *Server) handleHTTPComplete(w http.ResponseWriter, r *http.Request) {
+	start := time.Now()
+	query := r.URL.Query()
+	request := CompletionRequest{
+		ID:     query.Get("id"),
+		Prefix: query.Get("p"),
+		After:  query.Get("after"),
+		Lang:   query.Get("lang"),
+	}
+	entry := newAccessEntry(r.RemoteAddr, request.Prefix)
+	defer func() {
+		entry.latency = time.Since(start)
+		s.logAccess(entry)
+	}()
+
+	if rawLimit := query.Get("l"); rawLimit != "" {
+		limit, err := strconv.Atoi(rawLimit)
+		if err != nil {
+			entry.status = http.StatusBadRequest
+			writeJSON(w, entry.status, CompletionError{ID: request.ID, Error: "invalid limit", Code: 400})
+			return
+		}
+		request.Limit = limit
+	}
+	for param, target := range map[string]*int{"minl": &request.MinLen, "maxl": &request.MaxLen} {
+		if raw := query.Get(param); raw != "" {
+			value, err := strconv.Atoi(raw)
+			if err != nil {
+				entry.status = http.StatusBadRequest
+				writeJSON(w, entry.status, CompletionError{ID: request.ID, Error: "invalid " + param, Code: 400})
+				return
+			}
+			*target = value
+		}
+	}
+	if rawTail := query.Get("tail"); rawTail != "" {
+		request.Tail, _ = strconv.ParseBool(rawTail)
+	}
+	if rawHighlight := query.Get("h"); rawHighlight != "" {
+		request.Highlight, _ = strconv.ParseBool(rawHighlight)
+	}
+	if rawDebug := query.Get("d"); rawDebug != "" {
+		request.Debug, _ = strconv.ParseBool(rawDebug)
+	}
+	if rawPct := query.Get("pct"); rawPct != "" {
+		request.Pct, _ = strconv.ParseBool(rawPct)
+	}
+	if rawCasing := query.Get("casing"); rawCasing != "" {
+		request.Casing, _ = strconv.ParseBool(rawCasing)
+	}
+	if rawCanonical := query.Get("canon"); rawCanonical != "" {
+		request.Canonical, _ = strconv.ParseBool(rawCanonical)
+	}
+	s.countRequest()
+
+	response, completionErr := s.runCompletion(request)
+	if completionErr != nil {
+		entry.status = completionErr.Code
+		writeJSON(w, entry.status, completionErr)
+		return
+	}
+	entry.status = http.StatusOK
+	entry.count = response.Count
+	writeJSON(w, entry.status, response)
+}
+
+// writeJSON encodes body as the JSON response with the given status code
+func writeJSON(w http.ResponseWriter, status int, body any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(body); err != nil {
+		log.Errorf("Failed to encode HTTP response: %v", err)
+	}
+}
@@ -19,19 +19,169 @@ The server responds with suggestions ranked by freq:
 
 	{"id": "req_001", "s": [{"w": "amenity", "r": 1}, {"w": "america", "r": 2}], "c": 2, "t": 145}
 
+Setting "d" adds the dictionary chunk each suggestion was loaded from, to tell whether a
+missing word is just in a chunk that isn't loaded:
+
+	{"id": "req_002", "p": "ame", "l": 2, "d": true}
+	{"id": "req_002", "s": [{"w": "amenity", "r": 1, "k": 1}, {"w": "america", "r": 2, "k": 1}], "c": 2, "t": 150}
+
+Setting "pct" adds each word's frequency percentile among all loaded words, which unlike
+the rank doesn't depend on how many results came back. 99.5 means the top 0.5%:
+
+	{"id": "req_003", "p": "ame", "l": 2, "pct": true}
+	{"id": "req_003", "s": [{"w": "amenity", "r": 1, "pct": 99.91}, {"w": "america", "r": 2, "pct": 99.87}], "c": 2, "t": 150}
+
+Setting "casing" adds how the prefix's capitals were applied to the words, so clients that
+manage casing themselves can undo it: "none", "upper" (all-caps), "title" (first letter)
+or "mixed" (each capital copied to its position):
+
+	{"id": "req_007", "p": "Ame", "l": 2, "casing": true}
+	{"id": "req_007", "s": [{"w": "Amenity", "r": 1}, {"w": "America", "r": 2}], "c": 2, "t": 140, "casing": "title"}
+
+Setting "canon" returns the words as the dictionary has them and leaves the casing to the
+client, to apply when one is accepted. The response always carries "casing" then, and for
+"mixed" the rune positions to capitalize in "caps":
+
+	{"id": "req_008", "p": "hEl", "l": 2, "canon": true}
+	{"id": "req_008", "s": [{"w": "hello", "r": 1}, {"w": "help", "r": 2}], "c": 2, "t": 120, "casing": "mixed", "caps": [1]}
+
+When fewer suggestions than the limit are found while some dictionary chunks aren't loaded,
+the response has "more": true, a hint that set_size may find more words:
+
+	{"id": "req_004", "s": [{"w": "kubernetes", "r": 1}], "c": 1, "t": 90, "more": true}
+
+Suggestions can be limited to a length range in runes with "minl" and "maxl", either may be left out.
+Words outside the range are skipped during the search, so the limit is still filled when possible:
+
+	{"id": "req_005", "p": "ca", "l": 10, "minl": 5, "maxl": 7}
+
+With extra languages in dict.languages, "lang" picks the dictionary to complete from.
+Leaving it out, or naming dict.language, uses the primary one from the data dir.
+Dictionary actions like set_size and get_info take "lang" as well:
+
+	{"id": "req_006", "p": "hal", "l": 5, "lang": "de"}
+
+Setting "stream" sends each suggestion as its own message as soon as the list is ranked,
+so a client can render the top ones before the rest arrive. Items carry the request id
+and their index "i", in rank order, and a "done" message with the count ends the stream:
+
+	{"id": "req_003", "p": "ame", "l": 64, "stream": true}
+	{"id": "req_003", "i": 0, "s": {"w": "amenity", "r": 1}}
+	{"id": "req_003", "i": 1, "s": {"w": "america", "r": 2}}
+	{"id": "req_003", "done": true, "c": 2, "t": 140}
+
+A rejected streamed request gets the usual single error message and no done marker.
+
+Several prefixes can be completed in one round trip, results are keyed by their index:
+
+	{"id": "b1", "action": "batch_complete", "reqs": [{"p": "hel", "l": 10}, {"p": "wor", "l": 5}]}
+
+The word after a given one can be predicted from bigram data, optionally narrowed by a prefix:
+
+	{"id": "n1", "action": "predict_next", "prev": "thank", "p": "", "l": 5}
+
+Whether a prefix has any completions, e.g. before showing a popup, is cheaper to ask with
+count than with a full completion. Counting stops at 1000, "capped" is set when it did:
+
+	{"id": "k1", "action": "count", "p": "hel"}
+	{"id": "k1", "c": 214, "t": 35}
+
 Dict management enables runtime adjustment of loaded word sets:
 
 	{"id": "dict_001", "action": "set_size", "chunk_count": 5}
 	{"id": "dict_002", "action": "get_options"}
 
+When set_size needs to generate missing chunk files, it replies right away with a job id
+and status "pending", then generates and loads the chunks in the background:
+
+	{"id": "dict_003", "status": "pending", "job": "job_1", "job_state": "running"}
+	{"id": "dict_004", "action": "job_status", "job": "job_1"}
+
+The underlying generation runs can be listed or cancelled:
+
+	{"id": "gen_001", "action": "list_generations"}
+	{"id": "gen_002", "action": "cancel_generation", "generation": 1}
+
+Per-chunk word counts and score ranges show where the loaded words, and memory, come from:
+
+	{"id": "dict_005", "action": "get_chunk_stats"}
+
+Words can be taught or removed until the server exits, both reply with a status:
+
+	{"id": "w1", "action": "add_word", "word": "kubernetes", "freq": 50000}
+	{"id": "w2", "action": "remove_word", "word": "kubernetes"}
+
+Blacklisted words are never suggested, whatever their frequency:
+
+	{"id": "w3", "action": "blacklist_add", "word": "badword"}
+	{"id": "w4", "action": "blacklist_remove", "word": "badword"}
+
+Server limits and filtering can be changed at runtime, they are validated, saved to the config file
+and echoed back:
+
+	{"id": "c1", "action": "set_config", "max_limit": 20, "enable_filter": false}
+
+GUIs without a TOML parser can read the whole config as a JSON string and send back
+any subset of it, keyed like config.toml:
+
+	{"id": "c2", "action": "get_config_json"}
+	{"id": "c3", "action": "set_config_json", "config": "{\"server\":{\"max_limit\":20}}"}
+
+Everything useful for a bug report, stats, loader state, config and paths, can be written to one JSON file:
+
+	{"id": "m1", "action": "dump_metrics", "path": "/tmp/wordserve-metrics.json"}
+
+Completion latency since startup, in microseconds, is returned by the metrics action.
+It covers every completion, over IPC, HTTP and in batches:
+
+	{"id": "m2", "action": "metrics"}
+	{"id": "m2", "status": "ok", "count": 1520, "avg_us": 84, "min_us": 12, "max_us": 2310}
+
+A client that is about to exit can ask the server to stop. Pending responses are sent first,
+then the loader is stopped and the reply is the last message before Start returns:
+
+	{"id": "bye", "action": "shutdown"}
+
+Embedders can call Server.Shutdown instead. It answers every request already read,
+including queued and held ones, flushes stdout and stops the loader; requests read
+afterwards get a 503 error.
+
+Start ties the server's lifetime to stdin: when the client closes it, the server stops.
+Embedders serving clients that come and go can call ServeSession with each client's
+reader and writer instead. A session ends at its reader's EOF while the server keeps
+its loaded dictionary for the next one; sessions are served one at a time.
+Taught, removed and blacklisted words belong to the dictionary, not the session, so later
+sessions see them too. Cached results are kept per language and dropped whenever any of
+them changes, so a cached page never differs from what a fresh search would return.
+
+Chunks load in the background, so completions sent right after startup may see only part of the
+dictionary. With queue_until_ready set in the [server] config, they are held until the startup
+chunks are loaded, at most 30s, and requests read meanwhile wait behind them.
+
+With request_timeout_ms set, a completion whose trie search runs longer is abandoned and
+answered with a 504 error instead of holding up the requests behind it.
+
 Response structures include status information and error details when an op fail.
+A completion whose dictionary search fails gets a 500 error, never an empty success.
+
+# HTTP
+
+StartHTTP serves completions over plain HTTP instead of stdin/stdout, for clients
+that can't speak msgpack such as browser based editors. Responses use the same
+shapes encoded as JSON:
+
+	GET /complete?p=ame&l=24
+	{"s": [{"w": "amenity", "r": 1}, {"w": "america", "r": 2}], "c": 2, "t": 145}
 
-The server maintains request counts for periodic cleanup and config reloading. -> (BETA ONLY)
+The server maintains request counts for periodic cleanup. The config file is watched and
+reloaded 200ms after it last changes; if it can't be watched, it is reloaded every 100 requests instead. -> (BETA ONLY)
 
 # Message Types
 
 CompletionRequest and CompletionResponse handle the main prefix suggestion.
 Request includes a prefix string and optional limit for result count.
+A limit of 0 or less, or none, returns half of server.max_limit, capped at max_limit otherwise.
+Library calls such as suggest.Complete fall back to suggest.DefaultLimit (32) the same way.
 Responses contain suggestion arrays with word strings and rank information, plus timing data.
 
 DictionaryRequest and DictionaryResponse manage runtime dictionary operations.
@@ -48,23 +198,118 @@ package server
 
 // CompletionRequest - minimal completion request
 type CompletionRequest struct {
-	ID     string `msgpack:"id"`
-	Prefix string `msgpack:"p"`
-	Limit  int    `msgpack:"l"`
+	ID        string `msgpack:"id"`
+	Prefix    string `msgpack:"p"`
+	Limit     int    `msgpack:"l"`
+	Tail      bool   `msgpack:"tail,omitempty"`   // include the remaining-to-type suffix
+	After     string `msgpack:"after,omitempty"`  // text right after the cursor, used for ranking
+	Highlight bool   `msgpack:"h,omitempty"`      // include the matched rune positions
+	Debug     bool   `msgpack:"d,omitempty"`      // include the chunk each suggestion came from
+	Stream    bool   `msgpack:"stream,omitempty"` // send each suggestion as its own message, then a done marker
+	MinLen    int    `msgpack:"minl,omitempty"`   // shortest suggestion in runes, 0 for no bound
+	MaxLen    int    `msgpack:"maxl,omitempty"`   // longest suggestion in runes, 0 for no bound
+	Lang      string `msgpack:"lang,omitempty"`   // language to complete in, empty for dict.language
+	Pct       bool   `msgpack:"pct,omitempty"`    // include each word's frequency percentile in the dictionary
+	Casing    bool   `msgpack:"casing,omitempty"` // include the casing applied to the suggestions
+	Canonical bool   `msgpack:"canon,omitempty"`  // return words in dictionary case, with the casing to apply on accept
+}
+
+// PredictRequest - next-word prediction request, answered with a CompletionResponse
+type PredictRequest struct {
+	ID       string `msgpack:"id"`
+	Action   string `msgpack:"action"` // "predict_next"
+	Previous string `msgpack:"prev"`   // word before the cursor
+	Prefix   string `msgpack:"p"`      // partly typed next word, may be empty
+	Limit    int    `msgpack:"l"`
+	Lang     string `msgpack:"lang,omitempty"` // as in CompletionRequest
+}
+
+// CountRequest - asks how many completions a prefix has, answered with a CountResponse
+type CountRequest struct {
+	ID      string `msgpack:"id"`
+	Action  string `msgpack:"action"` // "count"
+	Prefix  string `msgpack:"p"`
+	MinFreq int    `msgpack:"minf,omitempty"` // frequency threshold, 0 for the one completions use
+	Lang    string `msgpack:"lang,omitempty"` // as in CompletionRequest
+}
+
+// CountResponse - number of words completing a prefix, counted up to 1000
+type CountResponse struct {
+	ID        string `msgpack:"id"`
+	Count     int    `msgpack:"c"`
+	Capped    bool   `msgpack:"capped,omitempty"` // the count stopped at 1000, more words match
+	TimeTaken int64  `msgpack:"t"`
 }
 
 // CompletionSuggestion - minimal suggestion response
 type CompletionSuggestion struct {
-	Word string `msgpack:"w"`
-	Rank uint16 `msgpack:"r"`
+	Word string `msgpack:"w" json:"w"`
+	Rank uint16 `msgpack:"r" json:"r"`
+	Tail string `msgpack:"tail,omitempty" json:"tail,omitempty"`
+	// MatchedIndexes are the rune positions in Word that matched the prefix
+	MatchedIndexes []int `msgpack:"h,omitempty" json:"h,omitempty"`
+	// Chunk is the dictionary chunk the word was loaded from, 0 for runtime words
+	Chunk int `msgpack:"k,omitempty" json:"k,omitempty"`
+	// Percentile is the share of loaded words less frequent than this one, 99.5 is the top 0.5%
+	Percentile float64 `msgpack:"pct,omitempty" json:"pct,omitempty"`
 }
 
 // CompletionResponse - completion response
 type CompletionResponse struct {
-	ID          string                 `msgpack:"id"`
+	ID          string                 `msgpack:"id" json:"id,omitempty"`
+	Suggestions []CompletionSuggestion `msgpack:"s" json:"s"`
+	Count       int                    `msgpack:"c" json:"c"`
+	TimeTaken   int64                  `msgpack:"t" json:"t"`
+	// More is set when fewer than the limit were found and some chunks aren't loaded,
+	// so growing the dictionary may find more words
+	More bool `msgpack:"more,omitempty" json:"more,omitempty"`
+	// Casing is how the prefix's capitals were applied, "none", "upper", "title" or "mixed",
+	// only when requested. With "canon" it is the casing to apply instead.
+	Casing string `msgpack:"casing,omitempty" json:"casing,omitempty"`
+	// Caps are the rune positions that "mixed" casing capitalizes, only with "canon"
+	Caps []int `msgpack:"caps,omitempty" json:"caps,omitempty"`
+}
+
+// CompletionStreamItem - one suggestion of a streamed completion, sent in rank order
+type CompletionStreamItem struct {
+	ID         string               `msgpack:"id"`
+	Index      int                  `msgpack:"i"` // position in the full list, from 0
+	Suggestion CompletionSuggestion `msgpack:"s"`
+}
+
+// CompletionStreamDone - last message of a streamed completion
+type CompletionStreamDone struct {
+	ID        string `msgpack:"id"`
+	Done      bool   `msgpack:"done"`
+	Count     int    `msgpack:"c"` // number of CompletionStreamItem messages sent before it
+	TimeTaken int64  `msgpack:"t"`
+	More      bool   `msgpack:"more,omitempty"`   // as in CompletionResponse
+	Casing    string `msgpack:"casing,omitempty"` // as in CompletionResponse
+	Caps      []int  `msgpack:"caps,omitempty"`   // as in CompletionResponse
+}
+
+// BatchCompletionRequest - several completion requests in one message
+type BatchCompletionRequest struct {
+	ID       string              `msgpack:"id"`
+	Action   string              `msgpack:"action"` // "batch_complete"
+	Requests []CompletionRequest `msgpack:"reqs"`
+}
+
+// BatchCompletionResult - suggestions for one entry of a batch, keyed by its index
+type BatchCompletionResult struct {
+	Index       int                    `msgpack:"i"`
 	Suggestions []CompletionSuggestion `msgpack:"s"`
 	Count       int                    `msgpack:"c"`
-	TimeTaken   int64                  `msgpack:"t"`
+	More        bool                   `msgpack:"more,omitempty"` // as in CompletionResponse
+	Error       string                 `msgpack:"e,omitempty"`
+	Code        int                    `msgpack:"code,omitempty"`
+}
+
+// BatchCompletionResponse - batch completion response, TimeTaken covers the whole batch
+type BatchCompletionResponse struct {
+	ID        string                  `msgpack:"id"`
+	Results   []BatchCompletionResult `msgpack:"r"`
+	TimeTaken int64                   `msgpack:"t"`
 }
 
 // CONFIG MESSAGES - Settings updates (dictionary only, other configs via TOML)
@@ -72,8 +317,11 @@ type CompletionResponse struct {
 // DictionaryRequest - dictionary management request
 type DictionaryRequest struct {
 	ID         string `msgpack:"id"`
-	Action     string `msgpack:"action"`                // "get_info", "set_size", "get_options", "get_chunk_count"
+	Action     string `msgpack:"action"`                // "get_info", "set_size", "get_options", "get_chunk_count", "list_generations", "cancel_generation", "job_status", "get_chunk_stats"
 	ChunkCount *int   `msgpack:"chunk_count,omitempty"` // for "set_size"
+	Generation *int   `msgpack:"generation,omitempty"`  // for "cancel_generation"
+	Job        string `msgpack:"job,omitempty"`         // for "job_status"
+	Lang       string `msgpack:"lang,omitempty"`        // language to manage, empty for dict.language
 }
 
 // DictionarySizeOption - dictionary size option
@@ -89,27 +337,86 @@ type DictionaryResponse struct {
 	Status          string                 `msgpack:"status"`
 	Error           string                 `msgpack:"error,omitempty"`
 	CurrentChunks   int                    `msgpack:"current_chunks,omitempty"`
+	TargetChunks    int                    `msgpack:"target_chunks,omitempty"` // last set_size, differs from current_chunks after a partial resize
 	AvailableChunks int                    `msgpack:"available_chunks,omitempty"`
+	Version         int                    `msgpack:"version,omitempty"` // dictionary version, changes on every load/evict
 	Options         []DictionarySizeOption `msgpack:"options,omitempty"`
+	Generations     []GenerationInfo       `msgpack:"generations,omitempty"`
+	Chunks          []ChunkStatInfo        `msgpack:"chunks,omitempty"`
+	Job             string                 `msgpack:"job,omitempty"`
+	JobState        string                 `msgpack:"job_state,omitempty"` // "running", "done", "failed"
+}
+
+// GenerationInfo - status of a background chunk generation job
+type GenerationInfo struct {
+	ID           int    `msgpack:"id"`
+	TargetChunks int    `msgpack:"target_chunks"`
+	State        string `msgpack:"state"`           // "running", "done", "failed", "cancelled"
+	Stage        string `msgpack:"stage,omitempty"` // "build" or "download"
+	ElapsedMs    int64  `msgpack:"elapsed_ms"`
+	Error        string `msgpack:"error,omitempty"`
+}
+
+// ChunkStatInfo - per-chunk word counts and score range, from get_chunk_stats
+type ChunkStatInfo struct {
+	ID        int  `msgpack:"id"`
+	FileWords int  `msgpack:"file_words"`
+	Words     int  `msgpack:"words"` // loaded from this chunk
+	Loaded    bool `msgpack:"loaded"`
+	Partial   bool `msgpack:"partial,omitempty"` // cut short by the word limit
+	MinScore  int  `msgpack:"min_score,omitempty"`
+	MaxScore  int  `msgpack:"max_score,omitempty"`
+}
+
+// WordRequest - runtime word addition or removal
+type WordRequest struct {
+	ID     string `msgpack:"id"`
+	Action string `msgpack:"action"`         // "add_word", "remove_word", "blacklist_add", "blacklist_remove"
+	Word   string `msgpack:"word"`           // stored lowercase, like the dictionary
+	Freq   int    `msgpack:"freq,omitempty"` // for "add_word", higher ranks first
+	Lang   string `msgpack:"lang,omitempty"` // as in CompletionRequest
 }
 
 // ConfigRequest - config management request
 type ConfigRequest struct {
 	ID     string `msgpack:"id"`
-	Action string `msgpack:"action"` // "rebuild_config", "get_config_path"
+	Action string `msgpack:"action"`           // "rebuild_config", "get_config_path", "set_config", "get_config_json", "set_config_json", "dump_metrics", "shutdown"
+	Path   string `msgpack:"path,omitempty"`   // for "dump_metrics", file the JSON is written to
+	Config string `msgpack:"config,omitempty"` // for "set_config_json", a JSON object with any subset of the TOML sections and keys
+}
+
+// ServerSettings - server config values a "set_config" request can change, any subset may be sent
+type ServerSettings struct {
+	MaxLimit     int  `msgpack:"max_limit"`
+	MinPrefix    int  `msgpack:"min_prefix"`
+	MaxPrefix    int  `msgpack:"max_prefix"`
+	EnableFilter bool `msgpack:"enable_filter"`
 }
 
 // ConfigResponse - config operation response
 type ConfigResponse struct {
-	ID         string `msgpack:"id"`
-	Status     string `msgpack:"status"`
-	Error      string `msgpack:"error,omitempty"`
-	ConfigPath string `msgpack:"config_path,omitempty"`
+	ID         string          `msgpack:"id"`
+	Status     string          `msgpack:"status"`
+	Error      string          `msgpack:"error,omitempty"`
+	ConfigPath string          `msgpack:"config_path,omitempty"`
+	Path       string          `msgpack:"path,omitempty"`     // file written by "dump_metrics"
+	Settings   *ServerSettings `msgpack:"settings,omitempty"` // values in effect after "set_config"
+	Config     string          `msgpack:"config,omitempty"`   // full config as JSON, from "get_config_json" and "set_config_json"
+}
+
+// LatencyResponse - completion latency over the server's lifetime, from the "metrics" action
+type LatencyResponse struct {
+	ID     string `msgpack:"id" json:"-"`
+	Status string `msgpack:"status" json:"-"`
+	Count  int64  `msgpack:"count" json:"count"` // completions answered
+	AvgUs  int64  `msgpack:"avg_us" json:"avg_us"`
+	MinUs  int64  `msgpack:"min_us" json:"min_us"`
+	MaxUs  int64  `msgpack:"max_us" json:"max_us"`
 }
 
 // CompletionError holds basic error information for completion requests
 type CompletionError struct {
-	ID    string `msgpack:"id"`
-	Error string `msgpack:"e"`
-	Code  int    `msgpack:"c"`
+	ID    string `msgpack:"id" json:"id,omitempty"`
+	Error string `msgpack:"e" json:"e"`
+	Code  int    `msgpack:"c" json:"c"`
 }
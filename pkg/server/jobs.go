@@ -0,0 +1,74 @@
+package server
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// jobState values reported by job_status
+const (
+	jobRunning = "running"
+	jobDone    = "done"
+	jobFailed  = "failed"
+)
+
+// job tracks a dictionary operation running in the background
+type job struct {
+	id         string
+	chunkCount int
+	state      string
+	err        string
+	startedAt  time.Time
+	finishedAt time.Time
+}
+
+// jobRegistry keeps track of async dictionary jobs for job_status polling
+type jobRegistry struct {
+	mu     sync.Mutex
+	jobs   map[string]*job
+	nextID int
+}
+
+func newJobRegistry() *jobRegistry {
+	return &jobRegistry{jobs: make(map[string]*job)}
+}
+
+// start runs fn in the background and returns the new job's id
+func (r *jobRegistry) start(chunkCount int, fn func() error) string {
+	r.mu.Lock()
+	r.nextID++
+	j := &job{
+		id:         fmt.Sprintf("job_%d", r.nextID),
+		chunkCount: chunkCount,
+		state:      jobRunning,
+		startedAt:  time.Now(),
+	}
+	r.jobs[j.id] = j
+	r.mu.Unlock()
+
+	go func() {
+		err := fn()
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		j.finishedAt = time.Now()
+		if err != nil {
+			j.state = jobFailed
+			j.err = err.Error()
+			return
+		}
+		j.state = jobDone
+	}()
+	return j.id
+}
+
+// get returns a snapshot of the job with the given id
+func (r *jobRegistry) get(id string) (job, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	j, ok := r.jobs[id]
+	if !ok {
+		return job{}, false
+	}
+	return *j, true
+}
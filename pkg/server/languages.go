@@ -0,0 +1,71 @@
+package server
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+
+	"github.com/bastiangx/wordserve/pkg/dictionary"
+	completion "github.com/bastiangx/wordserve/pkg/suggest"
+	"github.com/charmbracelet/log"
+)
+
+// language is one dictionary the server completes from, selected by a request's lang
+type language struct {
+	completer     completion.ICompleter
+	chunkLoader   *dictionary.Loader
+	runtimeLoader *dictionary.RuntimeLoader
+}
+
+// newLanguage wraps a completer, with runtime sizing when it is backed by chunk files
+func newLanguage(completer completion.ICompleter, maxChunks int) *language {
+	lang := &language{completer: completer}
+	if lazyCompleter, ok := completer.(*completion.Completer); ok {
+		if chunkLoader := lazyCompleter.GetChunkLoader(); chunkLoader != nil {
+			lang.chunkLoader = chunkLoader
+			lang.runtimeLoader = dictionary.NewRuntimeLoader(chunkLoader)
+			lang.runtimeLoader.SetMaxChunks(maxChunks)
+		}
+	}
+	return lang
+}
+
+// AddLanguage serves completer to requests whose lang is code, next to the primary
+// dictionary named by dict.language. Each language has its own trie and chunk set,
+// sized independently with set_size. Call it before Start or StartHTTP.
+func (s *Server) AddLanguage(code string, completer completion.ICompleter) {
+	s.languages[code] = newLanguage(completer, s.currentConfig().Dict.MaxChunks)
+	log.Debugf("Serving language %q", code)
+}
+
+// Languages returns the codes requests can select, the primary one included, sorted
+func (s *Server) Languages() []string {
+	codes := slices.Collect(maps.Keys(s.languages))
+	return slices.Sorted(slices.Values(append(codes, s.currentConfig().Dict.Language)))
+}
+
+// languageFor returns the dictionary a request's lang selects.
+// An empty code, or the primary language's, selects the primary dictionary.
+func (s *Server) languageFor(code string) (*language, error) {
+	if code == "" || code == s.currentConfig().Dict.Language {
+		return &language{completer: s.completer, chunkLoader: s.chunkLoader, runtimeLoader: s.runtimeLoader}, nil
+	}
+	if lang, ok := s.languages[code]; ok {
+		return lang, nil
+	}
+	return nil, fmt.Errorf("unknown language: %q", code)
+}
+
+// isPrimary reports whether lang is the dictionary named by dict.language
+func (lang *language) isPrimary(s *Server) bool {
+	return lang.completer == s.completer
+}
+
+// eachLanguage calls fn with the primary dictionary and every added language
+func (s *Server) eachLanguage(fn func(lang *language)) {
+	primary, _ := s.languageFor("")
+	fn(primary)
+	for _, lang := range s.languages {
+		fn(lang)
+	}
+}
@@ -0,0 +1,113 @@
+package server
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/bastiangx/wordserve/pkg/config"
+	completion "github.com/bastiangx/wordserve/pkg/suggest"
+)
+
+// newLanguageServer serves an English primary dictionary and a German one
+func newLanguageServer(t *testing.T) *Server {
+	t.Helper()
+	english := completion.NewCompleter()
+	english.AddWord("hello", 900)
+	english.AddWord("help", 800)
+	german := completion.NewCompleter()
+	german.AddWord("haus", 900)
+	german.AddWord("hallo", 800)
+
+	s := NewServer(english, config.DefaultConfig(), "")
+	s.AddLanguage("de", german)
+	return s
+}
+
+// suggestedWords returns the words of a completion response, in order
+func suggestedWords(t *testing.T, response map[string]any) []string {
+	t.Helper()
+	suggestions, ok := response["s"].([]any)
+	if !ok {
+		t.Fatalf("response %v has no suggestions", response)
+	}
+	var words []string
+	for _, suggestion := range suggestions {
+		words = append(words, suggestion.(map[string]any)["w"].(string))
+	}
+	return words
+}
+
+func TestCompletionByLanguage(t *testing.T) {
+	s := newLanguageServer(t)
+	responses := serve(t, s,
+		map[string]any{"id": "en", "p": "h", "l": 5},
+		map[string]any{"id": "de", "p": "h", "l": 5, "lang": "de"},
+		map[string]any{"id": "fr", "p": "h", "l": 5, "lang": "fr"},
+	)
+	if len(responses) != 3 {
+		t.Fatalf("got %d responses, want 3", len(responses))
+	}
+	if got, want := suggestedWords(t, responses[0]), []string{"hello", "help"}; !slices.Equal(got, want) {
+		t.Errorf("primary language = %v, want %v", got, want)
+	}
+	if got, want := suggestedWords(t, responses[1]), []string{"haus", "hallo"}; !slices.Equal(got, want) {
+		t.Errorf("lang de = %v, want %v", got, want)
+	}
+	if _, ok := responses[2]["e"]; !ok {
+		t.Errorf("unknown lang = %v, want an error", responses[2])
+	}
+}
+
+func TestWordActionsByLanguage(t *testing.T) {
+	s := newLanguageServer(t)
+	responses := serve(t, s,
+		map[string]any{"id": "add", "action": "add_word", "word": "hund", "freq": 1000, "lang": "de"},
+		map[string]any{"id": "remove", "action": "remove_word", "word": "haus", "lang": "de"},
+		map[string]any{"id": "ban", "action": "blacklist_add", "word": "hallo", "lang": "de"},
+		map[string]any{"id": "de", "p": "h", "l": 5, "lang": "de"},
+		map[string]any{"id": "en", "p": "h", "l": 5},
+		map[string]any{"id": "next", "action": "predict_next", "prev": "der", "p": "h", "l": 5, "lang": "de"},
+	)
+	if len(responses) != 6 {
+		t.Fatalf("got %d responses, want 6", len(responses))
+	}
+	for _, response := range responses[:3] {
+		if response["status"] != "ok" {
+			t.Errorf("response %v, want ok", response)
+		}
+	}
+	if got, want := suggestedWords(t, responses[3]), []string{"hund"}; !slices.Equal(got, want) {
+		t.Errorf("lang de after the word actions = %v, want %v", got, want)
+	}
+	if got, want := suggestedWords(t, responses[4]), []string{"hello", "help"}; !slices.Equal(got, want) {
+		t.Errorf("primary language after the word actions = %v, want %v", got, want)
+	}
+	if got, want := suggestedWords(t, responses[5]), []string{"hund"}; !slices.Equal(got, want) {
+		t.Errorf("predict_next with lang de = %v, want %v", got, want)
+	}
+}
+
+func TestWordActionsRejectUnknownLanguage(t *testing.T) {
+	s := newLanguageServer(t)
+	responses := serve(t, s,
+		map[string]any{"id": "add", "action": "add_word", "word": "bonjour", "freq": 1000, "lang": "fr"},
+		map[string]any{"id": "remove", "action": "remove_word", "word": "hello", "lang": "fr"},
+		map[string]any{"id": "ban", "action": "blacklist_add", "word": "hello", "lang": "fr"},
+		map[string]any{"id": "next", "action": "predict_next", "prev": "le", "p": "h", "lang": "fr"},
+		map[string]any{"id": "en", "p": "h", "l": 5},
+	)
+	if len(responses) != 5 {
+		t.Fatalf("got %d responses, want 5", len(responses))
+	}
+	for _, response := range responses[:3] {
+		if response["status"] != "error" {
+			t.Errorf("response %v, want an unknown language error", response)
+		}
+	}
+	if _, ok := responses[3]["e"]; !ok {
+		t.Errorf("predict_next with unknown lang = %v, want an error", responses[3])
+	}
+	if got, want := suggestedWords(t, responses[4]), []string{"hello", "help"}; !slices.Equal(got, want) {
+		t.Errorf("primary language = %v, want %v unchanged", got, want)
+	}
+}
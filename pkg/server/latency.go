@@ -0,0 +1,59 @@
+package server
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// latencyStats accumulates completion durations over the server's lifetime.
+// It is lock-free, so recording costs a few atomic ops on the completion path.
+type latencyStats struct {
+	count   atomic.Int64
+	totalUs atomic.Int64
+	minUs   atomic.Int64 // shortest duration plus one, 0 before the first completion
+	maxUs   atomic.Int64
+}
+
+// record adds one completion's duration
+func (l *latencyStats) record(elapsed time.Duration) {
+	us := elapsed.Microseconds()
+	l.count.Add(1)
+	l.totalUs.Add(us)
+	for {
+		current := l.minUs.Load()
+		if (current != 0 && us+1 >= current) || l.minUs.CompareAndSwap(current, us+1) {
+			break
+		}
+	}
+	for {
+		current := l.maxUs.Load()
+		if us <= current || l.maxUs.CompareAndSwap(current, us) {
+			break
+		}
+	}
+}
+
+// snapshot returns the stats so far, all 0 before the first completion.
+// The fields are read one by one, so a completion recorded meanwhile may
+// show in some of them only.
+func (l *latencyStats) snapshot() LatencyResponse {
+	snapshot := LatencyResponse{
+		Count: l.count.Load(),
+		MaxUs: l.maxUs.Load(),
+	}
+	if snapshot.Count > 0 {
+		snapshot.AvgUs = l.totalUs.Load() / snapshot.Count
+	}
+	if minUs := l.minUs.Load(); minUs > 0 {
+		snapshot.MinUs = minUs - 1
+	}
+	return snapshot
+}
+
+// processMetricsRequest answers the "metrics" action with the completion latency stats
+func (s *Server) processMetricsRequest(rawRequest map[string]any) error {
+	response := s.latency.snapshot()
+	response.ID, _ = rawRequest["id"].(string)
+	response.Status = "ok"
+	return s.sendResponse(&response)
+}
@@ -0,0 +1,77 @@
+package server
+
+import (
+	"testing"
+	"time"
+
+	"github.com/bastiangx/wordserve/pkg/config"
+	completion "github.com/bastiangx/wordserve/pkg/suggest"
+)
+
+func TestLatencyStats(t *testing.T) {
+	var stats latencyStats
+	if got := stats.snapshot(); got != (LatencyResponse{}) {
+		t.Errorf("snapshot before any completion = %+v, want zeros", got)
+	}
+	for _, elapsed := range []time.Duration{30 * time.Microsecond, 0, 90 * time.Microsecond} {
+		stats.record(elapsed)
+	}
+	want := LatencyResponse{Count: 3, AvgUs: 40, MinUs: 0, MaxUs: 90}
+	if got := stats.snapshot(); got != want {
+		t.Errorf("snapshot = %+v, want %+v", got, want)
+	}
+}
+
+func TestMetricsCountsCompletions(t *testing.T) {
+	completer := completion.NewCompleter()
+	completer.AddWord("hello", 900)
+	completer.AddWord("help", 800)
+	s := NewServer(completer, config.DefaultConfig(), "")
+
+	requests := []map[string]any{{"id": "before", "action": "metrics"}}
+	for _, prefix := range []string{"he", "hel", "help", "xyz", "h"} {
+		requests = append(requests, map[string]any{"id": prefix, "p": prefix, "l": 5})
+	}
+	// Rejected before completing, so not counted
+	requests = append(requests, map[string]any{"id": "empty", "p": "", "l": 5})
+	requests = append(requests, map[string]any{"id": "after", "action": "metrics"})
+
+	responses := serve(t, s, requests...)
+	if len(responses) != len(requests) {
+		t.Fatalf("got %d responses, want %d", len(responses), len(requests))
+	}
+	if before := responses[0]; toInt64(before["count"]) != 0 {
+		t.Errorf("metrics before any completion = %v, want count 0", before)
+	}
+	after := responses[len(responses)-1]
+	if after["status"] != "ok" || toInt64(after["count"]) != 5 {
+		t.Fatalf("metrics after 5 completions = %v, want count 5", after)
+	}
+	minUs, avgUs, maxUs := toInt64(after["min_us"]), toInt64(after["avg_us"]), toInt64(after["max_us"])
+	if minUs > avgUs || avgUs > maxUs {
+		t.Errorf("min_us %d, avg_us %d, max_us %d, want min <= avg <= max", minUs, avgUs, maxUs)
+	}
+}
+
+// toInt64 converts a msgpack decoded integer, which comes back in the smallest type that fits
+func toInt64(v any) int64 {
+	switch n := v.(type) {
+	case int8:
+		return int64(n)
+	case int16:
+		return int64(n)
+	case int32:
+		return int64(n)
+	case int64:
+		return n
+	case uint8:
+		return int64(n)
+	case uint16:
+		return int64(n)
+	case uint32:
+		return int64(n)
+	case uint64:
+		return int64(n)
+	}
+	return -1
+}
@@ -0,0 +1,112 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"runtime"
+	"time"
+
+	"github.com/bastiangx/wordserve/pkg/config"
+	"github.com/bastiangx/wordserve/pkg/dictionary"
+)
+
+// metricsDump is what dump_metrics writes, one file to attach to an issue
+type metricsDump struct {
+	Time     time.Time       `json:"time"`
+	Requests int64           `json:"requests"`
+	Latency  LatencyResponse `json:"latency"`
+	Stats    map[string]int  `json:"stats,omitempty"`
+	Loader   *loaderDump     `json:"loader,omitempty"`
+	Config   *config.Config  `json:"config"`
+	Paths    pathsDump       `json:"paths"`
+	Runtime  runtimeDump     `json:"runtime"`
+}
+
+// loaderDump is the chunk loader state at the time of the dump
+type loaderDump struct {
+	LoadedChunks    []int                         `json:"loaded_chunks"`
+	AvailableChunks int                           `json:"available_chunks"`
+	LoadedWords     int                           `json:"loaded_words"`
+	EstimatedMemory int64                         `json:"estimated_memory_bytes"`
+	DictVersion     uint64                        `json:"dict_version"`
+	IsLoading       bool                          `json:"is_loading"`
+	Generations     []dictionary.GenerationStatus `json:"generations,omitempty"`
+}
+
+// pathsDump says where the server looks for its files and whether they are there
+type pathsDump struct {
+	ConfigPath    string `json:"config_path"`
+	ConfigExists  bool   `json:"config_exists"`
+	DataDir       string `json:"data_dir,omitempty"`
+	DataDirExists bool   `json:"data_dir_exists"`
+	UserWordsPath string `json:"user_words_path,omitempty"`
+}
+
+// runtimeDump has the process numbers that matter for memory and leak reports
+type runtimeDump struct {
+	GoVersion  string `json:"go_version"`
+	Goroutines int    `json:"goroutines"`
+	HeapAlloc  uint64 `json:"heap_alloc_bytes"`
+	HeapSys    uint64 `json:"heap_sys_bytes"`
+	NumGC      uint32 `json:"num_gc"`
+}
+
+// collectMetrics gathers the completer stats, loader state, config and paths
+func (s *Server) collectMetrics() *metricsDump {
+	cfg := s.currentConfig()
+	dump := &metricsDump{
+		Time:     time.Now(),
+		Requests: s.requestCount.Load(),
+		Latency:  s.latency.snapshot(),
+		Config:   cfg,
+		Paths: pathsDump{
+			ConfigPath:    config.GetActiveConfigPath(s.configPath),
+			UserWordsPath: cfg.Dict.UserWordsPath,
+		},
+	}
+	_, err := os.Stat(dump.Paths.ConfigPath)
+	dump.Paths.ConfigExists = err == nil
+
+	if completer, ok := s.completer.(interface{ Stats() map[string]int }); ok {
+		dump.Stats = completer.Stats()
+	}
+	if s.chunkLoader != nil {
+		stats := s.chunkLoader.GetStats()
+		dump.Loader = &loaderDump{
+			LoadedChunks:    s.chunkLoader.GetLoadedIDs(),
+			AvailableChunks: stats.AvailableChunks,
+			LoadedWords:     stats.LoadedWords,
+			EstimatedMemory: s.chunkLoader.EstimatedMemory(),
+			DictVersion:     s.chunkLoader.Version(),
+			IsLoading:       stats.IsLoading,
+			Generations:     s.chunkLoader.ListGenerations(),
+		}
+		dump.Paths.DataDir = s.chunkLoader.DirPath()
+		_, err := os.Stat(dump.Paths.DataDir)
+		dump.Paths.DataDirExists = err == nil
+	}
+
+	var mem runtime.MemStats
+	runtime.ReadMemStats(&mem)
+	dump.Runtime = runtimeDump{
+		GoVersion:  runtime.Version(),
+		Goroutines: runtime.NumGoroutine(),
+		HeapAlloc:  mem.HeapAlloc,
+		HeapSys:    mem.HeapSys,
+		NumGC:      mem.NumGC,
+	}
+	return dump
+}
+
+// dumpMetrics writes the collected metrics to path as indented JSON
+func (s *Server) dumpMetrics(path string) error {
+	if path == "" {
+		return errors.New("path is required")
+	}
+	data, err := json.MarshalIndent(s.collectMetrics(), "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0o644)
+}
@@ -0,0 +1,74 @@
+package server
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime/pprof"
+	"time"
+
+	"github.com/charmbracelet/log"
+)
+
+// cpuProfile is a CPU profile being recorded while one completion runs
+type cpuProfile struct {
+	buf bytes.Buffer
+}
+
+// startProfile starts the CPU profiler before a completion when
+// server.profile_threshold_ms is set, so a slow request is profiled as it runs.
+// It returns nil when profiling is off or another completion is being profiled,
+// the profiler being process wide. With several workers, the profile also
+// samples the requests running alongside.
+func (s *Server) startProfile() *cpuProfile {
+	if s.currentConfig().Server.ProfileThresholdMs <= 0 {
+		return nil
+	}
+	if !s.profiling.CompareAndSwap(false, true) {
+		return nil
+	}
+	profile := &cpuProfile{}
+	if err := pprof.StartCPUProfile(&profile.buf); err != nil {
+		s.profiling.Store(false)
+		log.Debugf("Not profiling completion: %v", err)
+		return nil
+	}
+	return profile
+}
+
+// finishProfile stops the profiler started by startProfile and writes the profile
+// to server.profile_dir if the completion took longer than server.profile_threshold_ms.
+// Faster completions' profiles are dropped. profile may be nil.
+func (s *Server) finishProfile(profile *cpuProfile, prefix string, elapsed time.Duration) {
+	if profile == nil {
+		return
+	}
+	pprof.StopCPUProfile()
+	s.profiling.Store(false)
+
+	cfg := s.currentConfig().Server
+	if elapsed < time.Duration(cfg.ProfileThresholdMs)*time.Millisecond {
+		return
+	}
+	path, err := writeProfile(cfg.ProfileDir, profile.buf.Bytes())
+	if err != nil {
+		log.Warnf("Failed to save profile of slow completion of %s: %v", s.redact(prefix), err)
+		return
+	}
+	log.Warnf("Slow completion of %s took %s, CPU profile written to %s", s.redact(prefix), elapsed, path)
+}
+
+// writeProfile writes a recorded profile to dir, the system temp dir if empty,
+// and returns its path
+func writeProfile(dir string, data []byte) (string, error) {
+	if dir == "" {
+		dir = os.TempDir()
+	}
+	// The file name leaves the prefix out, it may be private
+	path := filepath.Join(dir, fmt.Sprintf("wordserve-cpu-%d.pprof", time.Now().UnixNano()))
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		return "", err
+	}
+	return path, nil
+}
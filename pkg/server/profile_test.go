@@ -0,0 +1,70 @@
+package server
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/bastiangx/wordserve/pkg/config"
+	completion "github.com/bastiangx/wordserve/pkg/suggest"
+)
+
+// slowCompleter takes at least delay for each completion
+type slowCompleter struct {
+	*completion.Completer
+	delay time.Duration
+}
+
+func (c slowCompleter) CompleteWithContext(ctx context.Context, opts completion.CompletionOptions) ([]completion.Suggestion, error) {
+	// Busy, so the profiler has CPU time to sample
+	for deadline := time.Now().Add(c.delay); time.Now().Before(deadline); {
+	}
+	return c.Completer.CompleteWithContext(ctx, opts)
+}
+
+// profiles returns the CPU profiles written to dir
+func profiles(t *testing.T, dir string) []string {
+	t.Helper()
+	paths, err := filepath.Glob(filepath.Join(dir, "wordserve-cpu-*.pprof"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return paths
+}
+
+func TestSlowCompletionIsProfiled(t *testing.T) {
+	base := completion.NewCompleter()
+	base.AddWord("hello", 500)
+	cfg := config.DefaultConfig()
+	cfg.Server.ProfileThresholdMs = 20
+	cfg.Server.ProfileDir = t.TempDir()
+
+	fast := NewServer(base, cfg, "")
+	serve(t, fast, map[string]any{"id": "1", "p": "hel", "l": 5})
+	if paths := profiles(t, cfg.Server.ProfileDir); len(paths) != 0 {
+		t.Fatalf("fast completion left profiles %v, want none", paths)
+	}
+
+	slow := NewServer(slowCompleter{base, 50 * time.Millisecond}, cfg, "")
+	responses := serve(t, slow, map[string]any{"id": "2", "p": "hel", "l": 5})
+	if len(responses) != 1 || responses[0]["id"] != "2" || len(responses[0]["s"].([]any)) != 1 {
+		t.Fatalf("responses = %v, want the completion answered as usual", responses)
+	}
+	if paths := profiles(t, cfg.Server.ProfileDir); len(paths) != 1 {
+		t.Fatalf("slow completion left profiles %v, want one", paths)
+	}
+}
+
+func TestProfilingOffByDefault(t *testing.T) {
+	base := completion.NewCompleter()
+	base.AddWord("hello", 500)
+	cfg := config.DefaultConfig()
+	cfg.Server.ProfileDir = t.TempDir()
+
+	s := NewServer(slowCompleter{base, 30 * time.Millisecond}, cfg, "")
+	serve(t, s, map[string]any{"id": "1", "p": "hel", "l": 5})
+	if paths := profiles(t, cfg.Server.ProfileDir); len(paths) != 0 {
+		t.Errorf("profiles %v written with profile_threshold_ms unset", paths)
+	}
+}
@@ -0,0 +1,37 @@
+package server
+
+import (
+	"context"
+	"time"
+
+	"github.com/charmbracelet/log"
+)
+
+// readyQueueTimeout is the longest a completion is held waiting for the dictionary
+const readyQueueTimeout = 30 * time.Second
+
+// waitUntilReady holds a completion until the chunks queued at startup are loaded,
+// when server.queue_until_ready is on. Requests read meanwhile wait behind it, so
+// early clients get full results instead of empty ones. Once the dictionary is
+// ready, the wait timed out or Shutdown began, completions are never held again.
+func (s *Server) waitUntilReady() {
+	if s.chunkLoader == nil || s.ready.Load() || !s.currentConfig().Server.QueueUntilReady {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), readyQueueTimeout)
+	defer cancel()
+	go func() {
+		select {
+		case <-s.stopping:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+	start := time.Now()
+	if err := s.chunkLoader.WaitUntilReady(ctx); err != nil {
+		log.Warnf("Dictionary not ready after %s, answering with the words loaded so far: %v", time.Since(start).Round(time.Millisecond), err)
+	} else if waited := time.Since(start); waited > time.Millisecond {
+		log.Debugf("Held completion %s until the dictionary was ready", waited.Round(time.Millisecond))
+	}
+	s.ready.Store(true)
+}
@@ -3,12 +3,19 @@ package server
 
 import (
 	"bytes"
+	"context"
+	"crypto/sha256"
+	"errors"
 	"fmt"
 	"io"
 	"os"
+	"slices"
 	"strconv"
+	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
+	"unicode/utf8"
 
 	"github.com/bastiangx/wordserve/internal/utils"
 	"github.com/bastiangx/wordserve/pkg/config"
@@ -22,13 +29,29 @@ import (
 type Server struct {
 	completer     completion.ICompleter
 	config        *config.Config
+	patterns      *utils.InputPatterns
 	configPath    string
 	runtimeLoader *dictionary.RuntimeLoader
-	decoder       *msgpack.Decoder
+	chunkLoader   *dictionary.Loader
+	jobs          *jobRegistry
+	languages     map[string]*language // added with AddLanguage, by code
+	out           io.Writer            // the current session's output, guarded by writeMutex
+	sessionMutex  sync.Mutex
 	buffer        *bytes.Buffer
 	encoder       *msgpack.Encoder
 	writeMutex    sync.Mutex
-	requestCount  int64
+	configMutex   sync.RWMutex
+	requestCount  atomic.Int64
+	latency       latencyStats
+	configWatched atomic.Bool
+	profiling     atomic.Bool
+	ready         atomic.Bool
+	done          chan struct{}
+	stopOnce      sync.Once
+	drainMutex    sync.Mutex
+	draining      bool
+	stopping      chan struct{} // closed when Shutdown begins
+	inflight      sync.WaitGroup
 }
 
 // NewServer creates a server instance with the given completer and configuration
@@ -37,71 +60,208 @@ func NewServer(completer completion.ICompleter, cfg *config.Config, configPath s
 	server := &Server{
 		completer:  completer,
 		config:     cfg,
+		patterns:   compilePatterns(cfg),
 		configPath: configPath,
 		buffer:     buffer,
 		encoder:    msgpack.NewEncoder(buffer),
+		jobs:       newJobRegistry(),
+		languages:  make(map[string]*language),
+		done:       make(chan struct{}),
+		stopping:   make(chan struct{}),
 	}
-	server.decoder = msgpack.NewDecoder(os.Stdin)
+	server.out = os.Stdout
 
-	if lazyCompleter, ok := completer.(*completion.Completer); ok {
-		if chunkLoader := lazyCompleter.GetChunkLoader(); chunkLoader != nil {
-			server.runtimeLoader = dictionary.NewRuntimeLoader(chunkLoader)
-		}
-	}
+	primary := newLanguage(completer, cfg.Dict.MaxChunks)
+	server.chunkLoader = primary.chunkLoader
+	server.runtimeLoader = primary.runtimeLoader
 	return server
 }
 
 // reloadConfig refreshes configuration from the TOML file
 func (s *Server) reloadConfig() error {
-	newConfig, err := config.LoadConfig(s.configPath)
+	newConfig, err := config.ReloadConfig(s.configPath)
 	if err != nil {
 		log.Warnf("Failed to reload config, keeping current: %v", err)
 		return err
 	}
+	patterns := compilePatterns(newConfig)
+	s.configMutex.Lock()
 	s.config = newConfig
+	s.patterns = patterns
+	s.configMutex.Unlock()
+	s.applyLoaderConfig(newConfig)
 	log.Debugf("Config reloaded from: %s", s.configPath)
 	return nil
 }
 
-// Start begins the main request processing loop
-func (s *Server) Start() error {
-	log.Debug("Starting server")
-	for {
-		if err := s.processCompletionRequest(); err != nil {
-			if err == io.EOF {
-				log.Debug("Client disconnected")
-				return nil
-			}
-			continue
+// applyLoaderConfig passes the dictionary limits and frequency thresholds of a
+// new config on to the loaders and completers
+func (s *Server) applyLoaderConfig(cfg *config.Config) {
+	thresholds := completion.FrequencyThresholds{Min: cfg.Dict.MinFreqThreshold, ShortPrefix: cfg.Dict.MinFreqShortPrefix}
+	s.eachLanguage(func(lang *language) {
+		if completer, ok := lang.completer.(interface {
+			SetFrequencyThresholds(thresholds completion.FrequencyThresholds)
+		}); ok {
+			completer.SetFrequencyThresholds(thresholds)
+		}
+		if lang.runtimeLoader != nil {
+			lang.runtimeLoader.SetMaxChunks(cfg.Dict.MaxChunks)
+		}
+		if lang.chunkLoader != nil {
+			lang.chunkLoader.SetMaxMemory(int64(cfg.Dict.MaxMemoryBytes))
 		}
+	})
+}
+
+// currentConfig returns the config in effect, safe to call from any worker
+func (s *Server) currentConfig() *config.Config {
+	s.configMutex.RLock()
+	defer s.configMutex.RUnlock()
+	return s.config
+}
+
+// currentPatterns returns the compiled allow/deny patterns of the config in effect
+func (s *Server) currentPatterns() *utils.InputPatterns {
+	s.configMutex.RLock()
+	defer s.configMutex.RUnlock()
+	return s.patterns
+}
+
+// compilePatterns compiles server.allow_pattern and server.deny_pattern.
+// An invalid pattern is logged and both are ignored, rather than rejecting every request.
+func compilePatterns(cfg *config.Config) *utils.InputPatterns {
+	patterns, err := utils.CompileInputPatterns(cfg.Server.AllowPattern, cfg.Server.DenyPattern)
+	if err != nil {
+		log.Warnf("Ignoring completion patterns: %v", err)
+		return nil
 	}
+	return patterns
 }
 
-// processCompletionRequest handles a single incoming request
-func (s *Server) processCompletionRequest() error {
-	s.requestCount++
-	if s.requestCount%100 == 0 {
-		s.reloadConfig()
+// redact returns user typed text for logging. With server.privacy_mode on it only
+// gives the length and a short hash, enough to tell requests apart but not what was typed.
+func (s *Server) redact(text string) string {
+	if !s.currentConfig().Server.PrivacyMode {
+		return fmt.Sprintf("'%s'", text)
 	}
+	sum := sha256.Sum256([]byte(text))
+	return fmt.Sprintf("<%d chars %x>", utf8.RuneCountInString(text), sum[:4])
+}
 
-	if s.requestCount%50 == 0 {
-		if completer, ok := s.completer.(interface{ ForceCleanup() }); ok {
-			completer.ForceCleanup()
+// watchDictionary starts polling the data dir for rewritten chunk files
+// when dict.watch_interval is set. It runs until the chunk loader stops.
+func (s *Server) watchDictionary() {
+	interval := s.currentConfig().Dict.WatchInterval
+	if interval <= 0 || s.chunkLoader == nil {
+		return
+	}
+	log.Debugf("Watching dictionary files every %ds", interval)
+	go s.chunkLoader.WatchChunks(context.Background(), time.Duration(interval)*time.Second, func(chunkIDs []int) {
+		if completer, ok := s.completer.(interface{ InvalidateFallbackCache() }); ok {
+			completer.InvalidateFallbackCache()
 		}
+	})
+}
+
+// Start begins the main request processing loop on stdin and stdout.
+// It returns when the client disconnects or sends a shutdown action,
+// after the chunk loader has been stopped.
+//
+// The client's session and the server's lifetime are the same here, as the
+// server is spawned per client. ServeSession serves one session without
+// shutting down, for transports where clients come and go.
+func (s *Server) Start() error {
+	log.Debug("Starting server")
+	s.watchConfig()
+	s.watchDictionary()
+	err := s.ServeSession(os.Stdin, os.Stdout)
+	s.Shutdown()
+	return err
+}
+
+// isShutdownRequest reports whether the request asks the server to exit
+func isShutdownRequest(rawRequest map[string]any) bool {
+	action, _ := rawRequest["action"].(string)
+	return action == "shutdown"
+}
+
+// shutdown stops the server in response to a shutdown action.
+// It answers earlier requests and stops the loader through Shutdown, then
+// acknowledges, so the client knows it is safe to exit.
+func (s *Server) shutdown(rawRequest map[string]any) error {
+	id, _ := rawRequest["id"].(string)
+	log.Debug("Shutdown requested")
+	s.Shutdown()
+	return s.sendResponse(&ConfigResponse{ID: id, Status: "ok"})
+}
+
+// countRequest counts an incoming request and runs the periodic cleanup,
+// and the periodic config reload when the config file isn't being watched
+func (s *Server) countRequest() {
+	requestCount := s.requestCount.Add(1)
+	if !s.configWatched.Load() && requestCount%configReloadEvery == 0 {
+		s.reloadConfig()
 	}
 
+	if requestCount%50 == 0 {
+		s.eachLanguage(func(lang *language) {
+			if completer, ok := lang.completer.(interface{ ForceCleanup() }); ok {
+				completer.ForceCleanup()
+			}
+		})
+	}
+}
+
+// stop releases background work, like the config watcher and the chunk loader goroutine
+func (s *Server) stop() {
+	s.stopOnce.Do(func() { close(s.done) })
+	s.eachLanguage(func(lang *language) {
+		if completer, ok := lang.completer.(interface{ Stop() }); ok {
+			completer.Stop()
+		}
+	})
+}
+
+// readRequest decodes the next request, running periodic upkeep first
+func (s *Server) readRequest(decoder *msgpack.Decoder) (map[string]any, error) {
+	s.countRequest()
+
 	var rawRequest map[string]any
-	if err := s.decoder.Decode(&rawRequest); err != nil {
+	if err := decoder.Decode(&rawRequest); err != nil {
 		log.Debugf("Decode error: %v", err)
-		return err
+		return nil, err
 	}
+	return rawRequest, nil
+}
+
+// handleRequest dispatches a decoded request to its handler
+func (s *Server) handleRequest(rawRequest map[string]any) error {
 
 	if action, exists := rawRequest["action"]; exists {
 		actionStr := action.(string)
 		// Check if it's a config management action
-		if actionStr == "rebuild_config" || actionStr == "get_config_path" {
+		if actionStr == "rebuild_config" || actionStr == "get_config_path" || actionStr == "dump_metrics" || actionStr == "set_config" ||
+			actionStr == "get_config_json" || actionStr == "set_config_json" {
 			return s.processConfigRequest(rawRequest, actionStr)
 		}
+		if actionStr == "batch_complete" {
+			return s.processBatchRequest(rawRequest)
+		}
+		if actionStr == "predict_next" {
+			return s.processPredictRequest(rawRequest)
+		}
+		if actionStr == "metrics" {
+			return s.processMetricsRequest(rawRequest)
+		}
+		if actionStr == "count" {
+			return s.processCountRequest(rawRequest)
+		}
+		if actionStr == "add_word" || actionStr == "remove_word" {
+			return s.processWordRequest(rawRequest, actionStr)
+		}
+		if actionStr == "blacklist_add" || actionStr == "blacklist_remove" {
+			return s.processBlacklistRequest(rawRequest, actionStr)
+		}
 		// Otherwise, it's a dictionary request
 		return s.processDictionaryRequest(rawRequest, actionStr)
 	}
@@ -133,11 +293,11 @@ func (s *Server) sendResponse(response any) error {
 		return fmt.Errorf("failed to encode response: %w", err)
 	}
 
-	if _, err := os.Stdout.Write(s.buffer.Bytes()); err != nil {
+	if _, err := s.out.Write(s.buffer.Bytes()); err != nil {
 		return fmt.Errorf("failed to write response: %w", err)
 	}
 
-	os.Stdout.Sync()
+	syncOutput(s.out)
 	return nil
 }
 
@@ -182,6 +342,68 @@ func (s *Server) processConfigRequest(rawRequest map[string]any, action string)
 			ConfigPath: configPath,
 		})
 
+	case "set_config":
+		settings, err := s.setConfig(rawRequest)
+		if err != nil {
+			return s.sendResponse(&ConfigResponse{
+				ID:     id,
+				Status: "error",
+				Error:  err.Error(),
+			})
+		}
+		return s.sendResponse(&ConfigResponse{
+			ID:       id,
+			Status:   "ok",
+			Settings: settings,
+		})
+
+	case "get_config_json":
+		data, err := s.currentConfig().ToJSON()
+		if err != nil {
+			return s.sendResponse(&ConfigResponse{
+				ID:     id,
+				Status: "error",
+				Error:  fmt.Sprintf("Failed to encode config: %v", err),
+			})
+		}
+		return s.sendResponse(&ConfigResponse{
+			ID:     id,
+			Status: "ok",
+			Config: data,
+		})
+
+	case "set_config_json":
+		data, _ := rawRequest["config"].(string)
+		updated, err := s.setConfigJSON(data)
+		if err != nil {
+			return s.sendResponse(&ConfigResponse{
+				ID:     id,
+				Status: "error",
+				Error:  err.Error(),
+			})
+		}
+		return s.sendResponse(&ConfigResponse{
+			ID:     id,
+			Status: "ok",
+			Config: updated,
+		})
+
+	case "dump_metrics":
+		path, _ := rawRequest["path"].(string)
+		if err := s.dumpMetrics(path); err != nil {
+			return s.sendResponse(&ConfigResponse{
+				ID:     id,
+				Status: "error",
+				Error:  fmt.Sprintf("Failed to dump metrics: %v", err),
+			})
+		}
+		log.Infof("Metrics written to %s", path)
+		return s.sendResponse(&ConfigResponse{
+			ID:     id,
+			Status: "ok",
+			Path:   path,
+		})
+
 	default:
 		return s.sendResponse(&ConfigResponse{
 			ID:     id,
@@ -191,6 +413,184 @@ func (s *Server) processConfigRequest(rawRequest map[string]any, action string)
 	}
 }
 
+// setConfig applies the server settings present in a set_config request,
+// saves them to the config file and puts them in effect for the next request.
+// Nothing changes if any value is invalid or the file can't be written.
+func (s *Server) setConfig(rawRequest map[string]any) (*ServerSettings, error) {
+	if s.configPath == "" {
+		return nil, errors.New("no config file loaded, settings can't be saved")
+	}
+	var maxLimit, minPrefix, maxPrefix *int
+	for key, target := range map[string]**int{"max_limit": &maxLimit, "min_prefix": &minPrefix, "max_prefix": &maxPrefix} {
+		rawValue, exists := rawRequest[key]
+		if !exists {
+			continue
+		}
+		value, err := parseInt(rawValue)
+		if err != nil {
+			return nil, fmt.Errorf("%s must be an integer", key)
+		}
+		*target = &value
+	}
+	var enableFilter *bool
+	if rawValue, exists := rawRequest["enable_filter"]; exists {
+		value, ok := rawValue.(bool)
+		if !ok {
+			return nil, errors.New("enable_filter must be a boolean")
+		}
+		enableFilter = &value
+	}
+
+	s.configMutex.Lock()
+	defer s.configMutex.Unlock()
+	updated := *s.config
+	server := &updated.Server
+	if maxLimit != nil {
+		server.MaxLimit = *maxLimit
+	}
+	if minPrefix != nil {
+		server.MinPrefix = *minPrefix
+	}
+	if maxPrefix != nil {
+		server.MaxPrefix = *maxPrefix
+	}
+	if enableFilter != nil {
+		server.EnableFilter = *enableFilter
+	}
+	if err := updated.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid config: %v", err)
+	}
+	if err := updated.Update(s.configPath, maxLimit, minPrefix, maxPrefix, enableFilter); err != nil {
+		return nil, fmt.Errorf("failed to save config: %v", err)
+	}
+	s.config = &updated
+	log.Debugf("Server settings updated: max_limit=%d min_prefix=%d max_prefix=%d enable_filter=%t",
+		server.MaxLimit, server.MinPrefix, server.MaxPrefix, server.EnableFilter)
+	return &ServerSettings{
+		MaxLimit:     server.MaxLimit,
+		MinPrefix:    server.MinPrefix,
+		MaxPrefix:    server.MaxPrefix,
+		EnableFilter: server.EnableFilter,
+	}, nil
+}
+
+// setConfigJSON applies a JSON config from a set_config_json request, saves it
+// to the config file and puts it in effect, returning the resulting config as JSON.
+// Nothing changes if the JSON or any value is invalid or the file can't be written.
+func (s *Server) setConfigJSON(data string) (string, error) {
+	if s.configPath == "" {
+		return "", errors.New("no config file loaded, settings can't be saved")
+	}
+	if data == "" {
+		return "", errors.New("config is required")
+	}
+
+	s.configMutex.Lock()
+	updated, err := s.config.WithJSON(data)
+	if err != nil {
+		s.configMutex.Unlock()
+		return "", err
+	}
+	if err := config.SaveConfig(updated, s.configPath); err != nil {
+		s.configMutex.Unlock()
+		return "", fmt.Errorf("failed to save config: %v", err)
+	}
+	s.config = updated
+	s.patterns = compilePatterns(updated)
+	s.configMutex.Unlock()
+
+	s.applyLoaderConfig(updated)
+	log.Debugf("Config updated from JSON and saved to: %s", s.configPath)
+	return updated.ToJSON()
+}
+
+// processWordRequest adds or removes a single word at runtime
+func (s *Server) processWordRequest(rawRequest map[string]any, action string) error {
+	var request WordRequest
+	request.ID, _ = rawRequest["id"].(string)
+	request.Action = action
+	rawWord, _ := rawRequest["word"].(string)
+	request.Word = strings.ToLower(strings.TrimSpace(rawWord))
+	log.Debugf("Processing word request: action=%s, word=%s", action, s.redact(request.Word))
+
+	if request.Word == "" {
+		return s.sendResponse(&ConfigResponse{ID: request.ID, Status: "error", Error: "word required"})
+	}
+	request.Lang, _ = rawRequest["lang"].(string)
+	lang, err := s.languageFor(request.Lang)
+	if err != nil {
+		return s.sendResponse(&ConfigResponse{ID: request.ID, Status: "error", Error: err.Error()})
+	}
+
+	switch action {
+	case "add_word":
+		freq, err := parseInt(rawRequest["freq"])
+		if err != nil || freq <= 0 {
+			return s.sendResponse(&ConfigResponse{ID: request.ID, Status: "error", Error: "freq must be a positive integer"})
+		}
+		request.Freq = freq
+		lang.completer.AddWord(request.Word, request.Freq)
+		if completer, ok := lang.completer.(interface{ InvalidateFallbackCache() }); ok {
+			completer.InvalidateFallbackCache()
+		}
+	case "remove_word":
+		remover, ok := lang.completer.(interface{ RemoveWord(word string) bool })
+		if !ok {
+			return s.sendResponse(&ConfigResponse{ID: request.ID, Status: "error", Error: "completer does not support removing words"})
+		}
+		if !remover.RemoveWord(request.Word) {
+			return s.sendResponse(&ConfigResponse{ID: request.ID, Status: "error", Error: fmt.Sprintf("word not found: %s", request.Word)})
+		}
+	}
+	// dict.user_words_path holds the primary language's words only
+	if path := s.currentConfig().Dict.UserWordsPath; path != "" && lang.isPrimary(s) && lang.chunkLoader != nil {
+		if err := lang.chunkLoader.SaveUserWords(path); err != nil {
+			log.Errorf("Failed to persist user words: %v", err)
+			return s.sendResponse(&ConfigResponse{ID: request.ID, Status: "error", Error: fmt.Sprintf("word updated but not saved: %v", err)})
+		}
+	}
+	return s.sendResponse(&ConfigResponse{ID: request.ID, Status: "ok"})
+}
+
+// processBlacklistRequest adds a word to the blacklist or removes it, saving the
+// list to dict.blacklist_path when one is set
+func (s *Server) processBlacklistRequest(rawRequest map[string]any, action string) error {
+	id, _ := rawRequest["id"].(string)
+	rawWord, _ := rawRequest["word"].(string)
+	word := strings.ToLower(strings.TrimSpace(rawWord))
+	log.Debugf("Processing blacklist request: action=%s, word=%s", action, s.redact(word))
+
+	if word == "" {
+		return s.sendResponse(&ConfigResponse{ID: id, Status: "error", Error: "word required"})
+	}
+	code, _ := rawRequest["lang"].(string)
+	lang, err := s.languageFor(code)
+	if err != nil {
+		return s.sendResponse(&ConfigResponse{ID: id, Status: "error", Error: err.Error()})
+	}
+	completer, ok := lang.completer.(interface{ Blacklist() *completion.Blacklist })
+	if !ok {
+		return s.sendResponse(&ConfigResponse{ID: id, Status: "error", Error: "completer does not support a blacklist"})
+	}
+	blacklist := completer.Blacklist()
+	switch action {
+	case "blacklist_add":
+		blacklist.Add(word)
+	case "blacklist_remove":
+		if !blacklist.Remove(word) {
+			return s.sendResponse(&ConfigResponse{ID: id, Status: "error", Error: fmt.Sprintf("word not blacklisted: %s", word)})
+		}
+	}
+	// dict.blacklist_path holds the primary language's blacklist only
+	if path := s.currentConfig().Dict.BlacklistPath; path != "" && lang.isPrimary(s) {
+		if err := blacklist.Save(path); err != nil {
+			log.Errorf("Failed to persist blacklist: %v", err)
+			return s.sendResponse(&ConfigResponse{ID: id, Status: "error", Error: fmt.Sprintf("blacklist updated but not saved: %v", err)})
+		}
+	}
+	return s.sendResponse(&ConfigResponse{ID: id, Status: "ok"})
+}
+
 // processDictionaryRequest handles dictionary management operations
 func (s *Server) processDictionaryRequest(rawRequest map[string]any, action string) error {
 	log.Debugf("Processing dictionary request: action=%s", action)
@@ -200,7 +600,16 @@ func (s *Server) processDictionaryRequest(rawRequest map[string]any, action stri
 		id = rawID.(string)
 	}
 
-	if s.runtimeLoader == nil {
+	code, _ := rawRequest["lang"].(string)
+	lang, err := s.languageFor(code)
+	if err != nil {
+		return s.sendResponse(&DictionaryResponse{
+			ID:     id,
+			Status: "error",
+			Error:  err.Error(),
+		})
+	}
+	if lang.runtimeLoader == nil {
 		log.Debug("Dictionary management not available - runtimeLoader is nil")
 		return s.sendResponse(&DictionaryResponse{
 			ID:     id,
@@ -210,24 +619,26 @@ func (s *Server) processDictionaryRequest(rawRequest map[string]any, action stri
 	}
 	switch action {
 	case "get_info":
-		stats := s.completer.Stats()
-		availableChunks, err := s.runtimeLoader.GetAvailableChunkCount()
-		if err != nil {
+		stats := lang.completer.Stats()
+		if _, err := lang.runtimeLoader.GetAvailableChunkCount(); err != nil {
 			return s.sendResponse(&DictionaryResponse{
 				ID:     id,
 				Status: "error",
 				Error:  err.Error(),
 			})
 		}
+		target, actual, available := lang.runtimeLoader.Status()
 		return s.sendResponse(&DictionaryResponse{
 			ID:              id,
 			Status:          "ok",
-			CurrentChunks:   stats["loadedChunks"],
-			AvailableChunks: availableChunks,
+			CurrentChunks:   actual,
+			TargetChunks:    target,
+			AvailableChunks: available,
+			Version:         stats["dictVersion"],
 		})
 
 	case "get_options":
-		options, err := s.runtimeLoader.GetDictionarySizeOptions()
+		options, err := lang.runtimeLoader.GetDictionarySizeOptions()
 		if err != nil {
 			return s.sendResponse(&DictionaryResponse{
 				ID:     id,
@@ -268,21 +679,76 @@ func (s *Server) processDictionaryRequest(rawRequest map[string]any, action stri
 			})
 		}
 
-		if err := s.runtimeLoader.SetDictionarySize(count); err != nil {
+		// Generating chunks can take a while, so run it as a job the client can poll
+		if lang.runtimeLoader.NeedsGeneration(count) {
+			jobID := s.jobs.start(count, func() error {
+				return lang.runtimeLoader.SetDictionarySize(count)
+			})
+			log.Debugf("set_size needs chunk generation, started %s", jobID)
+			return s.sendResponse(&DictionaryResponse{
+				ID:       id,
+				Status:   "pending",
+				Job:      jobID,
+				JobState: jobRunning,
+			})
+		}
+		return s.sendResponse(setDictionarySize(lang.runtimeLoader, id, count))
+
+	case "job_status":
+		jobID, _ := rawRequest["job"].(string)
+		j, exists := s.jobs.get(jobID)
+		if !exists {
+			return s.sendResponse(&DictionaryResponse{
+				ID:     id,
+				Status: "error",
+				Error:  fmt.Sprintf("unknown job: %q", jobID),
+			})
+		}
+		return s.sendResponse(&DictionaryResponse{
+			ID:       id,
+			Status:   "ok",
+			Job:      j.id,
+			JobState: j.state,
+			Error:    j.err,
+		})
+
+	case "list_generations":
+		return s.sendResponse(&DictionaryResponse{
+			ID:          id,
+			Status:      "ok",
+			Generations: generationInfos(lang.chunkLoader.ListGenerations()),
+		})
+
+	case "cancel_generation":
+		generationID, err := parseInt(rawRequest["generation"])
+		if err != nil {
+			return s.sendResponse(&DictionaryResponse{
+				ID:     id,
+				Status: "error",
+				Error:  "generation id required for cancel_generation action",
+			})
+		}
+		if err := lang.chunkLoader.CancelGeneration(generationID); err != nil {
 			return s.sendResponse(&DictionaryResponse{
 				ID:     id,
 				Status: "error",
 				Error:  err.Error(),
 			})
 		}
+		return s.sendResponse(&DictionaryResponse{
+			ID:     id,
+			Status: "ok",
+		})
 
+	case "get_chunk_stats":
 		return s.sendResponse(&DictionaryResponse{
 			ID:     id,
 			Status: "ok",
+			Chunks: chunkStatInfos(lang.chunkLoader.GetChunkStats()),
 		})
 
 	case "get_chunk_count":
-		availableChunks, err := s.runtimeLoader.GetAvailableChunkCount()
+		availableChunks, err := lang.runtimeLoader.GetAvailableChunkCount()
 		if err != nil {
 			return s.sendResponse(&DictionaryResponse{
 				ID:     id,
@@ -306,13 +772,86 @@ func (s *Server) processDictionaryRequest(rawRequest map[string]any, action stri
 	}
 }
 
+// setDictionarySize resizes a dictionary and builds the response for it
+func setDictionarySize(runtimeLoader *dictionary.RuntimeLoader, id string, count int) *DictionaryResponse {
+	if err := runtimeLoader.SetDictionarySize(count); err != nil {
+		return &DictionaryResponse{
+			ID:     id,
+			Status: "error",
+			Error:  err.Error(),
+		}
+	}
+	return &DictionaryResponse{
+		ID:     id,
+		Status: "ok",
+	}
+}
+
+// chunkStatInfos converts loader chunk stats to their wire format
+func chunkStatInfos(stats []dictionary.ChunkStat) []ChunkStatInfo {
+	infos := make([]ChunkStatInfo, len(stats))
+	for i, stat := range stats {
+		infos[i] = ChunkStatInfo{
+			ID:        stat.ID,
+			FileWords: stat.FileWords,
+			Words:     stat.WordCount,
+			Loaded:    stat.Loaded,
+			Partial:   stat.Partial,
+			MinScore:  stat.MinScore,
+			MaxScore:  stat.MaxScore,
+		}
+	}
+	return infos
+}
+
+// generationInfos converts loader generation statuses to their wire format
+func generationInfos(statuses []dictionary.GenerationStatus) []GenerationInfo {
+	infos := make([]GenerationInfo, len(statuses))
+	for i, status := range statuses {
+		end := status.FinishedAt
+		if end.IsZero() {
+			end = time.Now()
+		}
+		infos[i] = GenerationInfo{
+			ID:           status.ID,
+			TargetChunks: status.TargetChunks,
+			State:        string(status.State),
+			Stage:        status.Stage,
+			ElapsedMs:    end.Sub(status.StartedAt).Milliseconds(),
+			Error:        status.Error,
+		}
+	}
+	return infos
+}
+
 // parseChunkCount converts interface{} values to integers for chunk counts
 func parseChunkCount(value any) (int, error) {
+	return parseInt(value)
+}
+
+// parseInt converts the numeric types msgpack may decode into an int
+func parseInt(value any) (int, error) {
 	switch v := value.(type) {
 	case int:
 		return v, nil
+	case int8:
+		return int(v), nil
+	case int16:
+		return int(v), nil
+	case int32:
+		return int(v), nil
 	case int64:
 		return int(v), nil
+	case uint8:
+		return int(v), nil
+	case uint16:
+		return int(v), nil
+	case uint32:
+		return int(v), nil
+	case uint64:
+		return int(v), nil
+	case float32:
+		return int(v), nil
 	case float64:
 		return int(v), nil
 	case string:
@@ -348,45 +887,209 @@ func (s *Server) parseCompletionRequest(rawRequest map[string]any) CompletionReq
 	if prefix, ok := rawRequest["p"].(string); ok {
 		request.Prefix = prefix
 	}
-	if limit, ok := rawRequest["l"].(int); ok {
+	if limit, err := parseInt(rawRequest["l"]); err == nil {
 		request.Limit = limit
-	} else if limitFloat, ok := rawRequest["l"].(float64); ok {
-		request.Limit = int(limitFloat)
+	}
+	if tail, ok := rawRequest["tail"].(bool); ok {
+		request.Tail = tail
+	}
+	if after, ok := rawRequest["after"].(string); ok {
+		request.After = after
+	}
+	if highlight, ok := rawRequest["h"].(bool); ok {
+		request.Highlight = highlight
+	}
+	if debug, ok := rawRequest["d"].(bool); ok {
+		request.Debug = debug
+	}
+	if stream, ok := rawRequest["stream"].(bool); ok {
+		request.Stream = stream
+	}
+	if minLen, err := parseInt(rawRequest["minl"]); err == nil {
+		request.MinLen = minLen
+	}
+	if maxLen, err := parseInt(rawRequest["maxl"]); err == nil {
+		request.MaxLen = maxLen
+	}
+	if lang, ok := rawRequest["lang"].(string); ok {
+		request.Lang = lang
+	}
+	if pct, ok := rawRequest["pct"].(bool); ok {
+		request.Pct = pct
+	}
+	if casing, ok := rawRequest["casing"].(bool); ok {
+		request.Casing = casing
+	}
+	if canonical, ok := rawRequest["canon"].(bool); ok {
+		request.Canonical = canonical
 	}
 	return request
 }
 
 // handleCompletionRequest validates and processes a completion request
 func (s *Server) handleCompletionRequest(request CompletionRequest) error {
-	log.Debugf("Received completion request: prefix='%s', limit=%d", request.Prefix, request.Limit)
+	response, completionErr := s.runCompletion(request)
+	if completionErr != nil {
+		return s.sendResponse(completionErr)
+	}
+	if request.Stream {
+		return s.streamCompletion(response)
+	}
+	return s.sendResponse(response)
+}
+
+// streamCompletion sends a ranked response one suggestion per message, followed by a done marker.
+// Other responses may be interleaved by concurrent workers, clients match messages by id.
+func (s *Server) streamCompletion(response *CompletionResponse) error {
+	for i, suggestion := range response.Suggestions {
+		if err := s.sendResponse(&CompletionStreamItem{
+			ID:         response.ID,
+			Index:      i,
+			Suggestion: suggestion,
+		}); err != nil {
+			return err
+		}
+	}
+	return s.sendResponse(&CompletionStreamDone{
+		ID:        response.ID,
+		Done:      true,
+		Count:     response.Count,
+		TimeTaken: response.TimeTaken,
+		More:      response.More,
+		Casing:    response.Casing,
+		Caps:      response.Caps,
+	})
+}
+
+// runCompletion validates a completion request and builds its response.
+// Validation failures are returned as a CompletionError instead of being sent,
+// so batch requests can report them per entry.
+func (s *Server) runCompletion(request CompletionRequest) (*CompletionResponse, *CompletionError) {
+	log.Debugf("Received completion request: prefix=%s, limit=%d", s.redact(request.Prefix), request.Limit)
+	s.waitUntilReady()
+	cfg := s.currentConfig()
+	lang, err := s.languageFor(request.Lang)
+	if err != nil {
+		return nil, &CompletionError{ID: request.ID, Error: err.Error(), Code: 400}
+	}
 	// Validate prefix using config
 	if request.Prefix == "" {
-		return s.sendError(request.ID, "empty prefix", 400)
+		return nil, &CompletionError{ID: request.ID, Error: "empty prefix", Code: 400}
+	}
+	if len(request.Prefix) < cfg.Server.MinPrefix {
+		return nil, &CompletionError{ID: request.ID, Error: fmt.Sprintf("prefix too short (min: %d)", cfg.Server.MinPrefix), Code: 400}
+	}
+	if len(request.Prefix) > cfg.Server.MaxPrefix {
+		return nil, &CompletionError{ID: request.ID, Error: fmt.Sprintf("prefix too long (max: %d)", cfg.Server.MaxPrefix), Code: 400}
 	}
-	if len(request.Prefix) < s.config.Server.MinPrefix {
-		return s.sendError(request.ID, fmt.Sprintf("prefix too short (min: %d)", s.config.Server.MinPrefix), 400)
+	if request.MinLen < 0 || request.MaxLen < 0 {
+		return nil, &CompletionError{ID: request.ID, Error: "minl and maxl can't be negative", Code: 400}
 	}
-	if len(request.Prefix) > s.config.Server.MaxPrefix {
-		return s.sendError(request.ID, fmt.Sprintf("prefix too long (max: %d)", s.config.Server.MaxPrefix), 400)
+	if request.MaxLen > 0 && request.MinLen > request.MaxLen {
+		return nil, &CompletionError{ID: request.ID, Error: fmt.Sprintf("minl (%d) is greater than maxl (%d)", request.MinLen, request.MaxLen), Code: 400}
 	}
-	if s.config.Server.EnableFilter && !utils.IsValidInput(request.Prefix) {
-		return s.sendResponse(&CompletionResponse{
+	digits, _ := utils.ParseDigitMode(cfg.Server.DigitMode)
+	filter := utils.InputFilter{Digits: digits, AllowSymbols: cfg.Server.AllowSymbols}
+	if cfg.Server.EnableFilter && !utils.IsValidInputWith(request.Prefix, filter) {
+		return &CompletionResponse{
 			ID:          request.ID,
 			Suggestions: []CompletionSuggestion{},
 			Count:       0,
 			TimeTaken:   0,
-		})
+		}, nil
+	}
+	patterns := s.currentPatterns()
+	if !patterns.Match(request.Prefix) {
+		return &CompletionResponse{
+			ID:          request.ID,
+			Suggestions: []CompletionSuggestion{},
+			Count:       0,
+			TimeTaken:   0,
+		}, nil
 	}
 	if request.Limit <= 0 {
-		request.Limit = s.config.Server.MaxLimit / 2
+		request.Limit = cfg.Server.MaxLimit / 2
+	}
+	if request.Limit > cfg.Server.MaxLimit {
+		request.Limit = cfg.Server.MaxLimit
 	}
-	if request.Limit > s.config.Server.MaxLimit {
-		request.Limit = s.config.Server.MaxLimit
+	// Ask for extra suggestions when some may be filtered out by the patterns
+	fetchLimit := request.Limit
+	if patterns.Active() {
+		fetchLimit *= 2
 	}
 	// Get completions with timing
+	profile := s.startProfile()
 	start := time.Now()
-	suggestions := s.completer.Complete(request.Prefix, request.Limit)
+	var suggestions []completion.Suggestion
+	if contextCompleter, ok := lang.completer.(interface {
+		CompleteWithContext(ctx context.Context, opts completion.CompletionOptions) ([]completion.Suggestion, error)
+	}); ok {
+		ctx := context.Background()
+		timeout := time.Duration(cfg.Server.RequestTimeoutMs) * time.Millisecond
+		if timeout > 0 {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, timeout)
+			defer cancel()
+		}
+		suggestions, err = contextCompleter.CompleteWithContext(ctx, completion.CompletionOptions{
+			Prefix:    request.Prefix,
+			Limit:     fetchLimit,
+			MinLen:    request.MinLen,
+			MaxLen:    request.MaxLen,
+			After:     request.After,
+			Canonical: request.Canonical,
+		})
+		if errors.Is(err, context.DeadlineExceeded) {
+			elapsed := time.Since(start)
+			s.latency.record(elapsed)
+			s.finishProfile(profile, request.Prefix, elapsed)
+			log.Warnf("Completion for prefix %s timed out after %v", s.redact(request.Prefix), timeout)
+			return nil, &CompletionError{ID: request.ID, Error: fmt.Sprintf("completion timed out after %dms (server.request_timeout_ms)", cfg.Server.RequestTimeoutMs), Code: 504}
+		}
+		if err != nil {
+			s.finishProfile(profile, request.Prefix, time.Since(start))
+			log.Errorf("Completion for prefix %s failed: %v", s.redact(request.Prefix), err)
+			return nil, &CompletionError{ID: request.ID, Error: "completion failed: " + err.Error(), Code: 500}
+		}
+	} else if optionsCompleter, ok := lang.completer.(interface {
+		CompleteWithOptions(opts completion.CompletionOptions) []completion.Suggestion
+	}); ok && (request.MinLen > 0 || request.MaxLen > 0) {
+		suggestions = optionsCompleter.CompleteWithOptions(completion.CompletionOptions{
+			Prefix: request.Prefix,
+			Limit:  fetchLimit,
+			MinLen: request.MinLen,
+			MaxLen: request.MaxLen,
+			After:  request.After,
+		})
+	} else if aroundCompleter, ok := lang.completer.(interface {
+		CompleteAround(prefix, after string, limit int) []completion.Suggestion
+	}); ok && request.After != "" {
+		suggestions = aroundCompleter.CompleteAround(request.Prefix, request.After, fetchLimit)
+	} else {
+		suggestions = lang.completer.Complete(request.Prefix, fetchLimit)
+	}
+	if patterns.Active() {
+		suggestions = slices.DeleteFunc(suggestions, func(suggestion completion.Suggestion) bool {
+			return !patterns.Match(suggestion.Word)
+		})
+		if len(suggestions) > request.Limit {
+			suggestions = suggestions[:request.Limit]
+		}
+	}
 	elapsed := time.Since(start)
+	s.latency.record(elapsed)
+	s.finishProfile(profile, request.Prefix, elapsed)
+
+	casing, caps := "", []int(nil)
+	if request.Casing || request.Canonical {
+		casing = completion.Casing(request.Prefix)
+	}
+	if request.Canonical && casing == "mixed" {
+		caps = completion.CapitalPositions(request.Prefix)
+	}
+	chunkCompleter, _ := lang.completer.(interface{ WordChunk(word string) int })
+	percentileCompleter, _ := lang.completer.(interface{ FrequencyPercentile(freq int) float64 })
 
 	responseSuggestions := make([]CompletionSuggestion, len(suggestions))
 	for i, s := range suggestions {
@@ -394,12 +1097,160 @@ func (s *Server) handleCompletionRequest(request CompletionRequest) error {
 			Word: s.Word,
 			Rank: uint16(i + 1),
 		}
+		if request.Tail {
+			responseSuggestions[i].Tail = completion.Tail(request.Prefix, s.Word)
+		}
+		if request.Highlight {
+			responseSuggestions[i].MatchedIndexes = completion.MatchedIndexes(request.Prefix, s.Word)
+		}
+		if request.Debug && chunkCompleter != nil {
+			responseSuggestions[i].Chunk = chunkCompleter.WordChunk(s.Word)
+		}
+		if request.Pct && percentileCompleter != nil {
+			responseSuggestions[i].Percentile = percentileCompleter.FrequencyPercentile(s.Frequency)
+		}
 	}
-	response := &CompletionResponse{
+	return &CompletionResponse{
 		ID:          request.ID,
 		Suggestions: responseSuggestions,
 		Count:       len(responseSuggestions),
 		TimeTaken:   elapsed.Microseconds(),
+		More:        len(responseSuggestions) < request.Limit && lang.chunkLoader != nil && lang.chunkLoader.HasUnloaded(),
+		Casing:      casing,
+		Caps:        caps,
+	}, nil
+}
+
+// processPredictRequest suggests the next word after "prev", narrowed by an optional prefix
+func (s *Server) processPredictRequest(rawRequest map[string]any) error {
+	var request PredictRequest
+	request.ID, _ = rawRequest["id"].(string)
+	request.Previous, _ = rawRequest["prev"].(string)
+	request.Prefix, _ = rawRequest["p"].(string)
+	if limit, err := parseInt(rawRequest["l"]); err == nil {
+		request.Limit = limit
 	}
-	return s.sendResponse(response)
+	request.Lang, _ = rawRequest["lang"].(string)
+	log.Debugf("Received predict request: prev=%s, prefix=%s, limit=%d", s.redact(request.Previous), s.redact(request.Prefix), request.Limit)
+
+	lang, err := s.languageFor(request.Lang)
+	if err != nil {
+		return s.sendError(request.ID, err.Error(), 400)
+	}
+	predictor, ok := lang.completer.(interface {
+		PredictNext(previousWord, prefix string, limit int) []completion.Suggestion
+	})
+	if !ok {
+		return s.sendError(request.ID, "completer does not support next-word prediction", 501)
+	}
+	cfg := s.currentConfig()
+	if request.Previous == "" {
+		return s.sendError(request.ID, "prev required for predict_next action", 400)
+	}
+	if len(request.Prefix) > cfg.Server.MaxPrefix {
+		return s.sendError(request.ID, fmt.Sprintf("prefix too long (max: %d)", cfg.Server.MaxPrefix), 400)
+	}
+	if request.Limit <= 0 {
+		request.Limit = cfg.Server.MaxLimit / 2
+	}
+	if request.Limit > cfg.Server.MaxLimit {
+		request.Limit = cfg.Server.MaxLimit
+	}
+	s.waitUntilReady()
+
+	start := time.Now()
+	suggestions := predictor.PredictNext(request.Previous, request.Prefix, request.Limit)
+	elapsed := time.Since(start)
+
+	responseSuggestions := make([]CompletionSuggestion, len(suggestions))
+	for i, suggestion := range suggestions {
+		responseSuggestions[i] = CompletionSuggestion{
+			Word: suggestion.Word,
+			Rank: uint16(i + 1),
+		}
+	}
+	return s.sendResponse(&CompletionResponse{
+		ID:          request.ID,
+		Suggestions: responseSuggestions,
+		Count:       len(responseSuggestions),
+		TimeTaken:   elapsed.Microseconds(),
+	})
+}
+
+// processCountRequest answers how many completions a prefix has, capped at suggest.MaxCount
+func (s *Server) processCountRequest(rawRequest map[string]any) error {
+	var request CountRequest
+	request.ID, _ = rawRequest["id"].(string)
+	request.Prefix, _ = rawRequest["p"].(string)
+	request.Lang, _ = rawRequest["lang"].(string)
+	if minFreq, err := parseInt(rawRequest["minf"]); err == nil {
+		request.MinFreq = minFreq
+	}
+	log.Debugf("Received count request: prefix=%s", s.redact(request.Prefix))
+
+	cfg := s.currentConfig()
+	lang, err := s.languageFor(request.Lang)
+	if err != nil {
+		return s.sendError(request.ID, err.Error(), 400)
+	}
+	counter, ok := lang.completer.(interface {
+		CountMatches(prefix string, minFreq int) int
+	})
+	if !ok {
+		return s.sendError(request.ID, "completer does not support counting matches", 501)
+	}
+	if request.Prefix == "" {
+		return s.sendError(request.ID, "p required for count action", 400)
+	}
+	if len(request.Prefix) > cfg.Server.MaxPrefix {
+		return s.sendError(request.ID, fmt.Sprintf("prefix too long (max: %d)", cfg.Server.MaxPrefix), 400)
+	}
+	s.waitUntilReady()
+
+	start := time.Now()
+	response := CountResponse{ID: request.ID}
+	digits, _ := utils.ParseDigitMode(cfg.Server.DigitMode)
+	filter := utils.InputFilter{Digits: digits, AllowSymbols: cfg.Server.AllowSymbols}
+	if (!cfg.Server.EnableFilter || utils.IsValidInputWith(request.Prefix, filter)) && s.currentPatterns().Match(request.Prefix) {
+		response.Count = counter.CountMatches(request.Prefix, request.MinFreq)
+	}
+	response.Capped = response.Count >= completion.MaxCount
+	response.TimeTaken = time.Since(start).Microseconds()
+	return s.sendResponse(&response)
+}
+
+// processBatchRequest completes several prefixes and replies with one response
+func (s *Server) processBatchRequest(rawRequest map[string]any) error {
+	var request BatchCompletionRequest
+	request.ID, _ = rawRequest["id"].(string)
+	rawReqs, ok := rawRequest["reqs"].([]any)
+	if !ok {
+		return s.sendError(request.ID, "reqs array required for batch_complete action", 400)
+	}
+	for _, rawReq := range rawReqs {
+		subRequest, _ := rawReq.(map[string]any)
+		request.Requests = append(request.Requests, s.parseCompletionRequest(subRequest))
+	}
+	log.Debugf("Processing batch request: %d prefixes", len(request.Requests))
+
+	start := time.Now()
+	results := make([]BatchCompletionResult, len(request.Requests))
+	for i, sub := range request.Requests {
+		results[i].Index = i
+		response, completionErr := s.runCompletion(sub)
+		if completionErr != nil {
+			results[i].Error = completionErr.Error
+			results[i].Code = completionErr.Code
+			results[i].Suggestions = []CompletionSuggestion{}
+			continue
+		}
+		results[i].Suggestions = response.Suggestions
+		results[i].Count = response.Count
+		results[i].More = response.More
+	}
+	return s.sendResponse(&BatchCompletionResponse{
+		ID:        request.ID,
+		Results:   results,
+		TimeTaken: time.Since(start).Microseconds(),
+	})
 }
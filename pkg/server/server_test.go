@@ -0,0 +1,306 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/bastiangx/wordserve/pkg/config"
+	"github.com/bastiangx/wordserve/pkg/dictionary"
+	completion "github.com/bastiangx/wordserve/pkg/suggest"
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+// offlineRelease serves 404 for every release file, so a loader pointed at it
+// can't fall back to downloading the dictionary. It is shared by every test and
+// left running, so it doesn't count in the goroutines a test leaves behind.
+var offlineRelease = sync.OnceValue(func() string {
+	return httptest.NewServer(http.NotFoundHandler()).URL
+})
+
+// newTestCompleter builds chunks of chunkSize words from words, most frequent
+// first, and returns a lazy completer that has loaded all of them.
+// The config is kept to a temp dir and matched to the chunks, so nothing is
+// rebuilt or downloaded.
+func newTestCompleter(t *testing.T, words []string, chunkSize int) *completion.Completer {
+	t.Helper()
+	dir := t.TempDir()
+	var lines strings.Builder
+	for i, word := range words {
+		fmt.Fprintf(&lines, "%s\t%d\n", word, 1000+len(words)-i)
+	}
+	wordsPath := filepath.Join(dir, "words.txt")
+	if err := os.WriteFile(wordsPath, []byte(lines.String()), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := dictionary.BuildChunks(wordsPath, dir, chunkSize, 0); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	t.Setenv("WORDSERVE_MAX_WORDS", strconv.Itoa(len(words)))
+	t.Setenv("WORDSERVE_CHUNK_SIZE", strconv.Itoa(chunkSize))
+
+	completer := completion.NewLazyCompleter(dir, chunkSize, 0, false)
+	completer.GetChunkLoader().SetReleaseURL(offlineRelease())
+	t.Cleanup(completer.Stop)
+	if err := completer.Initialize(); err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := completer.GetChunkLoader().WaitUntilReady(ctx); err != nil {
+		t.Fatal(err)
+	}
+	return completer
+}
+
+// serve sends requests to s in one session and returns the responses in order
+func serve(t *testing.T, s *Server, requests ...map[string]any) []map[string]any {
+	t.Helper()
+	var in, out bytes.Buffer
+	encoder := msgpack.NewEncoder(&in)
+	for _, request := range requests {
+		if err := encoder.Encode(request); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := s.ServeSession(&in, &out); err != nil {
+		t.Fatal(err)
+	}
+	var responses []map[string]any
+	decoder := msgpack.NewDecoder(&out)
+	for {
+		var response map[string]any
+		if err := decoder.Decode(&response); err != nil {
+			if errors.Is(err, io.EOF) {
+				return responses
+			}
+			t.Fatal(err)
+		}
+		responses = append(responses, response)
+	}
+}
+
+func TestShutdownStopsGoroutines(t *testing.T) {
+	offlineRelease()
+	baseline := runtime.NumGoroutine()
+
+	completer := newTestCompleter(t, []string{"hello", "help", "helmet"}, 10)
+	s := NewServer(completer, config.DefaultConfig(), "")
+	responses := serve(t, s,
+		map[string]any{"id": "1", "p": "hel", "l": 5},
+		map[string]any{"id": "bye", "action": "shutdown"},
+	)
+	if len(responses) != 2 || responses[1]["id"] != "bye" || responses[1]["status"] != "ok" {
+		t.Fatalf("responses = %v, want a completion and the shutdown acknowledgement", responses)
+	}
+
+	deadline := time.Now().Add(5 * time.Second)
+	for runtime.NumGoroutine() > baseline {
+		if time.Now().After(deadline) {
+			buf := make([]byte, 1<<16)
+			t.Fatalf("%d goroutines after shutdown, want at most %d:\n%s",
+				runtime.NumGoroutine(), baseline, buf[:runtime.Stack(buf, true)])
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestWorkersAnswerEveryRequestOnce(t *testing.T) {
+	completer := completion.NewCompleter()
+	for i, word := range []string{"hello", "help", "helmet", "world", "word", "worm"} {
+		completer.AddWord(word, 1000-i)
+	}
+	cfg := config.DefaultConfig()
+	cfg.Server.Workers = 4
+	s := NewServer(completer, cfg, "")
+
+	const count = 100
+	prefixes := []string{"hel", "wor"}
+	requests := make([]map[string]any, count)
+	for i := range requests {
+		requests[i] = map[string]any{"id": strconv.Itoa(i), "p": prefixes[i%len(prefixes)], "l": 5}
+	}
+	responses := serve(t, s, requests...)
+	if len(responses) != count {
+		t.Fatalf("got %d responses, want %d", len(responses), count)
+	}
+	seen := make(map[string]bool, count)
+	for _, response := range responses {
+		id, _ := response["id"].(string)
+		if seen[id] {
+			t.Errorf("id %q answered twice", id)
+		}
+		seen[id] = true
+		i, err := strconv.Atoi(id)
+		if err != nil || i < 0 || i >= count {
+			t.Errorf("response %v has an id that wasn't sent", response)
+			continue
+		}
+		// Each answer matches its own request, not one handled next to it
+		for _, word := range suggestedWords(t, response) {
+			if !strings.HasPrefix(word, prefixes[i%len(prefixes)]) {
+				t.Errorf("request %d for %q got %q", i, prefixes[i%len(prefixes)], word)
+			}
+		}
+	}
+}
+
+func TestCompletionLengthBounds(t *testing.T) {
+	completer := completion.NewCompleter()
+	for i, word := range []string{"cry", "crab", "crane", "crates", "crayons", "crocodile"} {
+		completer.AddWord(word, 900-100*i)
+	}
+	s := NewServer(completer, config.DefaultConfig(), "")
+	responses := serve(t, s,
+		map[string]any{"id": "1", "p": "cr", "l": 10, "minl": 5, "maxl": 7},
+		map[string]any{"id": "2", "p": "cr", "l": 10, "minl": 7, "maxl": 5},
+		map[string]any{"id": "3", "p": "cr", "l": 10, "minl": -1},
+	)
+	if len(responses) != 3 {
+		t.Fatalf("got %d responses, want 3", len(responses))
+	}
+	var got []string
+	for _, suggestion := range responses[0]["s"].([]any) {
+		got = append(got, suggestion.(map[string]any)["w"].(string))
+	}
+	if want := []string{"crane", "crates", "crayons"}; !slices.Equal(got, want) {
+		t.Errorf("minl 5, maxl 7 = %v, want %v", got, want)
+	}
+	for _, response := range responses[1:] {
+		if _, ok := response["e"]; !ok {
+			t.Errorf("response %v has no error, want bad bounds rejected", response)
+		}
+	}
+}
+
+func TestCompletionTimeout(t *testing.T) {
+	completer := completion.NewCompleter()
+	for i := range 300_000 {
+		completer.AddWord("a"+strconv.Itoa(i), i+100)
+	}
+	cfg := config.DefaultConfig()
+	cfg.Server.RequestTimeoutMs = 1
+	responses := serve(t, NewServer(completer, cfg, ""), map[string]any{"id": "1", "p": "a", "l": 5})
+	if len(responses) != 1 || toInt64(responses[0]["c"]) != 504 {
+		t.Fatalf("responses = %v, want a 504 for the walk outlasting a 1ms timeout", responses)
+	}
+
+	cfg = config.DefaultConfig()
+	responses = serve(t, NewServer(completer, cfg, ""), map[string]any{"id": "2", "p": "a", "l": 5})
+	if len(responses) != 1 || len(responses[0]["s"].([]any)) != 5 {
+		t.Fatalf("responses = %v, want 5 suggestions without a timeout", responses)
+	}
+}
+
+func TestCountMatchesCompletions(t *testing.T) {
+	completer := completion.NewCompleter()
+	for i, word := range []string{"then", "there", "these", "theater", "thy", "tab"} {
+		completer.AddWord(word, 900-100*i)
+	}
+	s := NewServer(completer, config.DefaultConfig(), "")
+	for _, prefix := range []string{"t", "th", "the", "ther", "q"} {
+		responses := serve(t, s,
+			map[string]any{"id": "count", "action": "count", "p": prefix},
+			map[string]any{"id": "complete", "p": prefix, "l": 50},
+		)
+		if len(responses) != 2 {
+			t.Fatalf("got %d responses, want 2", len(responses))
+		}
+		suggestions, _ := responses[1]["s"].([]any)
+		if count := toInt64(responses[0]["c"]); count != int64(len(suggestions)) {
+			t.Errorf("count of %q = %d, want %d as many as completed", prefix, count, len(suggestions))
+		}
+	}
+}
+
+func TestReloadAppliesFrequencyThresholds(t *testing.T) {
+	completer := completion.NewCompleter()
+	completer.AddWord("word", 100)
+	completer.AddWord("words", 30)
+	completer.AddWord("wordy", 22)
+	configPath := filepath.Join(t.TempDir(), "config.toml")
+	cfg := config.DefaultConfig()
+	if err := config.SaveConfig(cfg, configPath); err != nil {
+		t.Fatal(err)
+	}
+	s := NewServer(completer, cfg, configPath)
+
+	cfg = config.DefaultConfig()
+	cfg.Dict.MinFreqThreshold = 25
+	if err := config.SaveConfig(cfg, configPath); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.reloadConfig(); err != nil {
+		t.Fatal(err)
+	}
+	responses := serve(t, s, map[string]any{"id": "1", "p": "wor", "l": 10})
+	var got []string
+	for _, suggestion := range responses[0]["s"].([]any) {
+		got = append(got, suggestion.(map[string]any)["w"].(string))
+	}
+	if want := []string{"word", "words"}; !slices.Equal(got, want) {
+		t.Errorf("completions after raising min_frequency_threshold to 25 = %v, want %v", got, want)
+	}
+}
+
+// failingCompleter makes every trie walk fail. Its context returns an error the
+// first time the walk's visitor checks it, and none afterwards, so the failure
+// isn't taken for a timeout.
+type failingCompleter struct {
+	*completion.Completer
+}
+
+func (c failingCompleter) CompleteWithContext(ctx context.Context, opts completion.CompletionOptions) ([]completion.Suggestion, error) {
+	return c.Completer.CompleteWithContext(&failingVisit{Context: ctx}, opts)
+}
+
+// failingVisit is the context failingCompleter searches with
+type failingVisit struct {
+	context.Context
+	failed bool
+}
+
+func (c *failingVisit) Err() error {
+	if !c.failed {
+		c.failed = true
+		return errors.New("visit failed")
+	}
+	return c.Context.Err()
+}
+
+func TestCompletionFailureIs500(t *testing.T) {
+	completer := completion.NewCompleter()
+	// Enough words for the visitor to check the context during the walk
+	for i := range 1000 {
+		completer.AddWord(fmt.Sprintf("ab%03d", i), 100)
+	}
+	s := NewServer(failingCompleter{completer}, config.DefaultConfig(), "")
+	responses := serve(t, s,
+		map[string]any{"id": "1", "p": "ab", "l": 5},
+		map[string]any{"id": "2", "p": "xy", "l": 5},
+	)
+	if len(responses) != 2 {
+		t.Fatalf("got %d responses, want 2", len(responses))
+	}
+	if toInt64(responses[0]["c"]) != 500 || !strings.Contains(responses[0]["e"].(string), "visit failed") {
+		t.Errorf("failed walk = %v, want a 500 naming the failure", responses[0])
+	}
+	// A prefix without matches has no subtree to walk, and is still an empty success
+	if _, ok := responses[1]["e"]; ok {
+		t.Errorf("prefix without matches = %v, want no error", responses[1])
+	}
+}
@@ -0,0 +1,108 @@
+package server
+
+import (
+	"errors"
+	"io"
+	"sync"
+
+	"github.com/charmbracelet/log"
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+// ServeSession answers the msgpack requests read from r on w until r ends.
+// The end of r only ends the session: the server, its loaders and watchers keep
+// running for the next one, and Shutdown ends the server. A shutdown action from
+// the client shuts the server down and ends the session too.
+//
+// Sessions are served one at a time, a second call waits for the first to end.
+// Responses of the session go to w, so a client only sees its own replies.
+// Sessions started after Shutdown have their first request refused.
+func (s *Server) ServeSession(r io.Reader, w io.Writer) error {
+	s.sessionMutex.Lock()
+	defer s.sessionMutex.Unlock()
+	s.writeMutex.Lock()
+	s.out = w
+	s.writeMutex.Unlock()
+
+	decoder := msgpack.NewDecoder(r)
+	if workers := s.currentConfig().Server.Workers; workers > 1 {
+		return s.serveWorkers(decoder, workers)
+	}
+	for {
+		rawRequest, err := s.readRequest(decoder)
+		if err != nil {
+			if sessionEnded(err) {
+				log.Debug("Client disconnected")
+				return nil
+			}
+			continue
+		}
+		if isShutdownRequest(rawRequest) {
+			return s.shutdown(rawRequest)
+		}
+		if !s.track() {
+			s.refuse(rawRequest)
+			return nil
+		}
+		s.handleRequest(rawRequest)
+		s.inflight.Done()
+	}
+}
+
+// serveWorkers decodes requests on the calling goroutine and hands them
+// to a pool of workers. Responses may be sent out of order, clients match them by id.
+// The pool lives as long as the session.
+func (s *Server) serveWorkers(decoder *msgpack.Decoder, workers int) error {
+	log.Debugf("Processing requests with %d workers", workers)
+	requests := make(chan map[string]any, workers)
+	var wg sync.WaitGroup
+	for range workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for rawRequest := range requests {
+				s.handleRequest(rawRequest)
+				s.inflight.Done()
+			}
+		}()
+	}
+	// Queued requests count as in flight, so Shutdown waits for the workers
+	// to answer them. Closing the queue afterwards lets the workers exit,
+	// after answering what the session sent before it ended.
+	defer func() {
+		close(requests)
+		wg.Wait()
+	}()
+
+	for {
+		rawRequest, err := s.readRequest(decoder)
+		if err != nil {
+			if sessionEnded(err) {
+				log.Debug("Client disconnected")
+				return nil
+			}
+			continue
+		}
+		if isShutdownRequest(rawRequest) {
+			return s.shutdown(rawRequest)
+		}
+		if !s.track() {
+			s.refuse(rawRequest)
+			return nil
+		}
+		requests <- rawRequest
+	}
+}
+
+// sessionEnded reports whether a read error means the client went away,
+// including a message cut off by the disconnect
+func sessionEnded(err error) bool {
+	return errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF)
+}
+
+// syncOutput pushes buffered output to the client when w is a file, like stdout
+func syncOutput(w io.Writer) {
+	if file, ok := w.(interface{ Sync() error }); ok {
+		file.Sync()
+	}
+}
@@ -0,0 +1,49 @@
+package server
+
+import (
+	"github.com/charmbracelet/log"
+)
+
+// Shutdown stops the server without losing replies. Requests already read are
+// answered first: completions held by queue_until_ready are released and served
+// with the words loaded so far, workers finish their queue, and stdout is flushed.
+// Then background work is stopped. Requests read after this are refused.
+// Safe to call more than once and from any goroutine.
+func (s *Server) Shutdown() {
+	s.drainMutex.Lock()
+	if !s.draining {
+		s.draining = true
+		close(s.stopping)
+		log.Debug("Draining pending requests")
+	}
+	s.drainMutex.Unlock()
+
+	s.inflight.Wait()
+	s.flush()
+	s.stop()
+}
+
+// track counts a request as in flight until it is answered, so Shutdown can wait for it.
+// It reports false once the server is shutting down; the request is then refused.
+func (s *Server) track() bool {
+	s.drainMutex.Lock()
+	defer s.drainMutex.Unlock()
+	if s.draining {
+		return false
+	}
+	s.inflight.Add(1)
+	return true
+}
+
+// refuse answers a request read after Shutdown began
+func (s *Server) refuse(rawRequest map[string]any) {
+	id, _ := rawRequest["id"].(string)
+	s.sendError(id, "server is shutting down", 503)
+}
+
+// flush waits for a response being written and pushes the output to the client
+func (s *Server) flush() {
+	s.writeMutex.Lock()
+	defer s.writeMutex.Unlock()
+	syncOutput(s.out)
+}
@@ -0,0 +1,74 @@
+package suggest
+
+import (
+	"testing"
+)
+
+// benchWords returns n distinct lowercase words of 3 to 8 letters, most frequent first
+func benchWords(n int) []string {
+	words := make([]string, n)
+	for i := range words {
+		word := make([]byte, 0, 8)
+		for v := i; len(word) < 3 || v > 0; v /= 26 {
+			word = append(word, byte('a'+v%26))
+		}
+		words[i] = string(word)
+	}
+	return words
+}
+
+// benchPrefixes returns the distinct two and three letter prefixes of words, up to n
+func benchPrefixes(words []string, n int) []string {
+	seen := make(map[string]bool)
+	var prefixes []string
+	for _, word := range words {
+		for _, length := range []int{2, 3} {
+			prefix := word[:length]
+			if !seen[prefix] {
+				seen[prefix] = true
+				prefixes = append(prefixes, prefix)
+			}
+		}
+		if len(prefixes) >= n {
+			break
+		}
+	}
+	return prefixes[:min(n, len(prefixes))]
+}
+
+// benchmarkComplete times completions of a repeated prefix workload, which the
+// hot cache is meant for, and of one where no prefix is repeated before the
+// cache has forgotten it
+func benchmarkComplete(b *testing.B, useHotCache bool) {
+	words := benchWords(50000)
+	completer := newTestCompleter(b, words, 10000, useHotCache)
+	if useHotCache {
+		waitHotCache(b, completer)
+	}
+	workloads := []struct {
+		name     string
+		prefixes []string
+	}{
+		{"repeated", []string{"th", "an", "he", "in", "re", "on", "at", "en"}},
+		// More prefixes than the cache holds, so each is evicted before it comes back
+		{"unique", benchPrefixes(words, 4*defaultHotCacheSize)},
+	}
+	for _, workload := range workloads {
+		b.Run(workload.name, func(b *testing.B) {
+			b.ReportAllocs()
+			i := 0
+			for b.Loop() {
+				completer.Complete(workload.prefixes[i%len(workload.prefixes)], 10)
+				i++
+			}
+		})
+	}
+}
+
+func BenchmarkCompleteHotCache(b *testing.B) {
+	benchmarkComplete(b, true)
+}
+
+func BenchmarkCompleteNoCache(b *testing.B) {
+	benchmarkComplete(b, false)
+}
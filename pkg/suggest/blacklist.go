@@ -0,0 +1,177 @@
+package suggest
+
+import (
+	"bufio"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"github.com/bastiangx/wordserve/internal/utils"
+	"github.com/charmbracelet/log"
+)
+
+// Blacklist holds words that are never suggested, whatever their frequency.
+//
+// Entries are stored lowercase, like the dictionary. Searches read an immutable
+// snapshot, so checking a word takes no lock; [Blacklist.Add] and [Blacklist.Remove]
+// copy the set, which is fine for the small, rarely changed lists this is for.
+//
+// With diacritic folding on, an entry also hides the words that fold to the same
+// form, so blacklisting "cafe" hides "café" too.
+type Blacklist struct {
+	mu      sync.Mutex // serializes writers
+	set     atomic.Pointer[blacklistSet]
+	version atomic.Uint64
+}
+
+// blacklistSet is one immutable state of a Blacklist
+type blacklistSet struct {
+	words  map[string]struct{}
+	folded map[string]int // folded form -> number of entries folding to it
+}
+
+// NewBlacklist returns an empty blacklist
+func NewBlacklist() *Blacklist {
+	b := &Blacklist{}
+	b.set.Store(&blacklistSet{words: map[string]struct{}{}, folded: map[string]int{}})
+	return b
+}
+
+// blocks reports whether a dictionary word is blacklisted.
+// Dictionary words are already lowercase.
+func (s *blacklistSet) blocks(word string, fold bool) bool {
+	if _, ok := s.words[word]; ok {
+		return true
+	}
+	return fold && s.folded[utils.FoldDiacritics(word)] > 0
+}
+
+// Contains reports whether word is blacklisted, ignoring case.
+// With fold, words differing only in accents from an entry count too.
+func (b *Blacklist) Contains(word string, fold bool) bool {
+	return b.set.Load().blocks(strings.ToLower(word), fold)
+}
+
+// Len returns the number of entries
+func (b *Blacklist) Len() int {
+	return len(b.set.Load().words)
+}
+
+// Words returns the entries, sorted
+func (b *Blacklist) Words() []string {
+	return slices.Sorted(maps.Keys(b.set.Load().words))
+}
+
+// Version increases whenever an entry is added or removed
+func (b *Blacklist) Version() uint64 {
+	return b.version.Load()
+}
+
+// Add blacklists words and returns how many weren't already
+func (b *Blacklist) Add(words ...string) int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	current := b.set.Load()
+	next := current.clone()
+	added := 0
+	for _, word := range words {
+		word = strings.ToLower(strings.TrimSpace(word))
+		if word == "" {
+			continue
+		}
+		if _, exists := next.words[word]; exists {
+			continue
+		}
+		next.words[word] = struct{}{}
+		next.folded[utils.FoldDiacritics(word)]++
+		added++
+	}
+	if added > 0 {
+		b.set.Store(next)
+		b.version.Add(1)
+	}
+	return added
+}
+
+// Remove takes a word off the blacklist and reports whether it was on it
+func (b *Blacklist) Remove(word string) bool {
+	word = strings.ToLower(strings.TrimSpace(word))
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	current := b.set.Load()
+	if _, exists := current.words[word]; !exists {
+		return false
+	}
+	next := current.clone()
+	delete(next.words, word)
+	folded := utils.FoldDiacritics(word)
+	if next.folded[folded]--; next.folded[folded] <= 0 {
+		delete(next.folded, folded)
+	}
+	b.set.Store(next)
+	b.version.Add(1)
+	return true
+}
+
+func (s *blacklistSet) clone() *blacklistSet {
+	return &blacklistSet{words: maps.Clone(s.words), folded: maps.Clone(s.folded)}
+}
+
+// Load adds the words of a newline-delimited file. Blank lines and lines
+// starting with # are skipped. A missing file is not an error, there is just
+// nothing to load yet.
+func (b *Blacklist) Load(path string) error {
+	file, err := os.Open(path)
+	if os.IsNotExist(err) {
+		log.Debugf("No blacklist file at %s", path)
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to load blacklist from %s: %w", path, err)
+	}
+	defer file.Close()
+
+	var words []string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		words = append(words, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("failed to load blacklist from %s: %w", path, err)
+	}
+	added := b.Add(words...)
+	log.Debugf("Loaded %d blacklisted words from %s", added, path)
+	return nil
+}
+
+// Save writes the entries to path, one per line in sorted order.
+// The file is replaced atomically, so a crash never leaves it half written.
+func (b *Blacklist) Save(path string) error {
+	if err := utils.EnsureDir(filepath.Dir(path)); err != nil {
+		return err
+	}
+	var content strings.Builder
+	for _, word := range b.Words() {
+		content.WriteString(word)
+		content.WriteByte('\n')
+	}
+	tmpPath := path + ".tmp"
+	if err := os.WriteFile(tmpPath, []byte(content.String()), 0o644); err != nil {
+		os.Remove(tmpPath)
+		return fmt.Errorf("failed to save blacklist: %w", err)
+	}
+	if err := os.Rename(tmpPath, path); err != nil {
+		os.Remove(tmpPath)
+		return fmt.Errorf("failed to save blacklist: %w", err)
+	}
+	return nil
+}
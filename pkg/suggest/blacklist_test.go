@@ -0,0 +1,54 @@
+package suggest
+
+import (
+	"slices"
+	"testing"
+)
+
+// checkNotSuggested fails if Complete or CompleteWithCallback return word for prefix
+func checkNotSuggested(t *testing.T, completer *Completer, prefix, word string) {
+	t.Helper()
+	if got := words(completer.Complete(prefix, 5)); slices.Contains(got, word) {
+		t.Errorf("Complete(%q) = %v, want no %s", prefix, got, word)
+	}
+	var streamed []string
+	if err := completer.CompleteWithCallback(prefix, 5, func(s Suggestion) bool {
+		streamed = append(streamed, s.Word)
+		return true
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if slices.Contains(streamed, word) {
+		t.Errorf("CompleteWithCallback(%q) = %v, want no %s", prefix, streamed, word)
+	}
+}
+
+func TestBlacklistedTopMatchIsNotSuggested(t *testing.T) {
+	completer := newStaticCompleter(map[string]int{"hello": 900, "help": 500, "helmet": 300})
+	completer.Blacklist().Add("Hello")
+	checkNotSuggested(t, completer, "hel", "hello")
+	if got := words(completer.Complete("hel", 5)); !slices.Equal(got, []string{"help", "helmet"}) {
+		t.Errorf("Complete(\"hel\") = %v, want the other words", got)
+	}
+}
+
+func TestBlacklistAfterHotCacheFilled(t *testing.T) {
+	completer := newTestCompleter(t, []string{"hello", "help", "helmet", "held", "hero"}, 10, true)
+	waitHotCache(t, completer)
+	if got := words(completer.Complete("hel", 5)); len(got) == 0 || got[0] != "hello" {
+		t.Fatalf("Complete(\"hel\") = %v, want hello first before blacklisting it", got)
+	}
+
+	// The cached results with hello in them are no longer used
+	completer.Blacklist().Add("hello")
+	checkNotSuggested(t, completer, "hel", "hello")
+
+	// nor put back when the cache is rebuilt, the second search is a cache hit
+	waitHotCache(t, completer)
+	checkNotSuggested(t, completer, "hel", "hello")
+	hitsBefore, _, _ := completer.hotCache.Stats()
+	checkNotSuggested(t, completer, "hel", "hello")
+	if hits, _, _ := completer.hotCache.Stats(); hits == hitsBefore {
+		t.Error("rebuilt cache was not used")
+	}
+}
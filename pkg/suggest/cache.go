@@ -0,0 +1,223 @@
+package suggest
+
+import (
+	"container/list"
+	"fmt"
+	"slices"
+	"sync"
+
+	"github.com/bastiangx/wordserve/pkg/dictionary"
+	"github.com/tchap/go-patricia/v2/patricia"
+)
+
+const (
+	// defaultHotCacheSize is the number of prefix results kept in the LRU.
+	defaultHotCacheSize = 512
+	// defaultHotWords is the number of most frequent words copied into the hot trie.
+	defaultHotWords = 5000
+)
+
+// HotCache speeds up repeated completions for frequently requested prefixes.
+//
+// It has two layers: an LRU of finished results keyed by prefix, limit and
+// threshold, and a small hot trie holding only the most frequent words of the
+// dictionary. A prefix that misses the LRU is searched in the hot trie first;
+// if that yields a full page of results, they are the true top results, since
+// every word outside the hot trie has a lower frequency.
+//
+// Cached results are stored before capitalization is applied. The cache
+// remembers the dictionary version it was populated from, so callers can
+// repopulate it whenever the dictionary changes and never serve stale results.
+type HotCache struct {
+	mu         sync.Mutex
+	rebuilding bool
+	version    uint64
+	capacity   int
+	entries    map[string]*list.Element
+	order      *list.List
+	hotTrie    *patricia.Trie
+	hotLimit   int
+	hotWords   int
+	hits       int
+	misses     int
+}
+
+type hotEntry struct {
+	key         string
+	suggestions []Suggestion
+}
+
+// NewHotCache creates a cache holding up to capacity prefix results and
+// a hot trie of up to hotWords words. Zero values use the defaults.
+func NewHotCache(capacity, hotWords int) *HotCache {
+	if capacity <= 0 {
+		capacity = defaultHotCacheSize
+	}
+	if hotWords <= 0 {
+		hotWords = defaultHotWords
+	}
+	return &HotCache{
+		capacity: capacity,
+		entries:  make(map[string]*list.Element, capacity),
+		order:    list.New(),
+		hotLimit: hotWords,
+	}
+}
+
+// Populate rebuilds the hot trie from the most frequent words in trie
+// and drops all cached results. version is the dictionary version trie belongs to.
+func (hc *HotCache) Populate(trie dictionary.Trie, version uint64) {
+	hc.populate(trie, version, nil)
+}
+
+// populate is [HotCache.Populate] leaving out the words blocked reports, which may be nil
+func (hc *HotCache) populate(trie dictionary.Trie, version uint64, blocked func(word string) bool) {
+	var words []Suggestion
+	if trie != nil {
+		trie.Visit(func(p patricia.Prefix, item patricia.Item) error {
+			word := string(p)
+			if blocked != nil && blocked(word) {
+				return nil
+			}
+			words = append(words, Suggestion{Word: word, Frequency: extractFrequency(item, word)})
+			return nil
+		})
+	}
+	slices.SortFunc(words, byFrequency)
+	if len(words) > hc.hotLimit {
+		words = words[:hc.hotLimit]
+	}
+	hotTrie := patricia.NewTrie()
+	for _, w := range words {
+		hotTrie.Insert(patricia.Prefix(w.Word), w.Frequency)
+	}
+
+	hc.mu.Lock()
+	defer hc.mu.Unlock()
+	hc.hotTrie = hotTrie
+	hc.hotWords = len(words)
+	hc.version = version
+	hc.entries = make(map[string]*list.Element, hc.capacity)
+	hc.order.Init()
+}
+
+// rebuild runs build in a new goroutine, unless an earlier rebuild is still running
+func (hc *HotCache) rebuild(build func()) {
+	hc.mu.Lock()
+	if hc.rebuilding {
+		hc.mu.Unlock()
+		return
+	}
+	hc.rebuilding = true
+	hc.mu.Unlock()
+	go func() {
+		defer func() {
+			hc.mu.Lock()
+			hc.rebuilding = false
+			hc.mu.Unlock()
+		}()
+		build()
+	}()
+}
+
+// Current reports whether the cache was populated from the given dictionary version.
+func (hc *HotCache) Current(version uint64) bool {
+	hc.mu.Lock()
+	defer hc.mu.Unlock()
+	return hc.hotTrie != nil && hc.version == version
+}
+
+// Lookup returns cached results for the given search, checking the LRU first
+// and then the hot trie. The returned slice is a copy.
+func (hc *HotCache) Lookup(lowerPrefix string, minThreshold, limit int) ([]Suggestion, bool) {
+	key := hotCacheKey(lowerPrefix, minThreshold, limit)
+
+	hc.mu.Lock()
+	defer hc.mu.Unlock()
+	if elem, ok := hc.entries[key]; ok {
+		hc.order.MoveToFront(elem)
+		hc.hits++
+		return cloneSuggestions(elem.Value.(*hotEntry).suggestions), true
+	}
+	if hc.hotTrie != nil && limit > 0 {
+		suggestions := hc.searchHot(lowerPrefix, minThreshold)
+		if len(suggestions) >= limit {
+			suggestions = suggestions[:limit]
+			hc.put(key, suggestions)
+			hc.hits++
+			return cloneSuggestions(suggestions), true
+		}
+	}
+	hc.misses++
+	return nil, false
+}
+
+// searchHot collects every qualifying hot word under the prefix, sorted by [byFrequency].
+// The hot trie is small, so the full subtree is visited. Callers must hold hc.mu.
+func (hc *HotCache) searchHot(lowerPrefix string, minThreshold int) []Suggestion {
+	var suggestions []Suggestion
+	hc.hotTrie.VisitSubtree(patricia.Prefix(lowerPrefix), func(p patricia.Prefix, item patricia.Item) error {
+		word := string(p)
+		if !extendsPrefix(p, lowerPrefix) {
+			return nil
+		}
+		if freq := extractFrequency(item, word); freq >= minThreshold {
+			suggestions = append(suggestions, Suggestion{Word: word, Frequency: freq})
+		}
+		return nil
+	})
+	slices.SortFunc(suggestions, byFrequency)
+	return suggestions
+}
+
+// Store caches the final, sorted results of a search.
+func (hc *HotCache) Store(lowerPrefix string, minThreshold, limit int, suggestions []Suggestion) {
+	hc.mu.Lock()
+	defer hc.mu.Unlock()
+	hc.put(hotCacheKey(lowerPrefix, minThreshold, limit), cloneSuggestions(suggestions))
+}
+
+// put inserts an entry and evicts the least recently used one when full.
+// Callers must hold hc.mu.
+func (hc *HotCache) put(key string, suggestions []Suggestion) {
+	if elem, ok := hc.entries[key]; ok {
+		elem.Value.(*hotEntry).suggestions = suggestions
+		hc.order.MoveToFront(elem)
+		return
+	}
+	hc.entries[key] = hc.order.PushFront(&hotEntry{key: key, suggestions: suggestions})
+	if hc.order.Len() > hc.capacity {
+		oldest := hc.order.Back()
+		hc.order.Remove(oldest)
+		delete(hc.entries, oldest.Value.(*hotEntry).key)
+	}
+}
+
+// Clear drops cached results and the hot trie.
+func (hc *HotCache) Clear() {
+	hc.mu.Lock()
+	defer hc.mu.Unlock()
+	hc.entries = make(map[string]*list.Element, hc.capacity)
+	hc.order.Init()
+	hc.hotTrie = nil
+	hc.hotWords = 0
+}
+
+// Stats returns the number of cache hits and misses, and the hot trie size.
+func (hc *HotCache) Stats() (hits, misses, words int) {
+	hc.mu.Lock()
+	defer hc.mu.Unlock()
+	return hc.hits, hc.misses, hc.hotWords
+}
+
+//go:inline
+func hotCacheKey(lowerPrefix string, minThreshold, limit int) string {
+	return fmt.Sprintf("%s\x00%d\x00%d", lowerPrefix, minThreshold, limit)
+}
+
+//go:inline
+func cloneSuggestions(suggestions []Suggestion) []Suggestion {
+	result := make([]Suggestion, len(suggestions))
+	copy(result, suggestions)
+	return result
+}
@@ -0,0 +1,40 @@
+package suggest
+
+import (
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestHotCacheRebuildsInBackground(t *testing.T) {
+	completer := newTestCompleter(t, []string{"hello", "help", "helmet", "held", "hero"}, 10, true)
+	waitHotCache(t, completer)
+
+	// The new word shows up right away, the stale cache is bypassed
+	completer.AddWord("helium", 1<<20)
+	if got := words(completer.Complete("hel", 2)); !slices.Contains(got, "helium") {
+		t.Fatalf("Complete after AddWord = %v, want helium", got)
+	}
+
+	waitHotCache(t, completer)
+	hitsBefore, _, _ := completer.hotCache.Stats()
+	if got := words(completer.Complete("hel", 2)); got[0] != "helium" {
+		t.Errorf("Complete from the rebuilt cache = %v, want helium first", got)
+	}
+	if hits, _, _ := completer.hotCache.Stats(); hits == hitsBefore {
+		t.Error("rebuilt cache was not used")
+	}
+}
+
+// waitHotCache searches until the hot cache is current, rebuilt in the background
+func waitHotCache(t testing.TB, completer *Completer) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for !completer.hotCache.Current(completer.Version()) {
+		if time.Now().After(deadline) {
+			t.Fatal("hot cache was not rebuilt for the current dictionary version")
+		}
+		completer.Complete("he", 1)
+		time.Sleep(time.Millisecond)
+	}
+}
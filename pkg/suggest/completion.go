@@ -1,28 +1,33 @@
 package suggest
 
 import (
+	"context"
 	"runtime"
+	"slices"
 	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"unicode/utf8"
 
 	"github.com/bastiangx/wordserve/internal/utils"
 	"github.com/bastiangx/wordserve/pkg/config"
 	"github.com/bastiangx/wordserve/pkg/dictionary"
+	"github.com/charmbracelet/log"
 
 	"github.com/tchap/go-patricia/v2/patricia"
 )
 
-var defaultConfig = &config.Config{Server: config.ServerConfig{MaxLimit: 64, MinPrefix: 1, MaxPrefix: 60, EnableFilter: true}, Dict: config.DictConfig{
-	MaxWords:               50000,
-	ChunkSize:              10000,
-	MinFreqThreshold:       20,
-	MinFreqShortPrefix:     24,
-	MaxWordCountValidation: 1000000,
-}, CLI: config.CliConfig{DefaultLimit: 24, DefaultMinLen: 1, DefaultMaxLen: 24, DefaultNoFilter: false}}
+// defaultConfig supplies the frequency thresholds until SetFrequencyThresholds is called
+var defaultConfig = config.DefaultConfig()
 
 // Suggestion represents a word completion result with its frequency ranking.
 type Suggestion struct {
 	Word      string `msgpack:"w"`
 	Frequency int    `msgpack:"f"`
+	Tail      string `msgpack:"t,omitempty"`
+	// MatchedIndexes are the rune positions in Word that matched the prefix, see [MatchedIndexes]
+	MatchedIndexes []int `msgpack:"m,omitempty"`
 }
 
 // Completer provides trie-based word completion with lazy loading support.
@@ -34,15 +39,36 @@ type Suggestion struct {
 //
 // The completer automatically manages a fallback trie when the chunk loader
 // cannot provide an active trie, ensuring consistent operation across
-// different dictionary states.
+// different dictionary states. It is rebuilt whenever the loader's version
+// moves, so chunks loaded or evicted by a resize show up without further calls.
+//
+// [AddWord], [RemoveWord], searches and [Stats] may run concurrently in both
+// modes. A static completer guards its dictionary with a read-write lock that
+// searches hold for their trie walk. A lazy one passes words to its chunk
+// loader, which publishes a new trie for each change and leaves the ones being
+// walked untouched. Runtime words are kept in a small trie of their own, so
+// adding or removing one doesn't copy the loaded chunks.
 type Completer struct {
-	trie               *patricia.Trie
-	totalWords         int
-	maxFrequency       int
-	wordFreqs          map[string]int
-	chunkLoader        *dictionary.Loader
-	cachedFallbackTrie *patricia.Trie
-	fallbackBuilt      bool
+	trie              *patricia.Trie
+	totalWords        int
+	maxFrequency      int
+	wordFreqs         map[string]int
+	chunkLoader       *dictionary.Loader
+	fallback          atomic.Pointer[fallbackTrie]
+	hotCache          *HotCache
+	foldIndex         *foldIndex
+	sortMode          SortMode
+	preferInflections bool
+	thresholds        atomic.Pointer[FrequencyThresholds]
+	userWordsPath     string
+	blacklist         *Blacklist
+	version           atomic.Uint64
+	// staticMu guards trie, wordFreqs, totalWords, maxFrequency and unsorted of
+	// static completers; lazy ones leave that to the chunk loader
+	staticMu sync.RWMutex
+	// unsorted is set when words were added or removed since the trie was last
+	// sorted, see readLockStatic
+	unsorted bool
 }
 
 // NewCompleter creates a new completer for static word addition.
@@ -54,6 +80,7 @@ func NewCompleter() *Completer {
 	return &Completer{
 		trie:      patricia.NewTrie(),
 		wordFreqs: make(map[string]int),
+		blacklist: NewBlacklist(),
 	}
 }
 
@@ -67,24 +94,68 @@ func NewCompleter() *Completer {
 // This mode is recommended for large dictionaries where loading all words
 // into memory at startup would be prohibitive. The chunk loader manages
 // memory usage by loading only the most relevant portions of the dictionary.
-func NewLazyCompleter(dirPath string, chunkSize, maxWords int) *Completer {
-	return &Completer{
+//
+// When useHotCache is true, results for repeated prefixes are served from a
+// [HotCache] populated from the active trie, skipping the full traversal.
+// When the dictionary changes the cache is rebuilt in the background, and
+// searches go to the trie until it is ready again.
+func NewLazyCompleter(dirPath string, chunkSize, maxWords int, useHotCache bool) *Completer {
+	c := &Completer{
 		trie:        patricia.NewTrie(),
 		wordFreqs:   make(map[string]int),
 		chunkLoader: dictionary.NewLoader(dirPath, maxWords),
+		blacklist:   NewBlacklist(),
 	}
+	if useHotCache {
+		c.hotCache = NewHotCache(0, 0)
+	}
+	return c
 }
 
-//go:inline
+// AddWord adds a word to the dictionary with the given frequency.
+//
+// For lazy completers the word goes to the chunk loader, where it stays on top
+// of the loaded chunks and takes part in the next [Complete] call.
 func (c *Completer) AddWord(word string, frequency int) {
-	c.trie.Insert(patricia.Prefix(word), frequency)
+	if c.chunkLoader != nil {
+		if err := c.chunkLoader.AddWord(word, frequency); err != nil {
+			log.Warnf("Failed to add word %q: %v", word, err)
+		}
+		return
+	}
+	c.staticMu.Lock()
+	defer c.staticMu.Unlock()
+	// Set replaces the frequency of a word added before, Insert would keep it
+	c.trie.Set(patricia.Prefix(word), frequency)
+	if _, exists := c.wordFreqs[word]; !exists {
+		c.totalWords++
+	}
 	c.wordFreqs[word] = frequency
-	c.totalWords++
+	c.unsorted = true
+	c.version.Add(1)
 	if frequency > c.maxFrequency {
 		c.maxFrequency = frequency
 	}
 }
 
+// RemoveWord deletes a word from the dictionary and reports whether it was present.
+func (c *Completer) RemoveWord(word string) bool {
+	if c.chunkLoader != nil {
+		return c.chunkLoader.RemoveWord(word)
+	}
+	c.staticMu.Lock()
+	defer c.staticMu.Unlock()
+	if _, exists := c.wordFreqs[word]; !exists {
+		return false
+	}
+	c.trie.Delete(patricia.Prefix(word))
+	delete(c.wordFreqs, word)
+	c.totalWords--
+	c.unsorted = true
+	c.version.Add(1)
+	return true
+}
+
 // Complete returns word suggestions for a given prefix.
 //
 // Complete searches the completer's dictionary for words beginning with the
@@ -94,37 +165,307 @@ func (c *Completer) AddWord(word string, frequency int) {
 //
 // The prefix parameter preserves the original capitalization, which is applied
 // to all returned suggestions. For example, searching for "HEL" will return
-// suggestions like "HELLO" and "HELP" with matching capitalization.
+// suggestions like "HELLO" and "HELP" with matching capitalization, "Hel" gives
+// "Hello" and mixed input like "hEl" keeps its capitals in place, "hEllo".
 //
 // If the completer uses a chunk loader and no active trie is available,
 // Complete builds and caches a fallback trie from loaded word frequencies.
-// This cached trie is reused across subsequent calls for efficiency.
+// This cached trie is reused across subsequent calls until the loaded words change.
 //
 // Frequency thresholds are automatically adjusted based on prefix length:
 // shorter prefixes (≤2 characters) use a higher threshold to reduce noise,
 // while longer prefixes use the standard threshold for broader results.
 //
+// A limit of 0 or less returns [DefaultLimit] suggestions, the same default the
+// server uses for requests without one. There is no way to ask for every match.
+//
 // Complete returns an empty slice if no matches are found or if an error
-// occurs during trie traversal.
+// occurs during trie traversal, which is logged. [CompleteWithContext] returns it instead.
+//
+// Complete is [CompleteWithOptions] with only the prefix and limit set.
 func (c *Completer) Complete(prefix string, limit int) []Suggestion {
-	return c.complete(prefix, limit)
+	return c.CompleteWithOptions(CompletionOptions{Prefix: prefix, Limit: limit})
 }
 
+// DefaultLimit is how many suggestions a search returns when its limit is 0 or less.
+// It is half the default server.max_limit, like the server's default for requests.
+const DefaultLimit = 32
+
+// resolveLimit returns limit, or [DefaultLimit] when it is 0 or less
+func resolveLimit(limit int) int {
+	if limit <= 0 {
+		return DefaultLimit
+	}
+	return limit
+}
+
+// complete runs a search with opts, its After is left to the caller
+//
 //go:inline
-func (c *Completer) complete(prefix string, limit int) []Suggestion {
+func (c *Completer) complete(opts CompletionOptions) []Suggestion {
+	suggestions, err := c.completeContext(context.Background(), opts)
+	if err != nil {
+		log.Error(err)
+	}
+	return suggestions
+}
+
+// completeContext is complete stopping the trie walk once ctx is done.
+// The words found until then are returned, ranked, with ctx's error,
+// and are kept out of the hot cache since they may miss better ones.
+// A failed walk returns no words and its error.
+func (c *Completer) completeContext(ctx context.Context, opts CompletionOptions) ([]Suggestion, error) {
+	defer c.readLockStatic()()
+	prefix, limit := opts.Prefix, opts.Limit
 	activeTrie := c.getActiveTrie()
 	lowerPrefix, capitalInfo := utils.GetCapitalDetails(prefix)
+	if opts.Canonical {
+		capitalInfo = nil
+	}
 	minFrequencyThreshold := c.getFrequencyThreshold(lowerPrefix)
+	if opts.MinFreq > 0 {
+		minFrequencyThreshold = opts.MinFreq
+	}
+	sortMode := c.sortMode
+	if opts.SortMode != nil {
+		sortMode = *opts.SortMode
+	}
+	skip := c.skipFilter(opts)
 
-	suggestions := SearchTrie(activeTrie, lowerPrefix, minFrequencyThreshold, limit)
-	c.sortAndLimitSuggestions(&suggestions, limit)
+	if c.foldIndex != nil {
+		suggestions, err := c.foldIndex.search(ctx, activeTrie, c.Version(), lowerPrefix, minFrequencyThreshold, limit, skip)
+		c.orderSuggestions(suggestions, activeTrie, lowerPrefix, sortMode)
+		c.applyCapitalization(suggestions, capitalInfo)
+		return suggestions, err
+	}
+
+	// The hot cache holds unbounded results, so length bounded searches skip it,
+	// and so do searches while it is rebuilt for a newer dictionary
+	useHotCache := c.hotCache != nil && !opts.limitsLength()
+	if useHotCache && !c.hotCache.Current(c.Version()) {
+		c.refreshHotCache()
+		useHotCache = false
+	}
+	if useHotCache {
+		if cached, ok := c.hotCache.Lookup(lowerPrefix, minFrequencyThreshold, limit); ok {
+			c.orderSuggestions(cached, activeTrie, lowerPrefix, sortMode)
+			c.applyCapitalization(cached, capitalInfo)
+			return cached, nil
+		}
+	}
+
+	suggestions, err := searchTrieContext(ctx, activeTrie, lowerPrefix, minFrequencyThreshold, limit, skip)
+	c.sortAndLimitSuggestions(&suggestions, limit, nil)
+	if useHotCache && err == nil {
+		c.hotCache.Store(lowerPrefix, minFrequencyThreshold, limit, suggestions)
+	}
+	c.orderSuggestions(suggestions, activeTrie, lowerPrefix, sortMode)
 	c.applyCapitalization(suggestions, capitalInfo)
 
+	return suggestions, err
+}
+
+// CompleteWithTail works like [Complete] and also fills in each suggestion's Tail.
+//
+// The tail is the part of the word left to type after the prefix, which is what
+// inline "ghost text" UIs render after the cursor. It is taken from the
+// capitalized word, so it matches what [Complete] would return.
+func (c *Completer) CompleteWithTail(prefix string, limit int) []Suggestion {
+	suggestions := c.complete(CompletionOptions{Prefix: prefix, Limit: resolveLimit(limit)})
+	for i := range suggestions {
+		suggestions[i].Tail = Tail(prefix, suggestions[i].Word)
+	}
 	return suggestions
 }
 
+// CompleteAround works like [Complete] but also considers the text after the cursor.
+//
+// The after parameter is whatever directly follows the cursor in the editor.
+// Suggestions equal to the next word in after are moved to the end of the list,
+// since accepting them would just duplicate that word. This is advisory ranking:
+// nothing is filtered out, so a short list may still contain the demoted word.
+func (c *Completer) CompleteAround(prefix, after string, limit int) []Suggestion {
+	return c.CompleteWithOptions(CompletionOptions{Prefix: prefix, Limit: limit, After: after})
+}
+
+// CompletionOptions describes a [Completer.CompleteWithOptions] search.
+// Fields left at their zero value keep the completer's defaults, so
+// CompletionOptions{Prefix: p, Limit: l} gives the same results as Complete(p, l).
+type CompletionOptions struct {
+	// Prefix is the typed text, its capitalization is applied to the results
+	Prefix string
+	// Limit is the most suggestions returned, 0 or less for [DefaultLimit]
+	Limit int
+	// MinFreq replaces the frequency threshold picked from the prefix length
+	MinFreq int
+	// MinLen and MaxLen bound the length of suggestions in runes, 0 leaves that side open
+	MinLen int
+	MaxLen int
+	// SortMode replaces the order set with [SetSortMode] for this search
+	SortMode *SortMode
+	// After is the text right after the cursor, as in [CompleteAround]
+	After string
+	// Canonical returns words as stored in the dictionary, without the prefix's
+	// capitals, for clients that show them as is and apply [ApplyCasing] on accept
+	Canonical bool
+}
+
+// limitsLength reports whether the options bound suggestion length
+func (o CompletionOptions) limitsLength() bool {
+	return o.MinLen > 0 || o.MaxLen > 0
+}
+
+// fitsLength reports whether word is within the length bounds
+func (o CompletionOptions) fitsLength(word string) bool {
+	length := utf8.RuneCountInString(word)
+	return length >= o.MinLen && (o.MaxLen <= 0 || length <= o.MaxLen)
+}
+
+// CompleteWithOptions is the one entry point behind [Complete] and its variants,
+// taking every search setting in opts.
+//
+// Words outside the MinLen/MaxLen bounds are skipped while the trie is walked,
+// so they never take the place of a word that fits, and up to Limit suggestions
+// of the right length are returned. Bounded searches bypass the hot cache.
+func (c *Completer) CompleteWithOptions(opts CompletionOptions) []Suggestion {
+	suggestions, err := c.CompleteWithContext(context.Background(), opts)
+	if err != nil {
+		log.Error(err)
+	}
+	return suggestions
+}
+
+// CompleteWithContext works like [CompleteWithOptions] but gives up on the
+// search once ctx is done, so a huge subtree can't hold up the caller.
+//
+// The context is checked every few hundred trie nodes while the subtree is
+// walked. When it is done, the words found so far are returned, ranked and
+// capitalized as usual, together with ctx's error. They may miss better
+// matches further down the subtree, so callers usually treat them as a failure.
+// Results served from the hot cache are never cut short.
+//
+// Unlike [CompleteWithOptions], which logs it and returns no suggestions,
+// a failed trie walk is returned as an error, so callers can tell it apart
+// from a prefix without matches.
+func (c *Completer) CompleteWithContext(ctx context.Context, opts CompletionOptions) ([]Suggestion, error) {
+	opts.Limit = resolveLimit(opts.Limit)
+	next := utils.FirstWord(opts.After)
+	if next == "" {
+		return c.completeContext(ctx, opts)
+	}
+	limit := opts.Limit
+	opts.Limit++
+	suggestions, err := c.completeContext(ctx, opts)
+	demoteWord(suggestions, next)
+	if len(suggestions) > limit {
+		suggestions = suggestions[:limit]
+	}
+	return suggestions, err
+}
+
+// demoteWord moves suggestions matching word (case-insensitively) to the end,
+// keeping the relative order of everything else.
+func demoteWord(suggestions []Suggestion, word string) {
+	sort.SliceStable(suggestions, func(i, j int) bool {
+		return !strings.EqualFold(suggestions[i].Word, word) && strings.EqualFold(suggestions[j].Word, word)
+	})
+}
+
+// Casing returns how the capitals of prefix are applied to its suggestions:
+// "none" when it is lowercase, "upper" when all-caps ("HEL" gives "HELLO"),
+// "title" for a leading capital only ("Hel" gives "Hello"), and "mixed" when
+// each capital is copied to its position ("hEl" gives "hEllo").
+func Casing(prefix string) string {
+	_, capitalInfo := utils.GetCapitalDetails(prefix)
+	return capitalInfo.Casing()
+}
+
+// CapitalPositions returns the rune positions of the capitals in prefix, which
+// "mixed" [Casing] copies into suggestions. It is nil for a lowercase prefix.
+func CapitalPositions(prefix string) []int {
+	_, capitalInfo := utils.GetCapitalDetails(prefix)
+	return capitalInfo.Positions()
+}
+
+// ApplyCasing capitalizes word the way a search for prefix would, so a client
+// listing canonical words (see [CompletionOptions].Canonical) can match the
+// typed casing when one is accepted.
+func ApplyCasing(word, prefix string) string {
+	_, capitalInfo := utils.GetCapitalDetails(prefix)
+	return utils.CapitalizeAtPositions(word, capitalInfo)
+}
+
+// Tail returns the part of word that follows prefix.
+// It cuts by rune count, so multi-byte characters are never split.
+func Tail(prefix, word string) string {
+	n := utf8.RuneCountInString(prefix)
+	for i := range word {
+		if n == 0 {
+			return word[i:]
+		}
+		n--
+	}
+	return ""
+}
+
+// MatchedIndexes returns the rune positions in word that the prefix matched,
+// for underlining the typed part of a suggestion. Positions count runes, not
+// bytes, so they index the same characters a UI shows. Prefix completion
+// always matches the leading runes, so this is 0 up to the prefix length.
+func MatchedIndexes(prefix, word string) []int {
+	n := min(utf8.RuneCountInString(prefix), utf8.RuneCountInString(word))
+	indexes := make([]int, n)
+	for i := range indexes {
+		indexes[i] = i
+	}
+	return indexes
+}
+
+// refreshHotCache rebuilds the hot cache from the current dictionary in the
+// background. Walking and sorting the whole dictionary takes too long for the
+// request that notices the change, so searches don't wait for it.
+func (c *Completer) refreshHotCache() {
+	c.hotCache.rebuild(func() {
+		defer c.readLockStatic()()
+		// The version is read first, a change in between only makes the cache stale again
+		version := c.Version()
+		c.hotCache.populate(c.getActiveTrie(), version, c.blockedFilter())
+	})
+}
+
+// readLockStatic read-locks a static completer's dictionary for a search and
+// returns the unlock, a no-op for lazy completers whose loader synchronizes.
+// Words can't be added or removed until it is called.
+//
+// patricia sorts a node's children in place the first time they are walked
+// after a change, which concurrent searches can't do under a read lock. So the
+// first search after words were added or removed sorts the trie under the
+// write lock before taking the read lock.
+func (c *Completer) readLockStatic() func() {
+	if c.chunkLoader != nil {
+		return func() {}
+	}
+	c.staticMu.RLock()
+	for c.unsorted {
+		c.staticMu.RUnlock()
+		c.staticMu.Lock()
+		if c.unsorted {
+			sortTrie(c.trie)
+			c.unsorted = false
+		}
+		c.staticMu.Unlock()
+		c.staticMu.RLock()
+	}
+	return c.staticMu.RUnlock
+}
+
+// sortTrie walks a trie once, leaving later walks only reading it
+func sortTrie(trie *patricia.Trie) {
+	trie.Visit(func(patricia.Prefix, patricia.Item) error { return nil })
+}
+
 //go:inline
-func (c *Completer) getActiveTrie() *patricia.Trie {
+func (c *Completer) getActiveTrie() dictionary.Trie {
 	if c.chunkLoader == nil {
 		return c.trie
 	}
@@ -134,41 +475,93 @@ func (c *Completer) getActiveTrie() *patricia.Trie {
 	return c.getFallbackTrie()
 }
 
+// fallbackTrie is a trie built from the chunk loader's words and the loader
+// version it was built at
+type fallbackTrie struct {
+	trie    *patricia.Trie
+	version uint64
+}
+
+// getFallbackTrie returns the cached fallback trie, rebuilding it first if the
+// loader has loaded, evicted, added or removed words since it was built
+//
 //go:inline
 func (c *Completer) getFallbackTrie() *patricia.Trie {
-	if c.fallbackBuilt {
-		return c.cachedFallbackTrie
+	version := c.chunkLoader.Version()
+	if cached := c.fallback.Load(); cached != nil && cached.version == version {
+		return cached.trie
 	}
-	return c.buildFallbackTrie()
+	return c.buildFallbackTrie(version)
 }
 
-func (c *Completer) buildFallbackTrie() *patricia.Trie {
-	c.cachedFallbackTrie = patricia.NewTrie()
+// buildFallbackTrie builds and caches the fallback trie. version is read before
+// the words, so a change in between leaves a stale version and a rebuild next time.
+func (c *Completer) buildFallbackTrie(version uint64) *patricia.Trie {
+	trie := patricia.NewTrie()
 	wordFreqs := c.chunkLoader.GetWordFreqs()
 	for word, freq := range wordFreqs {
-		c.cachedFallbackTrie.Insert(patricia.Prefix(word), freq)
+		trie.Insert(patricia.Prefix(word), freq)
 	}
-	c.fallbackBuilt = true
-	return c.cachedFallbackTrie
+	// Searches walk the cached trie concurrently, so it is sorted before it is shared
+	sortTrie(trie)
+	c.fallback.Store(&fallbackTrie{trie: trie, version: version})
+	return trie
 }
 
 //go:inline
 func (c *Completer) getFrequencyThreshold(lowerPrefix string) int {
+	thresholds := c.FrequencyThresholds()
 	if len(lowerPrefix) <= 2 || utils.IsRepetitive(lowerPrefix) {
-		return defaultConfig.Dict.MinFreqShortPrefix
+		return thresholds.ShortPrefix
 	}
-	return defaultConfig.Dict.MinFreqThreshold
+	return thresholds.Min
 }
 
-func (c *Completer) sortAndLimitSuggestions(suggestions *[]Suggestion, limit int) {
-	sort.Slice(*suggestions, func(i, j int) bool {
-		return (*suggestions)[i].Frequency > (*suggestions)[j].Frequency
-	})
+// FrequencyThresholds are the lowest frequencies words need to be suggested,
+// dict.min_frequency_threshold and dict.min_frequency_short_prefix in the config.
+type FrequencyThresholds struct {
+	// Min applies to prefixes longer than 2 characters
+	Min int
+	// ShortPrefix applies to prefixes of 1 or 2 characters and repetitive ones like "aaa"
+	ShortPrefix int
+}
+
+// SetFrequencyThresholds replaces the default thresholds of 20 and 24.
+// It is safe to call while completions run, e.g. after a config reload;
+// searches already running finish with the old thresholds.
+func (c *Completer) SetFrequencyThresholds(thresholds FrequencyThresholds) {
+	c.thresholds.Store(&thresholds)
+}
+
+// FrequencyThresholds returns the thresholds in effect
+func (c *Completer) FrequencyThresholds() FrequencyThresholds {
+	if thresholds := c.thresholds.Load(); thresholds != nil {
+		return *thresholds
+	}
+	return FrequencyThresholds{Min: defaultConfig.Dict.MinFreqThreshold, ShortPrefix: defaultConfig.Dict.MinFreqShortPrefix}
+}
+
+// sortAndLimitSuggestions sorts suggestions with compare, [byFrequency] when nil,
+// and keeps the first limit of them. A limit of 0 keeps all.
+func (c *Completer) sortAndLimitSuggestions(suggestions *[]Suggestion, limit int, compare comparator) {
+	if compare == nil {
+		compare = byFrequency
+	}
+	slices.SortFunc(*suggestions, compare)
 	if len(*suggestions) > limit && limit > 0 {
 		*suggestions = (*suggestions)[:limit]
 	}
 }
 
+// orderSuggestions puts frequency-sorted suggestions in the order results are
+// returned in: sorted by mode, then with inflections first if preferred
+func (c *Completer) orderSuggestions(suggestions []Suggestion, trie dictionary.Trie, lowerPrefix string, mode SortMode) {
+	orderSuggestions(suggestions, mode)
+	if c.preferInflections {
+		promoteInflections(suggestions, trie, lowerPrefix)
+	}
+}
+
 //go:inline
 func (c *Completer) applyCapitalization(suggestions []Suggestion, capitalInfo *utils.CapitalInfo) {
 	if capitalInfo == nil {
@@ -192,19 +585,22 @@ func (c *Completer) applyCapitalization(suggestions []Suggestion, capitalInfo *u
 // the original prefix casing.
 //
 // Unlike the callback-based trie functions, CompleteWithCallback sorts results
-// by frequency before delivery, providing the same ordering guarantees as [Complete].
-// However, this requires collecting all results before sorting, which uses some
-// temporary memory.
+// by frequency before delivery, providing the same results and ordering as [Complete].
+// However, this requires collecting the best limit results before delivery, which
+// uses some temporary memory.
 //
 // CompleteWithCallback returns an error if trie traversal fails, or nil on success.
 // The number of suggestions delivered may be less than the limit if the callback
-// returns false or if fewer matches are found.
+// returns false or if fewer matches are found. A limit of 0 or less delivers up
+// to [DefaultLimit], as in [Complete]. On a static completer the callback runs
+// while the dictionary is read-locked, so it must not add or remove words.
 func (c *Completer) CompleteWithCallback(prefix string, limit int, callback func(Suggestion) bool) error {
-	return c.completeWithCallback(prefix, limit, callback)
+	return c.completeWithCallback(prefix, resolveLimit(limit), callback)
 }
 
 //go:inline
 func (c *Completer) completeWithCallback(prefix string, limit int, callback func(Suggestion) bool) error {
+	defer c.readLockStatic()()
 	activeTrie := c.getActiveTrie()
 	lowerPrefix, capitalInfo := utils.GetCapitalDetails(prefix)
 	minFrequencyThreshold := c.getFrequencyThreshold(lowerPrefix)
@@ -214,18 +610,46 @@ func (c *Completer) completeWithCallback(prefix string, limit int, callback func
 		return err
 	}
 
-	c.sortAndLimitSuggestions(&suggestions, limit)
+	c.sortAndLimitSuggestions(&suggestions, limit, nil)
+	c.orderSuggestions(suggestions, activeTrie, lowerPrefix, c.sortMode)
 	return c.deliverSuggestions(suggestions, capitalInfo, callback)
 }
 
-//go:inline
-func (c *Completer) collectSuggestions(trie *patricia.Trie, lowerPrefix string, minFrequencyThreshold, limit int) ([]Suggestion, error) {
-	suggestions := make([]Suggestion, 0, limit*2)
-	err := SearchTrieWithCallback(trie, lowerPrefix, minFrequencyThreshold, limit*2, func(s Suggestion) bool {
-		suggestions = append(suggestions, s)
-		return true
+// CompleteUnsorted delivers matches of prefix to callback as the trie walk finds them,
+// for UIs that want the first match as early as possible.
+//
+// There is no ordering guarantee: words arrive in trie order, not by frequency,
+// and need not be the most frequent matches, since the walk stops after limit
+// words or as soon as callback returns false. The sort mode is ignored. Nothing
+// is collected in between, so no intermediate slice is allocated. Frequency
+// thresholds, the blacklist and capitalization apply as in [Complete], while
+// accents are matched as typed even with [SetFoldDiacritics] on.
+// A limit of 0 or less delivers up to [DefaultLimit].
+//
+// Use [CompleteWithCallback] or [CompleteTopKStreaming] when the best words matter.
+// As with CompleteWithCallback, the callback must not add or remove words.
+//
+// CompleteUnsorted returns an error if the trie walk fails, or nil on success,
+// including when callback stopped it.
+func (c *Completer) CompleteUnsorted(prefix string, limit int, callback func(Suggestion) bool) error {
+	defer c.readLockStatic()()
+	activeTrie := c.getActiveTrie()
+	lowerPrefix, capitalInfo := utils.GetCapitalDetails(prefix)
+	minFrequencyThreshold := c.getFrequencyThreshold(lowerPrefix)
+	return searchTrieWithCallback(activeTrie, lowerPrefix, minFrequencyThreshold, resolveLimit(limit), c.blockedFilter(), func(s Suggestion) bool {
+		if capitalInfo != nil {
+			s.Word = utils.CapitalizeAtPositions(s.Word, capitalInfo)
+		}
+		return callback(s)
 	})
-	return suggestions, err
+}
+
+// collectSuggestions returns the limit most frequent matches, as [Complete] finds them.
+// Taking the first words in trie order instead would miss frequent words that come late.
+//
+//go:inline
+func (c *Completer) collectSuggestions(trie dictionary.Trie, lowerPrefix string, minFrequencyThreshold, limit int) ([]Suggestion, error) {
+	return searchTrieContext(context.Background(), trie, lowerPrefix, minFrequencyThreshold, limit, c.blockedFilter())
 }
 
 //go:inline
@@ -246,12 +670,89 @@ func (c *Completer) LoadBinaryDictionary(filename string) error {
 	return c.Initialize()
 }
 
+// SetSortMode sets the order [Complete] and [CompleteWithCallback] return results in.
+//
+// The most frequent matches are picked first, then reordered by mode, so an
+// alphabetical list still holds the words a frequency list would.
+func (c *Completer) SetSortMode(mode SortMode) {
+	c.sortMode = mode
+}
+
+// SetFoldDiacritics turns diacritic-insensitive matching on or off.
+//
+// With folding on, accents are ignored on both sides of the match: "cafe" finds
+// "café" and "cafeteria", and "resumé" finds "resume". Suggestions keep the
+// spelling they have in the dictionary. Folded searches bypass the hot cache.
+func (c *Completer) SetFoldDiacritics(enabled bool) {
+	if !enabled {
+		c.foldIndex = nil
+		return
+	}
+	if c.foldIndex == nil {
+		c.foldIndex = newFoldIndex()
+	}
+}
+
+// SetUserWordsPath sets a user dictionary saved with [dictionary.Loader.SaveUserWords]
+// that [Initialize] layers on top of the loaded chunks. Only lazy completers use it.
+func (c *Completer) SetUserWordsPath(path string) {
+	c.userWordsPath = path
+}
+
+// Blacklist returns the words this completer never suggests.
+//
+// Changes to it apply from the next search. Matching ignores case, and with
+// [SetFoldDiacritics] on it ignores accents too.
+func (c *Completer) Blacklist() *Blacklist {
+	return c.blacklist
+}
+
+// blockedFilter returns the blacklist check for searches, or nil when nothing is blacklisted
+func (c *Completer) blockedFilter() func(word string) bool {
+	set := c.blacklist.set.Load()
+	if len(set.words) == 0 {
+		return nil
+	}
+	fold := c.foldIndex != nil
+	return func(word string) bool {
+		return set.blocks(word, fold)
+	}
+}
+
+// skipFilter returns the check for words a search leaves out, the blacklisted ones
+// and those outside the length bounds of opts, or nil when none are left out
+func (c *Completer) skipFilter(opts CompletionOptions) func(word string) bool {
+	blocked := c.blockedFilter()
+	if !opts.limitsLength() {
+		return blocked
+	}
+	return func(word string) bool {
+		return !opts.fitsLength(word) || (blocked != nil && blocked(word))
+	}
+}
+
+// Initialize starts loading dictionary chunks for lazy completers.
+//
+// It returns an error if the chunk loader cannot find or prepare any
+// dictionary chunks, rather than leaving the completer silently empty.
+// Static completers have nothing to load and always return nil.
+//
+// Words from the user dictionary set with [SetUserWordsPath] are added on top
+// of the chunks; a broken user dictionary is logged and skipped.
 func (c *Completer) Initialize() error {
 	if c.chunkLoader != nil {
 		if err := c.chunkLoader.StartLoading(); err != nil {
 			return err
 		}
+		if c.userWordsPath != "" {
+			if err := c.chunkLoader.LoadUserWords(c.userWordsPath); err != nil {
+				log.Warnf("Continuing without user words: %v", err)
+			}
+		}
 		c.syncFromLoader()
+		if c.hotCache != nil {
+			c.hotCache.populate(c.getActiveTrie(), c.Version(), c.blockedFilter())
+		}
 
 		return nil
 	}
@@ -294,6 +795,36 @@ func (c *Completer) ForceCleanup() {
 	runtime.GC()
 }
 
+// Version returns the dictionary version, which increases whenever words are
+// added, loaded, evicted or blacklisted. Results cached under an older version are stale.
+func (c *Completer) Version() uint64 {
+	version := c.version.Load() + c.blacklist.Version()
+	if c.chunkLoader != nil {
+		version += c.chunkLoader.Version()
+	}
+	return version
+}
+
+// WordChunk returns the ID of the chunk a suggested word came from, for debugging
+// partly loaded dictionaries. It is 0 for runtime words and in static mode.
+func (c *Completer) WordChunk(word string) int {
+	if c.chunkLoader == nil {
+		return 0
+	}
+	chunkID, _ := c.chunkLoader.WordChunk(strings.ToLower(word))
+	return chunkID
+}
+
+// FrequencyPercentile returns the share of loaded words less frequent than freq,
+// from 0 to 100, see [dictionary.FrequencyDistribution.Percentile].
+// It is 0 in static mode, where there is no chunk loader to rank against.
+func (c *Completer) FrequencyPercentile(freq int) float64 {
+	if c.chunkLoader == nil {
+		return 0
+	}
+	return c.chunkLoader.Distribution().Percentile(freq)
+}
+
 //go:inline
 func (c *Completer) Stats() map[string]int {
 	return c.buildStatsMap()
@@ -301,10 +832,18 @@ func (c *Completer) Stats() map[string]int {
 
 //go:inline
 func (c *Completer) buildStatsMap() map[string]int {
-	stats := make(map[string]int, 6)
+	stats := make(map[string]int, 8)
+	c.staticMu.RLock()
 	stats["totalWords"] = c.totalWords
 	stats["maxFrequency"] = c.maxFrequency
+	c.staticMu.RUnlock()
+	stats["dictVersion"] = int(c.Version())
 	c.addLoaderStats(stats)
+	if c.hotCache != nil {
+		hits, _, words := c.hotCache.Stats()
+		stats["hotCacheHits"] = hits
+		stats["hotCacheWords"] = words
+	}
 	return stats
 }
 
@@ -325,10 +864,11 @@ func (c *Completer) GetChunkLoader() *dictionary.Loader {
 	return c.chunkLoader
 }
 
-// InvalidateFallbackCache clears the cached fallback trie when chunk loader state changes
+// InvalidateFallbackCache clears the cached fallback trie, so the next search rebuilds it.
+// The completer already does this when the chunk loader's version changes, so calling
+// it is only needed after changing loader state in a way that doesn't bump the version.
 //
 //go:inline
 func (c *Completer) InvalidateFallbackCache() {
-	c.cachedFallbackTrie = nil
-	c.fallbackBuilt = false
+	c.fallback.Store(nil)
 }
@@ -0,0 +1,119 @@
+package suggest
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bastiangx/wordserve/pkg/dictionary"
+)
+
+// offlineRelease serves 404 for every release file, so a loader pointed at it
+// can't fall back to downloading the dictionary
+func offlineRelease(t testing.TB) string {
+	t.Helper()
+	server := httptest.NewServer(http.NotFoundHandler())
+	t.Cleanup(server.Close)
+	return server.URL
+}
+
+func TestInitializeEmptyDirFails(t *testing.T) {
+	completer := NewLazyCompleter(t.TempDir(), 1000, 1000, false)
+	completer.GetChunkLoader().SetReleaseURL(offlineRelease(t))
+	defer completer.Stop()
+
+	if err := completer.Initialize(); err == nil {
+		t.Fatal("Initialize on an empty data dir returned no error")
+	}
+	if stats := completer.Stats(); stats["totalWords"] != 0 {
+		t.Errorf("totalWords = %d, want 0", stats["totalWords"])
+	}
+}
+
+// newTestCompleter builds chunks of chunkSize words from words, most frequent
+// first, and returns a lazy completer that has loaded all of them.
+// The config is kept to a temp dir and matched to the chunks, so nothing is
+// rebuilt or downloaded.
+func newTestCompleter(t testing.TB, words []string, chunkSize int, useHotCache bool) *Completer {
+	t.Helper()
+	dir := t.TempDir()
+	var lines strings.Builder
+	for i, word := range words {
+		fmt.Fprintf(&lines, "%s\t%d\n", word, len(words)-i)
+	}
+	wordsPath := filepath.Join(dir, "words.txt")
+	if err := os.WriteFile(wordsPath, []byte(lines.String()), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := dictionary.BuildChunks(wordsPath, dir, chunkSize, 0); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	t.Setenv("WORDSERVE_MAX_WORDS", strconv.Itoa(len(words)))
+	t.Setenv("WORDSERVE_CHUNK_SIZE", strconv.Itoa(chunkSize))
+
+	completer := NewLazyCompleter(dir, chunkSize, 0, useHotCache)
+	completer.GetChunkLoader().SetReleaseURL(offlineRelease(t))
+	t.Cleanup(completer.Stop)
+	if err := completer.Initialize(); err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := completer.GetChunkLoader().WaitUntilReady(ctx); err != nil {
+		t.Fatal(err)
+	}
+	return completer
+}
+
+// words returns the words of suggestions in order
+func words(suggestions []Suggestion) []string {
+	result := make([]string, len(suggestions))
+	for i, s := range suggestions {
+		result[i] = s.Word
+	}
+	return result
+}
+
+func TestCompleteKeepsPrefixCasing(t *testing.T) {
+	completer := newStaticCompleter(map[string]int{"hello": 900, "help": 800})
+	tests := map[string][]string{
+		"HEL": {"HELLO", "HELP"},
+		"Hel": {"Hello", "Help"},
+		"hEl": {"hEllo", "hElp"},
+		"hel": {"hello", "help"},
+	}
+	for prefix, want := range tests {
+		if got := words(completer.Complete(prefix, 5)); !slices.Equal(got, want) {
+			t.Errorf("Complete(%q) = %v, want %v", prefix, got, want)
+		}
+	}
+}
+
+func TestCompleteMatchesCompleteWithOptions(t *testing.T) {
+	completers := map[string]*Completer{
+		"static": newStaticCompleter(randomDictionary(2000)),
+		"lazy":   newTestCompleter(t, []string{"hello", "help", "helmet", "held", "hero", "world", "word"}, 3, true),
+	}
+	prefixes := []string{"", "a", "Ab", "ABC", "abcde", "he", "Hel", "HELL", "wor", "zz"}
+	for name, completer := range completers {
+		for _, prefix := range prefixes {
+			for _, limit := range []int{0, 1, 5, 100} {
+				got := completer.Complete(prefix, limit)
+				want := completer.CompleteWithOptions(CompletionOptions{Prefix: prefix, Limit: limit})
+				if !reflect.DeepEqual(got, want) {
+					t.Errorf("%s: Complete(%q, %d) = %v, CompleteWithOptions = %v", name, prefix, limit, got, want)
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,84 @@
+package suggest
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/tchap/go-patricia/v2/patricia"
+)
+
+// rounds is how many times each goroutine of hammer repeats its call
+const rounds = 200
+
+// hammer runs AddWord, RemoveWord, searches and Stats on completer at the same
+// time, for go test -race to catch unguarded access
+func hammer(t *testing.T, completer *Completer) {
+	t.Helper()
+	var wg sync.WaitGroup
+	run := func(fn func(i int)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range rounds {
+				fn(i)
+			}
+		}()
+	}
+	run(func(i int) { completer.AddWord(fmt.Sprintf("hel%03d", i), 1000+i) })
+	run(func(i int) { completer.AddWord(fmt.Sprintf("help%02d", i%10), 2000+i) })
+	run(func(i int) {
+		if i%2 == 1 {
+			completer.RemoveWord(fmt.Sprintf("hel%03d", i-1))
+		}
+	})
+	run(func(int) { completer.Complete("hel", 5) })
+	run(func(int) { completer.Complete("help", 5) })
+	run(func(int) { completer.CountMatches("he", 0) })
+	run(func(int) { completer.Stats() })
+	wg.Wait()
+
+	// Each help## word keeps the frequency it was last added with
+	got := completer.Complete("help0", 20)
+	if len(got) != 10 {
+		t.Fatalf("Complete(\"help0\") = %v after the concurrent adds, want 10 words", got)
+	}
+	for i, suggestion := range got {
+		want := Suggestion{Word: fmt.Sprintf("help%02d", 9-i), Frequency: 2000 + rounds - 1 - i}
+		if suggestion.Word != want.Word || suggestion.Frequency != want.Frequency {
+			t.Errorf("Complete(\"help0\")[%d] = %+v, want %+v", i, suggestion, want)
+		}
+	}
+}
+
+func TestStaticCompleterConcurrentAccess(t *testing.T) {
+	completer := newStaticCompleter(map[string]int{"hello": 500, "help": 400})
+	hammer(t, completer)
+
+	// Removes may run before their add, so count what is left; help## words
+	// were added 20 times each and must count once
+	present := 0
+	completer.trie.Visit(func(patricia.Prefix, patricia.Item) error {
+		present++
+		return nil
+	})
+	if got := completer.Stats()["totalWords"]; got != present || got != len(completer.wordFreqs) {
+		t.Errorf("totalWords = %d, want %d as many as in the trie and %d in wordFreqs", got, present, len(completer.wordFreqs))
+	}
+}
+
+func TestLazyCompleterConcurrentAccess(t *testing.T) {
+	completer := newTestCompleter(t, []string{"hello", "help", "helmet", "hero", "world"}, 2, true)
+	hammer(t, completer)
+}
+
+func TestStaticAddWordReplacesFrequency(t *testing.T) {
+	completer := newStaticCompleter(map[string]int{"hello": 500, "help": 400})
+	completer.AddWord("help", 900)
+	if got := completer.Complete("hel", 5); len(got) != 2 || got[0].Word != "help" || got[0].Frequency != 900 {
+		t.Errorf("Complete(\"hel\") after re-adding help = %v, want help first at 900", got)
+	}
+	if got := completer.Stats()["totalWords"]; got != 2 {
+		t.Errorf("totalWords after re-adding a word = %d, want 2", got)
+	}
+}
@@ -0,0 +1,53 @@
+package suggest
+
+import (
+	"errors"
+
+	"github.com/bastiangx/wordserve/internal/utils"
+	"github.com/tchap/go-patricia/v2/patricia"
+)
+
+// MaxCount is where [Completer.CountMatches] stops counting
+const MaxCount = 1000
+
+// errCountCapped ends the trie walk once MaxCount words are counted
+var errCountCapped = errors.New("count capped")
+
+// CountMatches returns how many words qualify as completions of prefix, for
+// clients that only need to know whether there are any, e.g. to decide on
+// showing a popup, without paying for sorting and capitalization.
+//
+// A word counts if it extends the prefix, is not blacklisted and its frequency
+// is at least minFreq. A minFreq of 0 or less uses the threshold [Complete]
+// picks for the prefix, so a count of 0 means Complete would return nothing.
+// Counting stops at [MaxCount], bigger subtrees all report MaxCount.
+//
+// Accents are matched as typed, even with [SetFoldDiacritics] on.
+func (c *Completer) CountMatches(prefix string, minFreq int) int {
+	defer c.readLockStatic()()
+	trie := c.getActiveTrie()
+	if trie == nil {
+		return 0
+	}
+	lowerPrefix, _ := utils.GetCapitalDetails(prefix)
+	if minFreq <= 0 {
+		minFreq = c.getFrequencyThreshold(lowerPrefix)
+	}
+	skip := c.blockedFilter()
+	count := 0
+	trie.VisitSubtree(patricia.Prefix(lowerPrefix), func(p patricia.Prefix, item patricia.Item) error {
+		if !extendsPrefix(p, lowerPrefix) {
+			return nil
+		}
+		word := string(p)
+		if extractFrequency(item, word) < minFreq || (skip != nil && skip(word)) {
+			return nil
+		}
+		count++
+		if count >= MaxCount {
+			return errCountCapped
+		}
+		return nil
+	})
+	return count
+}
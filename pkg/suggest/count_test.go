@@ -0,0 +1,51 @@
+package suggest
+
+import (
+	"strconv"
+	"testing"
+)
+
+var countWords = map[string]int{
+	"the":     900,
+	"then":    800,
+	"there":   700,
+	"these":   600,
+	"theater": 500,
+	"they":    22, // passes the threshold of longer prefixes only
+	"thee":    10, // below both thresholds
+	"thy":     300,
+	"tab":     200,
+	"table":   100,
+	"zebra":   50,
+}
+
+func TestCountMatchesAgreesWithComplete(t *testing.T) {
+	completer := newStaticCompleter(countWords)
+	completer.Blacklist().Add("table")
+	if got := completer.CountMatches("the", 0); got != 5 {
+		t.Errorf("CountMatches(\"the\", 0) = %d, want 5", got)
+	}
+	for _, prefix := range []string{"t", "th", "the", "ther", "The", "ta", "z", "zebra", "q"} {
+		count := completer.CountMatches(prefix, 0)
+		if got := len(completer.Complete(prefix, 100)); count != got {
+			t.Errorf("CountMatches(%q, 0) = %d, want %d as many as Complete returns", prefix, count, got)
+		}
+		for _, minFreq := range []int{1, 25, 600} {
+			count := completer.CountMatches(prefix, minFreq)
+			got := len(completer.CompleteWithOptions(CompletionOptions{Prefix: prefix, Limit: 100, MinFreq: minFreq}))
+			if count != got {
+				t.Errorf("CountMatches(%q, %d) = %d, want %d as many as Complete returns", prefix, minFreq, count, got)
+			}
+		}
+	}
+}
+
+func TestCountMatchesStopsAtMaxCount(t *testing.T) {
+	completer := NewCompleter()
+	for i := range MaxCount + 50 {
+		completer.AddWord("w"+strconv.Itoa(i), 100)
+	}
+	if got := completer.CountMatches("w", 0); got != MaxCount {
+		t.Errorf("CountMatches over %d words = %d, want %d", MaxCount+50, got, MaxCount)
+	}
+}
@@ -0,0 +1,89 @@
+package suggest
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/bastiangx/wordserve/pkg/dictionary"
+)
+
+// newPartialCompleter builds chunks of chunkSize words from words, most
+// frequent first, and returns a lazy completer that has loaded only the first
+func newPartialCompleter(t *testing.T, words []string, chunkSize int) *Completer {
+	t.Helper()
+	dir := t.TempDir()
+	var lines strings.Builder
+	for i, word := range words {
+		fmt.Fprintf(&lines, "%s\t%d\n", word, len(words)-i)
+	}
+	wordsPath := filepath.Join(dir, "words.txt")
+	if err := os.WriteFile(wordsPath, []byte(lines.String()), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := dictionary.BuildChunks(wordsPath, dir, chunkSize, 0); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	t.Setenv("WORDSERVE_MAX_WORDS", fmt.Sprint(chunkSize))
+	t.Setenv("WORDSERVE_CHUNK_SIZE", fmt.Sprint(chunkSize))
+
+	completer := NewLazyCompleter(dir, chunkSize, chunkSize, false)
+	completer.GetChunkLoader().SetReleaseURL(offlineRelease(t))
+	t.Cleanup(completer.Stop)
+	if err := completer.Initialize(); err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := completer.GetChunkLoader().WaitUntilReady(ctx); err != nil {
+		t.Fatal(err)
+	}
+	return completer
+}
+
+func TestResizeShowsNewlyLoadedWords(t *testing.T) {
+	dictionaryWords := make([]string, 300)
+	for i := range dictionaryWords {
+		dictionaryWords[i] = fmt.Sprintf("w%03d", i)
+	}
+	completer := newPartialCompleter(t, dictionaryWords, 100)
+	if got := completer.Complete("w2", 5); len(got) != 0 {
+		t.Fatalf("Complete(\"w2\") = %v before the resize, want nothing from the unloaded chunks", got)
+	}
+	// Build the fallback trie for the first chunk, so the resize has to replace it
+	if got := SearchTrie(completer.getFallbackTrie(), "w2", 0, 5); len(got) != 0 {
+		t.Fatalf("fallback trie has %v before the resize, want nothing", got)
+	}
+
+	if err := dictionary.NewRuntimeLoader(completer.GetChunkLoader()).SetDictionarySize(3); err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"w200", "w201", "w202", "w203", "w204"}
+	if got := words(completer.Complete("w2", 5)); !slices.Equal(got, want) {
+		t.Errorf("Complete(\"w2\") after the resize = %v, want %v", got, want)
+	}
+	if got := words(SearchTrie(completer.getFallbackTrie(), "w2", 0, 5)); !slices.Equal(got, want) {
+		t.Errorf("fallback trie after the resize has %v, want %v", got, want)
+	}
+
+	// The rebuilt fallback trie is walked by concurrent searches
+	fallback := completer.getFallbackTrie()
+	var wg sync.WaitGroup
+	for range 4 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for range 50 {
+				SearchTrie(fallback, "w", 0, 5)
+			}
+		}()
+	}
+	wg.Wait()
+}
@@ -0,0 +1,115 @@
+package suggest
+
+import (
+	"context"
+	"slices"
+	"sync"
+
+	"github.com/bastiangx/wordserve/internal/utils"
+	"github.com/bastiangx/wordserve/pkg/dictionary"
+	"github.com/tchap/go-patricia/v2/patricia"
+)
+
+// foldIndex makes completion diacritic-insensitive, so "cafe" finds "café".
+//
+// Only words that change when folded are indexed: their folded form is the trie
+// key and the item is the list of original words sharing it. A search combines
+// the main trie, queried with the folded prefix, with this index, so results
+// always carry the original accented spelling. Like [HotCache] it remembers the
+// dictionary version it was built from and is rebuilt when that moves.
+type foldIndex struct {
+	mu      sync.Mutex
+	version uint64
+	trie    *patricia.Trie
+}
+
+// foldedWord is one original word stored under a folded key
+type foldedWord struct {
+	word string
+	freq int
+}
+
+func newFoldIndex() *foldIndex {
+	return &foldIndex{}
+}
+
+// build indexes the accented words in trie. Callers must hold fi.mu.
+func (fi *foldIndex) build(trie dictionary.Trie, version uint64) {
+	index := patricia.NewTrie()
+	if trie != nil {
+		trie.Visit(func(p patricia.Prefix, item patricia.Item) error {
+			word := string(p)
+			folded := utils.FoldDiacritics(word)
+			if folded == word {
+				return nil
+			}
+			entry := foldedWord{word: word, freq: extractFrequency(item, word)}
+			entries, _ := index.Get(patricia.Prefix(folded)).([]foldedWord)
+			index.Set(patricia.Prefix(folded), append(entries, entry))
+			return nil
+		})
+	}
+	fi.trie = index
+	fi.version = version
+}
+
+// search returns suggestions whose folded form starts with the folded prefix,
+// most frequent first. The typed word itself is left out, as in [SearchTrie],
+// and so are the words skip reports, which may be nil.
+// If ctx is done during the trie walk, the folded spellings are not searched
+// and the words found so far are returned with ctx's error.
+func (fi *foldIndex) search(ctx context.Context, trie dictionary.Trie, version uint64, lowerPrefix string, minThreshold, limit int, skip func(word string) bool) ([]Suggestion, error) {
+	foldedPrefix := utils.FoldDiacritics(lowerPrefix)
+	suggestions, err := searchTrieContext(ctx, trie, foldedPrefix, minThreshold, limit, skip)
+	if err != nil {
+		return sortAndTrim(suggestions, limit), err
+	}
+	if foldedPrefix != lowerPrefix && (skip == nil || !skip(foldedPrefix)) {
+		// The unaccented spelling is a different word from the one typed
+		if freq := exactFrequency(trie, foldedPrefix); freq >= minThreshold {
+			suggestions = append(suggestions, Suggestion{Word: foldedPrefix, Frequency: freq})
+		}
+	}
+
+	seen := make(map[string]bool, len(suggestions))
+	for _, suggestion := range suggestions {
+		seen[suggestion.Word] = true
+	}
+
+	fi.mu.Lock()
+	if fi.trie == nil || fi.version != version {
+		fi.build(trie, version)
+	}
+	fi.trie.VisitSubtree(patricia.Prefix(foldedPrefix), func(p patricia.Prefix, item patricia.Item) error {
+		for _, entry := range item.([]foldedWord) {
+			if entry.word != lowerPrefix && !seen[entry.word] && entry.freq >= minThreshold && (skip == nil || !skip(entry.word)) {
+				suggestions = append(suggestions, Suggestion{Word: entry.word, Frequency: entry.freq})
+			}
+		}
+		return nil
+	})
+	fi.mu.Unlock()
+
+	return sortAndTrim(suggestions, limit), nil
+}
+
+// sortAndTrim orders suggestions by [byFrequency] and keeps at most limit of them
+func sortAndTrim(suggestions []Suggestion, limit int) []Suggestion {
+	slices.SortFunc(suggestions, byFrequency)
+	if limit > 0 && len(suggestions) > limit {
+		suggestions = suggestions[:limit]
+	}
+	return suggestions
+}
+
+// exactFrequency returns the frequency of word if it is in trie, 0 otherwise
+func exactFrequency(trie dictionary.Trie, word string) int {
+	if trie == nil {
+		return 0
+	}
+	item := trie.Get(patricia.Prefix(word))
+	if item == nil {
+		return 0
+	}
+	return extractFrequency(item, word)
+}
@@ -0,0 +1,27 @@
+package suggest
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestFoldDiacriticsMatching(t *testing.T) {
+	tests := []struct {
+		prefix string
+		want   string
+	}{
+		{"cafe", "café"},
+		{"resume", "resumé"},
+		{"naive", "naïve"},
+	}
+	completer := newStaticCompleter(map[string]int{"café": 500, "resumé": 500, "naïve": 500})
+	for _, fold := range []bool{true, false} {
+		completer.SetFoldDiacritics(fold)
+		for _, tt := range tests {
+			got := words(completer.Complete(tt.prefix, 10))
+			if found := slices.Contains(got, tt.want); found != fold {
+				t.Errorf("fold %t: Complete(%q) = %q, want %q found %t", fold, tt.prefix, got, tt.want, fold)
+			}
+		}
+	}
+}
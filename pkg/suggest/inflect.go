@@ -0,0 +1,66 @@
+package suggest
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/bastiangx/wordserve/pkg/dictionary"
+)
+
+// inflectionSuffixes are the common English endings isInflection recognises,
+// longest first so "ings" is tried before "s"
+var inflectionSuffixes = []string{"ings", "ness", "ment", "ers", "est", "ing", "er", "ed", "es", "ly", "s", "d"}
+
+// isInflection reports whether word looks like prefix with a common inflection
+// added, such as "reading" or "reader" for "read".
+//
+// It is a light heuristic, not a stemmer: word must be prefix followed by one
+// of a few English suffixes, allowing a doubled last consonant ("running").
+// Endings that change the prefix itself, like "carried" for "carry", can't be
+// completions of it and are not considered.
+func isInflection(prefix, word string) bool {
+	if prefix == "" || len(word) <= len(prefix) || !strings.HasPrefix(word, prefix) {
+		return false
+	}
+	rest := word[len(prefix):]
+	if last := prefix[len(prefix)-1]; len(rest) > 1 && rest[0] == last && !strings.ContainsRune("aeiouy", rune(last)) {
+		if doubled := rest[1:]; isInflectionSuffix(doubled) && doubled != "s" && doubled != "ly" {
+			return true
+		}
+	}
+	return isInflectionSuffix(rest)
+}
+
+// isInflectionSuffix reports whether s is one of the inflectionSuffixes
+func isInflectionSuffix(s string) bool {
+	return slices.Contains(inflectionSuffixes, s)
+}
+
+// SetPreferInflections moves inflections of a whole-word prefix ahead of other matches.
+//
+// When the typed prefix is itself a dictionary word, suggestions that look like
+// it with a common ending, "reading" and "reader" for "read", come before
+// unrelated longer words like "ready". The set of results is unchanged and each
+// group keeps the sort mode's order, so this only reorders.
+func (c *Completer) SetPreferInflections(enabled bool) {
+	c.preferInflections = enabled
+}
+
+// promoteInflections moves the inflections of lowerPrefix to the front of
+// suggestions, keeping the order within both groups, if lowerPrefix is a word in trie
+func promoteInflections(suggestions []Suggestion, trie dictionary.Trie, lowerPrefix string) {
+	if len(suggestions) < 2 || exactFrequency(trie, lowerPrefix) == 0 {
+		return
+	}
+	slices.SortStableFunc(suggestions, func(a, b Suggestion) int {
+		aInflected, bInflected := isInflection(lowerPrefix, a.Word), isInflection(lowerPrefix, b.Word)
+		switch {
+		case aInflected == bInflected:
+			return 0
+		case aInflected:
+			return -1
+		default:
+			return 1
+		}
+	})
+}
@@ -31,7 +31,7 @@ Includes special handling for TS clients (Obsidian for example) that may send fr
 
 # Memory
 
-Two sync.Pool instances manage mem alloc for suggestion collection and word deduplication.
+Two sync.Pool instances manage mem alloc for suggestion collection and, in callback searches, word deduplication.
 The suggestion pool has slices with initial capacity of 75 elements,
 while the seen words pool maps with 150.
 
@@ -69,21 +69,18 @@ for completion ops.
 # Algorithm
 
 The core  performs depth first traversal starting from the root prefix node.
-It collects suggestions that meet frequency thresholds while having deduplication through a seen-words map.
-Collection stops when reaching 1.5x the requested limit.
+It walks the whole subtree, keeping the best limit words seen so far in a bounded min-heap,
+so a frequent word late in trie order is never dropped for rarer ones found first.
 
 Node processing during traversal includes several checks:
-exact prefix matches are excluded, duplicate words are filtered, and frequency thresholds are applied before collection.
+exact prefix matches are excluded, frequency thresholds are applied, and words rarer than the worst kept one
+are rejected before their string is built.
 
 	err := trie.VisitSubtree(patricia.Prefix(lowerPrefix), func(p patricia.Prefix, item patricia.Item) error {
-		if len(suggestions) >= targetLen { return nil }
-		word := string(p)
-		if seenWords[word] { return nil }
 		freq := extractFrequency(item, word)
-		if freq < minThreshold { return nil }
-		suggestions = append(
-				suggestions, Suggestion{Word: word, Frequency: freq})
-		seenWords[word] = true
+		if freq < minThreshold || (len(best) >= limit && freq < best[0].Frequency) { return nil }
+		if len(best) < limit { heap.Push(&best, Suggestion{Word: string(p), Frequency: freq}) }
+		else { best[0] = Suggestion{Word: string(p), Frequency: freq}; heap.Fix(&best, 0) }
 		return nil
 	})
 
@@ -103,13 +100,44 @@ While this introduces temporary allocation, it mainly eliminates the final resul
 		return true
 	})
 
+CompleteUnsorted drops the ordering guarantee for latency: words are delivered in trie order
+as the walk finds them, with nothing collected or sorted, and the walk ends at the limit or
+when the callback returns false.
+
+	err := completer.CompleteUnsorted("prefix", 20, func(s Suggestion) bool {
+		show(s.Word)
+		return false // the first match is enough
+	})
+
+# Streaming
+
+CompleteTopKStreaming is for clients rendering results progressively with large limits.
+It keeps the best words seen so far in a bounded min-heap while the whole subtree is walked,
+and delivers a sorted snapshot every few hundred matches.
+Early snapshots may still change, the final one (done set) is exact.
+
+	err := completer.CompleteTopKStreaming("th", 500, 0, func(best []Suggestion, done bool) bool {
+		render(best)
+		return true
+	})
+
+# Deadlines
+
+CompleteWithContext bounds how long a search may take. The trie walk checks the context
+every few hundred nodes and stops once it is done, returning the words found so far with
+the context's error:
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	suggestions, err := completer.CompleteWithContext(ctx, CompletionOptions{Prefix: "th", Limit: 10})
+
 # Perf
 
 The implementation achieves sub millisecond completion times consistently for typical workloads
 through several design choices:
 radix trie structure provides O(k) lookup where k is prefix length,
 memory pools reduce alloc overhead,
-early termination prevents unnecessary traversal,
+a bounded heap keeps only the best words of large subtrees,
 and frequency thresholds filter low relevance results.
 
 Benchmarking on Apple MacBook m4Pro chip shows completion times under 500 microseconds
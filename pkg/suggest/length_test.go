@@ -0,0 +1,59 @@
+package suggest
+
+import (
+	"fmt"
+	"slices"
+	"testing"
+)
+
+var lengthWords = map[string]int{
+	"cry":         900,
+	"crab":        800,
+	"crane":       700,
+	"crates":      600,
+	"crayons":     500,
+	"crocodile":   400,
+	"cranberries": 300,
+	"crest":       200,
+	"crêpe":       100,
+}
+
+func TestCompleteWithLengthBounds(t *testing.T) {
+	tests := []struct {
+		minLen, maxLen, limit int
+		want                  []string
+	}{
+		{0, 0, 10, []string{"cry", "crab", "crane", "crates", "crayons", "crocodile", "cranberries", "crest", "crêpe"}},
+		{5, 7, 10, []string{"crane", "crates", "crayons", "crest", "crêpe"}},
+		// The short, more frequent words don't take the places of those that fit
+		{5, 7, 2, []string{"crane", "crates"}},
+		{9, 0, 10, []string{"crocodile", "cranberries"}},
+		{0, 4, 10, []string{"cry", "crab"}},
+		// Counted in runes, crêpe is 5 letters in 6 bytes
+		{5, 5, 10, []string{"crane", "crest", "crêpe"}},
+		{8, 8, 10, nil},
+	}
+	completer := newStaticCompleter(lengthWords)
+	for _, tt := range tests {
+		got := words(completer.CompleteWithOptions(CompletionOptions{Prefix: "cr", Limit: tt.limit, MinLen: tt.minLen, MaxLen: tt.maxLen}))
+		if !slices.Equal(got, tt.want) {
+			t.Errorf("minLen %d, maxLen %d, limit %d: got %v, want %v", tt.minLen, tt.maxLen, tt.limit, got, tt.want)
+		}
+	}
+}
+
+func TestLengthBoundsBypassHotCache(t *testing.T) {
+	dict := []string{"cry", "crab", "crane", "crates", "crayons", "crocodile"}
+	for i := range 100 {
+		dict = append(dict, fmt.Sprintf("filler%03d", i))
+	}
+	completer := newTestCompleter(t, dict, 50, true)
+	waitHotCache(t, completer)
+
+	// Fills the hot cache with the unbounded results for the prefix
+	completer.Complete("cr", 3)
+	got := words(completer.CompleteWithOptions(CompletionOptions{Prefix: "cr", Limit: 3, MinLen: 5, MaxLen: 7}))
+	if want := []string{"crane", "crates", "crayons"}; !slices.Equal(got, want) {
+		t.Errorf("bounded search after a cached one = %v, want %v", got, want)
+	}
+}
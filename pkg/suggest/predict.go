@@ -0,0 +1,65 @@
+package suggest
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/bastiangx/wordserve/internal/utils"
+	"github.com/tchap/go-patricia/v2/patricia"
+)
+
+// PredictNext suggests the word that follows previousWord.
+//
+// Candidates come from the bigram data loaded next to the dictionary chunks
+// and are ranked by how often they follow previousWord. The prefix narrows them
+// down to words starting with what has been typed so far, and may be empty.
+// Capitalization of the prefix is applied as in [Complete].
+//
+// When there is no bigram data for previousWord, or it yields fewer than limit
+// words, the rest is filled with plain prefix completions for a non-empty prefix.
+// A limit of 0 or less predicts up to [DefaultLimit] words, as in [Complete].
+func (c *Completer) PredictNext(previousWord, prefix string, limit int) []Suggestion {
+	limit = resolveLimit(limit)
+	lowerPrefix, capitalInfo := utils.GetCapitalDetails(prefix)
+	var suggestions []Suggestion
+	if nextTrie := c.nextWords(strings.ToLower(strings.TrimSpace(previousWord))); nextTrie != nil {
+		nextTrie.VisitSubtree(patricia.Prefix(lowerPrefix), func(p patricia.Prefix, item patricia.Item) error {
+			word := string(p)
+			if word == lowerPrefix && lowerPrefix != "" {
+				return nil
+			}
+			suggestions = append(suggestions, Suggestion{Word: word, Frequency: extractFrequency(item, word)})
+			return nil
+		})
+		slices.SortFunc(suggestions, byFrequency)
+		if len(suggestions) > limit {
+			suggestions = suggestions[:limit]
+		}
+		c.applyCapitalization(suggestions, capitalInfo)
+	}
+
+	if prefix == "" || len(suggestions) >= limit {
+		return suggestions
+	}
+	seen := make(map[string]bool, len(suggestions))
+	for _, suggestion := range suggestions {
+		seen[suggestion.Word] = true
+	}
+	for _, suggestion := range c.complete(CompletionOptions{Prefix: prefix, Limit: limit}) {
+		if len(suggestions) >= limit {
+			break
+		}
+		if !seen[suggestion.Word] {
+			suggestions = append(suggestions, suggestion)
+		}
+	}
+	return suggestions
+}
+
+// nextWords returns the trie of words following previousWord, nil without bigram data
+func (c *Completer) nextWords(previousWord string) *patricia.Trie {
+	if c.chunkLoader == nil || previousWord == "" {
+		return nil
+	}
+	return c.chunkLoader.Bigrams().Next(previousWord)
+}
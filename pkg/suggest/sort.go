@@ -0,0 +1,101 @@
+package suggest
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"strings"
+	"unicode/utf8"
+)
+
+// SortMode selects how completion results are ordered.
+//
+// The set of results is always the most frequent matches; the mode only
+// decides the order they are returned in. Frequency breaks ties in every mode.
+type SortMode int
+
+const (
+	// SortFrequency orders by descending frequency, the default.
+	SortFrequency SortMode = iota
+	// SortAlphabetical orders words A to Z.
+	SortAlphabetical
+	// SortLength orders shortest words first, so "cat" comes before "caterpillar".
+	SortLength
+)
+
+// String returns the config name of the mode.
+func (m SortMode) String() string {
+	switch m {
+	case SortAlphabetical:
+		return "alphabetical"
+	case SortLength:
+		return "length"
+	default:
+		return "frequency"
+	}
+}
+
+// ParseSortMode parses a config value ("frequency", "alphabetical" or "length").
+// An empty string is the default frequency order.
+func ParseSortMode(s string) (SortMode, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "", "frequency":
+		return SortFrequency, nil
+	case "alphabetical":
+		return SortAlphabetical, nil
+	case "length":
+		return SortLength, nil
+	}
+	return SortFrequency, fmt.Errorf("unknown sort mode: %q", s)
+}
+
+// comparator orders two suggestions the way [slices.SortFunc] expects:
+// negative when a comes first, positive when b does, 0 when they tie.
+type comparator func(a, b Suggestion) int
+
+// byFrequency is the default order: most frequent first, then A to Z.
+// Every search ranks with it, so words of equal frequency come back in the
+// same order on every run, whichever path (trie, hot cache, folding) found them.
+func byFrequency(a, b Suggestion) int {
+	if c := cmp.Compare(b.Frequency, a.Frequency); c != 0 {
+		return c
+	}
+	return strings.Compare(a.Word, b.Word)
+}
+
+// byAlphabet orders words A to Z, then most frequent first.
+func byAlphabet(a, b Suggestion) int {
+	if c := strings.Compare(a.Word, b.Word); c != 0 {
+		return c
+	}
+	return cmp.Compare(b.Frequency, a.Frequency)
+}
+
+// byLength orders shortest words first, in runes, then by [byFrequency].
+func byLength(a, b Suggestion) int {
+	if c := cmp.Compare(utf8.RuneCountInString(a.Word), utf8.RuneCountInString(b.Word)); c != 0 {
+		return c
+	}
+	return byFrequency(a, b)
+}
+
+// comparator returns the order results are returned in for mode
+func (m SortMode) comparator() comparator {
+	switch m {
+	case SortAlphabetical:
+		return byAlphabet
+	case SortLength:
+		return byLength
+	default:
+		return byFrequency
+	}
+}
+
+// orderSuggestions reorders frequency-sorted suggestions according to mode.
+// The sort is stable and falls back to the word itself, so results are deterministic.
+func orderSuggestions(suggestions []Suggestion, mode SortMode) {
+	if mode == SortFrequency {
+		return
+	}
+	slices.SortStableFunc(suggestions, mode.comparator())
+}
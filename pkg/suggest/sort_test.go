@@ -0,0 +1,118 @@
+package suggest
+
+import (
+	"slices"
+	"testing"
+)
+
+// newStaticCompleter returns a static completer holding words with their frequencies
+func newStaticCompleter(words map[string]int) *Completer {
+	completer := NewCompleter()
+	for word, freq := range words {
+		completer.AddWord(word, freq)
+	}
+	return completer
+}
+
+var sortWords = map[string]int{
+	"caterpillar": 900,
+	"car":         500,
+	"cat":         500,
+	"cab":         300,
+	"camel":       100,
+	"cap":         100,
+}
+
+func TestSortModes(t *testing.T) {
+	tests := []struct {
+		mode SortMode
+		want []string
+	}{
+		// Equal frequencies fall back to A to Z
+		{SortFrequency, []string{"caterpillar", "car", "cat", "cab", "camel", "cap"}},
+		{SortAlphabetical, []string{"cab", "camel", "cap", "car", "cat", "caterpillar"}},
+		// Equal lengths fall back to frequency, then A to Z
+		{SortLength, []string{"car", "cat", "cab", "cap", "camel", "caterpillar"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.mode.String(), func(t *testing.T) {
+			completer := newStaticCompleter(sortWords)
+			completer.SetSortMode(tt.mode)
+			// Repeated to catch any order that depends on map or trie iteration
+			for range 5 {
+				if got := words(completer.Complete("ca", 10)); !slices.Equal(got, tt.want) {
+					t.Fatalf("Complete = %v, want %v", got, tt.want)
+				}
+			}
+			var streamed []string
+			if err := completer.CompleteWithCallback("ca", 10, func(s Suggestion) bool {
+				streamed = append(streamed, s.Word)
+				return true
+			}); err != nil {
+				t.Fatal(err)
+			}
+			if !slices.Equal(streamed, tt.want) {
+				t.Errorf("CompleteWithCallback = %v, want %v", streamed, tt.want)
+			}
+
+			mode := tt.mode
+			perRequest := newStaticCompleter(sortWords)
+			got := words(perRequest.CompleteWithOptions(CompletionOptions{Prefix: "ca", Limit: 10, SortMode: &mode}))
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("CompleteWithOptions with SortMode = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSortModeKeepsTopResults(t *testing.T) {
+	completer := newStaticCompleter(sortWords)
+	completer.SetSortMode(SortAlphabetical)
+	// The 3 most frequent words, returned A to Z, not the 3 first words A to Z
+	want := []string{"car", "cat", "caterpillar"}
+	if got := words(completer.Complete("ca", 3)); !slices.Equal(got, want) {
+		t.Errorf("Complete = %v, want %v", got, want)
+	}
+}
+
+func TestParseSortMode(t *testing.T) {
+	for input, want := range map[string]SortMode{
+		"":              SortFrequency,
+		"frequency":     SortFrequency,
+		" Alphabetical": SortAlphabetical,
+		"LENGTH":        SortLength,
+	} {
+		if got, err := ParseSortMode(input); err != nil || got != want {
+			t.Errorf("ParseSortMode(%q) = %v, %v, want %v", input, got, err, want)
+		}
+	}
+	if _, err := ParseSortMode("random"); err == nil {
+		t.Error(`ParseSortMode("random") returned no error`)
+	}
+}
+
+func TestEqualFrequencyTiebreak(t *testing.T) {
+	// Inserted out of order, so neither trie nor insertion order gives A to Z
+	tied := []string{"tap", "tan", "tax", "tab", "tar", "tag"}
+	completer := NewCompleter()
+	completer.AddWord("tea", 900)
+	for _, word := range tied {
+		completer.AddWord(word, 500)
+	}
+	completer.AddWord("toy", 100)
+
+	// Words of equal frequency come back A to Z, on every run
+	want := []string{"tea", "tab", "tag", "tan", "tap", "tar", "tax", "toy"}
+	for range 20 {
+		if got := words(completer.Complete("t", 10)); !slices.Equal(got, want) {
+			t.Fatalf("Complete(\"t\") = %v, want %v", got, want)
+		}
+	}
+	// Cut by the limit, the first words A to Z are kept
+	if got := words(completer.Complete("t", 4)); !slices.Equal(got, want[:4]) {
+		t.Errorf("Complete(\"t\", 4) = %v, want %v", got, want[:4])
+	}
+	if got := words(completer.Complete("ta", 3)); !slices.Equal(got, want[1:4]) {
+		t.Errorf("Complete(\"ta\", 3) = %v, want %v", got, want[1:4])
+	}
+}
@@ -0,0 +1,138 @@
+package suggest
+
+import (
+	"container/heap"
+	"errors"
+	"slices"
+
+	"github.com/bastiangx/wordserve/internal/utils"
+	"github.com/tchap/go-patricia/v2/patricia"
+)
+
+// defaultStreamInterval is how many matching words are scanned between snapshots
+// when [Completer.CompleteTopKStreaming] is given no interval
+const defaultStreamInterval = 256
+
+// errStopStream ends the trie walk once the caller has seen enough
+var errStopStream = errors.New("stream stopped")
+
+// CompleteTopKStreaming delivers the best limit completions of prefix while the
+// trie is still being walked, for clients that render results progressively.
+//
+// Unlike [CompleteWithCallback], which collects and sorts before delivering,
+// it keeps the current top limit words in a bounded min-heap and hands a sorted
+// snapshot of them to deliver every interval matching words, whenever the heap
+// changed since the last one. Early snapshots are a best guess: a frequent word
+// further down the subtree can still push a word out. The whole subtree is
+// scanned, so the last snapshot, sent with done set, is exact.
+//
+// deliver returns false to stop scanning, keeping the last snapshot it got.
+// Snapshots are copies and safe to keep, but deliver must not add or remove
+// words, as in [CompleteWithCallback]. Capitalization and the sort mode are
+// applied to each, the heap itself always ranks by frequency.
+// A limit of 0 or less keeps [DefaultLimit] words, as in [Complete], and an
+// interval of 0 or less scans 256 words between snapshots.
+//
+// CompleteTopKStreaming returns an error if the trie walk fails, or nil on
+// success, including when deliver stopped it.
+func (c *Completer) CompleteTopKStreaming(prefix string, limit, interval int, deliver func(best []Suggestion, done bool) bool) error {
+	defer c.readLockStatic()()
+	limit = resolveLimit(limit)
+	if interval <= 0 {
+		interval = defaultStreamInterval
+	}
+	trie := c.getActiveTrie()
+	lowerPrefix, capitalInfo := utils.GetCapitalDetails(prefix)
+	stream := &topKStream{
+		limit:       limit,
+		sortMode:    c.sortMode,
+		capitalInfo: capitalInfo,
+		deliver:     deliver,
+	}
+	if trie == nil {
+		stream.emit(true)
+		return nil
+	}
+
+	minThreshold := c.getFrequencyThreshold(lowerPrefix)
+	skip := c.blockedFilter()
+	scanned := 0
+	err := trie.VisitSubtree(patricia.Prefix(lowerPrefix), func(p patricia.Prefix, item patricia.Item) error {
+		if !extendsPrefix(p, lowerPrefix) {
+			return nil
+		}
+		word := string(p)
+		freq := extractFrequency(item, word)
+		if freq < minThreshold || (skip != nil && skip(word)) {
+			return nil
+		}
+		stream.offer(Suggestion{Word: word, Frequency: freq})
+		scanned++
+		if scanned%interval == 0 && stream.changed && !stream.emit(false) {
+			return errStopStream
+		}
+		return nil
+	})
+	if errors.Is(err, errStopStream) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	stream.emit(true)
+	return nil
+}
+
+// topKStream holds the best words found so far in a [CompleteTopKStreaming] walk
+type topKStream struct {
+	best        suggestionHeap
+	limit       int
+	changed     bool
+	sortMode    SortMode
+	capitalInfo *utils.CapitalInfo
+	deliver     func([]Suggestion, bool) bool
+}
+
+// offer keeps s if it is among the limit best words seen
+func (t *topKStream) offer(s Suggestion) {
+	if len(t.best) < t.limit {
+		heap.Push(&t.best, s)
+		t.changed = true
+		return
+	}
+	if byFrequency(s, t.best[0]) < 0 {
+		t.best[0] = s
+		heap.Fix(&t.best, 0)
+		t.changed = true
+	}
+}
+
+// emit sends a sorted, capitalized copy of the best words to deliver
+func (t *topKStream) emit(done bool) bool {
+	snapshot := slices.Clone([]Suggestion(t.best))
+	slices.SortFunc(snapshot, byFrequency)
+	orderSuggestions(snapshot, t.sortMode)
+	if t.capitalInfo != nil {
+		for i := range snapshot {
+			snapshot[i].Word = utils.CapitalizeAtPositions(snapshot[i].Word, t.capitalInfo)
+		}
+	}
+	t.changed = false
+	return t.deliver(snapshot, done)
+}
+
+// suggestionHeap is a min-heap with the worst of the kept suggestions at the root
+type suggestionHeap []Suggestion
+
+func (h suggestionHeap) Len() int           { return len(h) }
+func (h suggestionHeap) Less(i, j int) bool { return byFrequency(h[i], h[j]) > 0 }
+func (h suggestionHeap) Swap(i, j int)      { h[i], h[j] = h[j], h[i] }
+
+func (h *suggestionHeap) Push(x any) { *h = append(*h, x.(Suggestion)) }
+
+func (h *suggestionHeap) Pop() any {
+	old := *h
+	s := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return s
+}
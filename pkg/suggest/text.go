@@ -0,0 +1,84 @@
+package suggest
+
+import (
+	"bufio"
+	"cmp"
+	"fmt"
+	"math"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/bastiangx/wordserve/pkg/dictionary"
+	"github.com/charmbracelet/log"
+)
+
+// textEntry is one word of a text dictionary with the count given for it, 0 when none was
+type textEntry struct {
+	word  string
+	count uint64
+}
+
+// LoadTextDictionary adds the words of a plain text dictionary, for trying out a
+// vocabulary without building chunks first.
+//
+// Each line holds a word, optionally followed by a tab or spaces and its count,
+// as in words.txt. Blank lines and lines starting with # are skipped. Words are
+// ranked by count, words without one keep their file order after the counted
+// ones, and scored like chunk words of the same rank ([dictionary.RankInverse]),
+// so frequency thresholds behave as with chunks. Words are lowercased and a word
+// seen again keeps its first line.
+//
+// It is meant for static completers from [NewCompleter]; on a lazy completer the
+// words are added on top of the chunks like runtime words.
+func (c *Completer) LoadTextDictionary(path string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("failed to load text dictionary: %w", err)
+	}
+	defer file.Close()
+
+	var entries []textEntry
+	seen := make(map[string]bool)
+	skipped := 0
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		fields := strings.Fields(line)
+		entry := textEntry{word: strings.ToLower(fields[0])}
+		if len(fields) > 1 {
+			count, err := strconv.ParseUint(fields[1], 10, 64)
+			if err != nil {
+				skipped++
+				continue
+			}
+			entry.count = count
+		}
+		if seen[entry.word] {
+			skipped++
+			continue
+		}
+		seen[entry.word] = true
+		entries = append(entries, entry)
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("failed to load text dictionary: %w", err)
+	}
+	if skipped > 0 {
+		log.Debugf("Skipped %d malformed or repeated lines in %s", skipped, path)
+	}
+
+	slices.SortStableFunc(entries, func(a, b textEntry) int {
+		return cmp.Compare(b.count, a.count)
+	})
+	for i, entry := range entries {
+		rank := uint16(min(i+1, math.MaxUint16))
+		c.AddWord(entry.word, dictionary.RankInverse.Score(rank))
+	}
+	log.Debugf("Loaded %d words from %s", len(entries), path)
+	return nil
+}
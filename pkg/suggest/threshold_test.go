@@ -0,0 +1,46 @@
+package suggest
+
+import (
+	"slices"
+	"testing"
+)
+
+var thresholdWords = map[string]int{
+	"word":   100,
+	"words":  30,
+	"wordy":  22,
+	"worded": 15,
+}
+
+func TestFrequencyThresholdsChangeResults(t *testing.T) {
+	completer := newStaticCompleter(thresholdWords)
+	tests := []struct {
+		thresholds FrequencyThresholds
+		prefix     string
+		want       []string
+	}{
+		// The defaults, 20 for longer prefixes and 24 for short ones
+		{FrequencyThresholds{Min: 20, ShortPrefix: 24}, "wor", []string{"word", "words", "wordy"}},
+		{FrequencyThresholds{Min: 20, ShortPrefix: 24}, "wo", []string{"word", "words"}},
+		{FrequencyThresholds{Min: 50, ShortPrefix: 24}, "wor", []string{"word"}},
+		{FrequencyThresholds{Min: 50, ShortPrefix: 24}, "wo", []string{"word", "words"}},
+		{FrequencyThresholds{Min: 20, ShortPrefix: 10}, "wo", []string{"word", "words", "wordy", "worded"}},
+		{FrequencyThresholds{Min: 1, ShortPrefix: 1}, "word", []string{"words", "wordy", "worded"}},
+	}
+	for _, tt := range tests {
+		completer.SetFrequencyThresholds(tt.thresholds)
+		if got := words(completer.Complete(tt.prefix, 10)); !slices.Equal(got, tt.want) {
+			t.Errorf("Complete(%q) with %+v = %v, want %v", tt.prefix, tt.thresholds, got, tt.want)
+		}
+		if got := completer.CountMatches(tt.prefix, 0); got != len(tt.want) {
+			t.Errorf("CountMatches(%q) with %+v = %d, want %d", tt.prefix, tt.thresholds, got, len(tt.want))
+		}
+	}
+}
+
+func TestFrequencyThresholdsDefault(t *testing.T) {
+	want := FrequencyThresholds{Min: 20, ShortPrefix: 24}
+	if got := NewCompleter().FrequencyThresholds(); got != want {
+		t.Errorf("FrequencyThresholds() = %+v, want %+v", got, want)
+	}
+}
@@ -0,0 +1,68 @@
+package suggest
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/tchap/go-patricia/v2/patricia"
+)
+
+// largeTrie holds n words starting with "a", ranked by their number
+func largeTrie(n int) *patricia.Trie {
+	trie := patricia.NewTrie()
+	for i := range n {
+		trie.Insert(patricia.Prefix("a"+strconv.Itoa(i)), i+100)
+	}
+	return trie
+}
+
+// expiredContext returns a context whose tiny timeout has already passed
+func expiredContext(t *testing.T) context.Context {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), time.Microsecond)
+	t.Cleanup(cancel)
+	<-ctx.Done()
+	return ctx
+}
+
+func TestSearchTrieContextTimesOut(t *testing.T) {
+	trie := largeTrie(200_000)
+
+	all, err := searchTrieContext(context.Background(), trie, "a", 0, 10, nil)
+	if err != nil || len(all) != 10 {
+		t.Fatalf("search without a timeout = %d words, %v, want 10 words", len(all), err)
+	}
+
+	partial, err := searchTrieContext(expiredContext(t), trie, "a", 0, 10, nil)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("search with a tiny timeout returned %v, want context.DeadlineExceeded", err)
+	}
+	if len(partial) > 10 {
+		t.Errorf("search with a tiny timeout returned %d words, want at most the limit", len(partial))
+	}
+	// Only the first few hundred nodes are walked, the best words lie far beyond
+	if len(partial) > 0 && partial[0].Frequency >= all[0].Frequency {
+		t.Errorf("search with a tiny timeout found %v, want it cut short before %v", partial[0], all[0])
+	}
+}
+
+func TestCompleteWithContextTimesOut(t *testing.T) {
+	completer := NewCompleter()
+	for i := range 200_000 {
+		completer.AddWord("a"+strconv.Itoa(i), i+100)
+	}
+
+	if _, err := completer.CompleteWithContext(context.Background(), CompletionOptions{Prefix: "a", Limit: 10}); err != nil {
+		t.Fatalf("CompleteWithContext without a timeout: %v", err)
+	}
+	suggestions, err := completer.CompleteWithContext(expiredContext(t), CompletionOptions{Prefix: "a", Limit: 10})
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("CompleteWithContext with a tiny timeout returned %v, want context.DeadlineExceeded", err)
+	}
+	if len(suggestions) > 10 {
+		t.Errorf("CompleteWithContext with a tiny timeout returned %d words, want at most the limit", len(suggestions))
+	}
+}
@@ -0,0 +1,95 @@
+package suggest
+
+import (
+	"math/rand/v2"
+	"slices"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/bastiangx/wordserve/pkg/dictionary"
+	"github.com/tchap/go-patricia/v2/patricia"
+)
+
+// randomDictionary returns n random words over a small alphabet, so prefixes
+// share big subtrees, with frequencies drawn from a narrow range, so many tie
+func randomDictionary(n int) map[string]int {
+	rng := rand.New(rand.NewPCG(1, 2))
+	dictionary := make(map[string]int, n)
+	for len(dictionary) < n {
+		word := make([]byte, 2+rng.IntN(6))
+		for i := range word {
+			word[i] = "abcde"[rng.IntN(5)]
+		}
+		dictionary[string(word)] = 1 + rng.IntN(200)
+	}
+	return dictionary
+}
+
+// bruteForceTopN ranks every word extending prefix at or above minFreq and keeps the first limit
+func bruteForceTopN(dictionary map[string]int, prefix string, minFreq, limit int) []string {
+	var matches []Suggestion
+	for word, freq := range dictionary {
+		if strings.HasPrefix(word, prefix) && utf8.RuneCountInString(word) > utf8.RuneCountInString(prefix) && freq >= minFreq {
+			matches = append(matches, Suggestion{Word: word, Frequency: freq})
+		}
+	}
+	slices.SortFunc(matches, byFrequency)
+	return words(matches[:min(limit, len(matches))])
+}
+
+func TestTopNMatchesBruteForce(t *testing.T) {
+	dictionary := randomDictionary(3000)
+	completer := newStaticCompleter(dictionary)
+	for _, prefix := range []string{"a", "b", "ab", "cde", "eee", "abcd", "dd"} {
+		for _, limit := range []int{1, 3, 10, 50, 5000} {
+			for _, minFreq := range []int{1, 100, 190} {
+				want := bruteForceTopN(dictionary, prefix, minFreq, limit)
+				if got := words(SearchTrie(completer.trie, prefix, minFreq, limit)); !slices.Equal(got, want) {
+					t.Errorf("SearchTrie(%q, minFreq %d, limit %d) = %v, want %v", prefix, minFreq, limit, got, want)
+				}
+				opts := CompletionOptions{Prefix: prefix, Limit: limit, MinFreq: minFreq}
+				if got := words(completer.CompleteWithOptions(opts)); !slices.Equal(got, want) {
+					t.Errorf("Complete(%q, minFreq %d, limit %d) = %v, want %v", prefix, minFreq, limit, got, want)
+				}
+			}
+		}
+	}
+}
+
+// collectThenSort is the search the bounded heap replaced: every qualifying
+// word is collected, then all of them are sorted
+func collectThenSort(trie dictionary.Trie, prefix string, minFreq, limit int) []Suggestion {
+	var matches []Suggestion
+	trie.VisitSubtree(patricia.Prefix(prefix), func(p patricia.Prefix, item patricia.Item) error {
+		if !extendsPrefix(p, prefix) {
+			return nil
+		}
+		word := string(p)
+		if freq := extractFrequency(item, word); freq >= minFreq {
+			matches = append(matches, Suggestion{Word: word, Frequency: freq})
+		}
+		return nil
+	})
+	slices.SortFunc(matches, byFrequency)
+	return matches[:min(limit, len(matches))]
+}
+
+func BenchmarkTopN(b *testing.B) {
+	completer := newStaticCompleter(randomDictionary(50000))
+	searches := []struct {
+		name   string
+		search func(trie dictionary.Trie, prefix string, minFreq, limit int) []Suggestion
+	}{
+		{"heap", SearchTrie},
+		{"collect-then-sort", collectThenSort},
+	}
+	for _, search := range searches {
+		b.Run(search.name, func(b *testing.B) {
+			b.ReportAllocs()
+			for b.Loop() {
+				search.search(completer.trie, "a", 1, 10)
+			}
+		})
+	}
+}
@@ -1,8 +1,15 @@
 package suggest
 
 import (
+	"container/heap"
+	"context"
+	"errors"
+	"fmt"
+	"slices"
 	"sync"
+	"unicode/utf8"
 
+	"github.com/bastiangx/wordserve/pkg/dictionary"
 	"github.com/charmbracelet/log"
 	"github.com/tchap/go-patricia/v2/patricia"
 )
@@ -24,94 +31,129 @@ func init() {
 	}
 }
 
-// SearchTrie performs trie traversal with early termination and deduplication.
+// SearchTrie returns the most frequent words completing a prefix.
 //
 // SearchTrie traverses the given trie to find words matching the specified prefix,
 // applying frequency thresholds and result limits. The function uses memory pools
-// for alloc management and implements early termination when enough
-// results are collected.
+// for alloc management.
 //
 // The lowerPrefix parameter should be a lowercase version of the desired prefix.
-// Words in the trie matching this prefix are collected if their frequency meets
-// or exceeds minThreshold. The search stops after collecting ~1.5x
-// the requested limit to allow for better freq based sorting.
+// Words in the trie matching this prefix are collected if they are longer than
+// it in runes and their frequency meets or exceeds minThreshold, so a suggestion
+// always extends what was typed. The whole subtree is walked while a min-heap
+// keeps the limit best words seen, so the result is the true top limit by
+// frequency wherever the words sit in trie order. It is sorted by frequency,
+// ties A to Z. A limit of 0 or less returns no words.
 //
 // The returned slice is a copy, and safe for the caller to modify.
 //
 // SearchTrie returns nil if an error occurs during trie traversal.
 // The caller is responsible for ensuring the trie is properly initialized.
-func SearchTrie(trie *patricia.Trie, lowerPrefix string, minThreshold, limit int) []Suggestion {
-	if trie == nil {
-		return []Suggestion{}
+func SearchTrie(trie dictionary.Trie, lowerPrefix string, minThreshold, limit int) []Suggestion {
+	return searchTrie(trie, lowerPrefix, minThreshold, limit, nil)
+}
+
+// searchTrie is [SearchTrie] leaving out the words skip reports, which may be nil.
+// A failed walk is logged and returns nil.
+func searchTrie(trie dictionary.Trie, lowerPrefix string, minThreshold, limit int, skip func(word string) bool) []Suggestion {
+	suggestions, err := searchTrieContext(context.Background(), trie, lowerPrefix, minThreshold, limit, skip)
+	if err != nil {
+		log.Error(err)
+	}
+	return suggestions
+}
+
+// cancelCheckInterval is how many trie nodes are visited between checks of the context
+const cancelCheckInterval = 256
+
+// searchTrieContext is searchTrie stopping the walk once ctx is done.
+// It then returns the best words found so far along with ctx's error.
+// If the walk fails, it returns nil and the error instead of logging it.
+func searchTrieContext(ctx context.Context, trie dictionary.Trie, lowerPrefix string, minThreshold, limit int, skip func(word string) bool) ([]Suggestion, error) {
+	if trie == nil || limit <= 0 {
+		return []Suggestion{}, nil
 	}
-	return searchTrieImpl(trie, lowerPrefix, minThreshold, limit)
+	return searchTrieImpl(ctx, trie, lowerPrefix, minThreshold, limit, skip)
 }
 
 //go:inline
-func searchTrieImpl(trie *patricia.Trie, lowerPrefix string, minThreshold, limit int) []Suggestion {
-	// Get pooled resources
+func searchTrieImpl(ctx context.Context, trie dictionary.Trie, lowerPrefix string, minThreshold, limit int, skip func(word string) bool) ([]Suggestion, error) {
+	// Get pooled resources, the heap is backed by the pooled slice
 	suggestionsPtr := suggestionPool.Get().(*[]Suggestion)
-	suggestions := (*suggestionsPtr)[:0]
+	best := suggestionHeap((*suggestionsPtr)[:0])
 	defer func() {
-		if cap(*suggestionsPtr) > 200 {
+		if cap(best) > 200 {
 			*suggestionsPtr = make([]Suggestion, 0, 75)
 		} else {
-			*suggestionsPtr = (*suggestionsPtr)[:0]
+			*suggestionsPtr = best[:0]
 		}
 		suggestionPool.Put(suggestionsPtr)
 	}()
 
-	seenWordsPtr := seenWordsPool.Get().(*map[string]bool)
-	seenWords := *seenWordsPtr
-	defer func() {
-		clear(seenWords)
-		seenWordsPool.Put(seenWordsPtr)
-	}()
-
 	prefixBytes := patricia.Prefix(lowerPrefix)
-	targetLen := limit + limit/2
-
+	visited := 0
 	err := trie.VisitSubtree(prefixBytes, func(p patricia.Prefix, item patricia.Item) error {
-		return processTrieNode(p, item, lowerPrefix, minThreshold, targetLen, &suggestions, seenWords)
+		visited++
+		if visited%cancelCheckInterval == 0 {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+		}
+		processTrieNode(p, item, lowerPrefix, minThreshold, limit, &best, skip)
+		return nil
 	})
 
-	if err != nil {
-		log.Errorf("Error visiting trie subtree: %v", err)
-		return nil
+	if err != nil && ctx.Err() == nil {
+		return nil, fmt.Errorf("failed to visit trie subtree: %w", err)
 	}
 
-	result := make([]Suggestion, len(suggestions))
-	copy(result, suggestions)
-	return result
+	result := make([]Suggestion, len(best))
+	copy(result, best)
+	slices.SortFunc(result, byFrequency)
+	return result, err
 }
 
+// processTrieNode offers the word at p to best if it qualifies, keeping the limit
+// most frequent words in the heap
+//
 //go:inline
-func processTrieNode(p patricia.Prefix, item patricia.Item, lowerPrefix string, minThreshold, targetLen int, suggestions *[]Suggestion, seenWords map[string]bool) error {
-	if len(*suggestions) >= targetLen {
-		return nil
+func processTrieNode(p patricia.Prefix, item patricia.Item, lowerPrefix string, minThreshold, limit int, best *suggestionHeap, skip func(word string) bool) {
+	if !extendsPrefix(p, lowerPrefix) {
+		return
 	}
-
-	wordBytes := []byte(p)
-	if len(wordBytes) == len(lowerPrefix) && string(wordBytes) == lowerPrefix {
-		return nil
+	// Most words of a big subtree are rarer than the worst kept one,
+	// reject those before building their string
+	freq, ok := itemFrequency(item)
+	if !ok {
+		freq = extractFrequency(item, string(p))
 	}
-
-	word := string(wordBytes)
-	if seenWords[word] {
-		return nil
+	if freq < minThreshold || (len(*best) >= limit && freq < (*best)[0].Frequency) {
+		return
 	}
 
-	freq := extractFrequency(item, word)
-	if freq < minThreshold {
-		return nil
+	word := string(p)
+	if skip != nil && skip(word) {
+		return
+	}
+	suggestion := Suggestion{Word: word, Frequency: freq}
+	if len(*best) < limit {
+		heap.Push(best, suggestion)
+		return
+	}
+	if byFrequency(suggestion, (*best)[0]) < 0 {
+		(*best)[0] = suggestion
+		heap.Fix(best, 0)
 	}
+}
 
-	seenWords[word] = true
-	*suggestions = append(*suggestions, Suggestion{
-		Word:      word,
-		Frequency: freq,
-	})
-	return nil
+// extendsPrefix reports whether word is strictly longer than the prefix in runes,
+// so every suggestion adds at least one character to what was typed.
+// Comparing rune counts rather than bytes also drops keys that only differ from
+// the prefix by stray trailing bytes.
+//
+//go:inline
+func extendsPrefix(word []byte, lowerPrefix string) bool {
+	return utf8.RuneCount(word) > utf8.RuneCountInString(lowerPrefix)
 }
 
 // SearchTrieWithCallback performs zero-copy trie traversal using a callback.
@@ -127,15 +169,20 @@ func processTrieNode(p patricia.Prefix, item patricia.Item, lowerPrefix string,
 // It stops when the limit is reached or when the callback returns false.
 //
 // SearchTrieWithCallback returns an error if trie traversal fails, or nil on success.
-func SearchTrieWithCallback(trie *patricia.Trie, lowerPrefix string, minThreshold, limit int, callback func(Suggestion) bool) error {
+func SearchTrieWithCallback(trie dictionary.Trie, lowerPrefix string, minThreshold, limit int, callback func(Suggestion) bool) error {
+	return searchTrieWithCallback(trie, lowerPrefix, minThreshold, limit, nil, callback)
+}
+
+// searchTrieWithCallback is [SearchTrieWithCallback] leaving out the words skip reports, which may be nil
+func searchTrieWithCallback(trie dictionary.Trie, lowerPrefix string, minThreshold, limit int, skip func(word string) bool, callback func(Suggestion) bool) error {
 	if trie == nil {
 		return nil
 	}
-	return searchTrieWithCallbackImpl(trie, lowerPrefix, minThreshold, limit, callback)
+	return searchTrieWithCallbackImpl(trie, lowerPrefix, minThreshold, limit, skip, callback)
 }
 
 //go:inline
-func searchTrieWithCallbackImpl(trie *patricia.Trie, lowerPrefix string, minThreshold, limit int, callback func(Suggestion) bool) error {
+func searchTrieWithCallbackImpl(trie dictionary.Trie, lowerPrefix string, minThreshold, limit int, skip func(word string) bool, callback func(Suggestion) bool) error {
 	seenWordsPtr := seenWordsPool.Get().(*map[string]bool)
 	seenWords := *seenWordsPtr
 	defer func() {
@@ -146,19 +193,26 @@ func searchTrieWithCallbackImpl(trie *patricia.Trie, lowerPrefix string, minThre
 	count := 0
 	prefixBytes := patricia.Prefix(lowerPrefix)
 
-	return trie.VisitSubtree(prefixBytes, func(p patricia.Prefix, item patricia.Item) error {
-		return processCallbackNode(p, item, lowerPrefix, minThreshold, limit, &count, seenWords, callback)
+	err := trie.VisitSubtree(prefixBytes, func(p patricia.Prefix, item patricia.Item) error {
+		return processCallbackNode(p, item, lowerPrefix, minThreshold, limit, &count, seenWords, skip, callback)
 	})
+	if errors.Is(err, errStopCallback) {
+		return nil
+	}
+	return err
 }
 
+// errStopCallback ends a callback search once the limit is reached or the callback returns false
+var errStopCallback = errors.New("callback search stopped")
+
 //go:inline
-func processCallbackNode(p patricia.Prefix, item patricia.Item, lowerPrefix string, minThreshold, limit int, count *int, seenWords map[string]bool, callback func(Suggestion) bool) error {
+func processCallbackNode(p patricia.Prefix, item patricia.Item, lowerPrefix string, minThreshold, limit int, count *int, seenWords map[string]bool, skip func(word string) bool, callback func(Suggestion) bool) error {
 	if *count >= limit {
-		return nil
+		return errStopCallback
 	}
 
 	wordBytes := []byte(p)
-	if len(wordBytes) == len(lowerPrefix) && string(wordBytes) == lowerPrefix {
+	if !extendsPrefix(wordBytes, lowerPrefix) {
 		return nil
 	}
 
@@ -171,12 +225,18 @@ func processCallbackNode(p patricia.Prefix, item patricia.Item, lowerPrefix stri
 	if freq < minThreshold {
 		return nil
 	}
+	if skip != nil && skip(word) {
+		return nil
+	}
 
 	seenWords[word] = true
 	if !callback(Suggestion{Word: word, Frequency: freq}) {
-		return nil
+		return errStopCallback
 	}
 	*count++
+	if *count >= limit {
+		return errStopCallback
+	}
 	return nil
 }
 
@@ -188,19 +248,29 @@ func processCallbackNode(p patricia.Prefix, item patricia.Item, lowerPrefix stri
 //
 //go:inline
 func extractFrequency(item patricia.Item, word string) int {
-	if freq, ok := item.(int); ok {
+	if freq, ok := itemFrequency(item); ok {
 		return freq
 	}
+	log.Errorf("Unknown item type: %T for word %s", item, word)
+	return 1
+}
+
+// itemFrequency is extractFrequency without the word, reporting false for an unknown type
+//
+//go:inline
+func itemFrequency(item patricia.Item) (int, bool) {
+	if freq, ok := item.(int); ok {
+		return freq, true
+	}
 	if freq, ok := item.(int32); ok {
-		return int(freq)
+		return int(freq), true
 	}
 	switch v := item.(type) {
 	case uint32:
-		return int(v)
+		return int(v), true
 	case float64:
-		return int(v)
+		return int(v), true
 	default:
-		log.Errorf("Unknown item type: %T for word %s", item, word)
-		return 1
+		return 0, false
 	}
 }
@@ -0,0 +1,182 @@
+package suggest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/tchap/go-patricia/v2/patricia"
+)
+
+func TestExtendsPrefix(t *testing.T) {
+	tests := []struct {
+		word, prefix string
+		want         bool
+	}{
+		{"hello", "hello", false},
+		{"hellos", "hello", true},
+		{"héllo", "héll", true},
+		{"héllo", "héllo", false},
+		// The prefix ends half way through é, so café has as many runes as it
+		// even though it has a byte more
+		{"café", "caf\xc3", false},
+		{"cafés", "caf\xc3", true},
+		{"caf\xc3", "caf", true},
+	}
+	for _, tt := range tests {
+		if got := extendsPrefix([]byte(tt.word), tt.prefix); got != tt.want {
+			t.Errorf("extendsPrefix(%q, %q) = %t, want %t", tt.word, tt.prefix, got, tt.want)
+		}
+	}
+}
+
+// checkExtends fails the test for every word not strictly longer than prefix in runes
+func checkExtends(t *testing.T, source, prefix string, words []string) {
+	t.Helper()
+	for _, word := range words {
+		if utf8.RuneCountInString(word) <= utf8.RuneCountInString(prefix) {
+			t.Errorf("%s(%q) returned %q, which doesn't extend the prefix", source, prefix, word)
+		}
+	}
+}
+
+func TestSearchTrieOnlyExtendsPrefix(t *testing.T) {
+	trie := patricia.NewTrie()
+	for _, word := range []string{"caf", "café", "cafés", "cafe", "cafes", "caf\xc3"} {
+		trie.Insert(patricia.Prefix(word), 100)
+	}
+	for _, prefix := range []string{"caf", "caf\xc3", "café", "cafe"} {
+		checkExtends(t, "SearchTrie", prefix, words(SearchTrie(trie, prefix, 0, 10)))
+
+		var streamed []string
+		if err := SearchTrieWithCallback(trie, prefix, 0, 10, func(s Suggestion) bool {
+			streamed = append(streamed, s.Word)
+			return true
+		}); err != nil {
+			t.Fatal(err)
+		}
+		checkExtends(t, "SearchTrieWithCallback", prefix, streamed)
+	}
+	if got := words(SearchTrie(trie, "caf\xc3", 0, 10)); len(got) != 1 || got[0] != "cafés" {
+		t.Errorf("SearchTrie(%q) = %q, want only cafés", "caf\xc3", got)
+	}
+}
+
+func TestCompleteOnlyExtendsMixedCasePrefix(t *testing.T) {
+	completer := newStaticCompleter(map[string]int{
+		"hello":  500,
+		"hellos": 400,
+		"héllo":  300,
+		"héllos": 200,
+	})
+	for _, prefix := range []string{"HeLLo", "HELLO", "hÉllo", "HÉLLO"} {
+		got := words(completer.Complete(prefix, 10))
+		checkExtends(t, "Complete", prefix, got)
+		if len(got) != 1 || !strings.EqualFold(got[0][:len(got[0])-1], prefix) {
+			t.Errorf("Complete(%q) = %q, want only the plural", prefix, got)
+		}
+		if n := completer.CountMatches(prefix, 0); n != len(got) {
+			t.Errorf("CountMatches(%q) = %d, want %d", prefix, n, len(got))
+		}
+	}
+}
+
+func TestSearchFindsFrequentWordLateInTrieOrder(t *testing.T) {
+	// 200 rare words come before abzzz in trie order, far more than the limit
+	completer := NewCompleter()
+	for i := range 200 {
+		completer.AddWord(fmt.Sprintf("ab%03d", i), 30+i%5)
+	}
+	completer.AddWord("abzzz", 5000)
+
+	if got := words(SearchTrie(completer.trie, "ab", 0, 5)); len(got) != 5 || got[0] != "abzzz" {
+		t.Errorf("SearchTrie(\"ab\", 5) = %v, want abzzz first", got)
+	}
+	if got := words(completer.Complete("ab", 5)); len(got) != 5 || got[0] != "abzzz" {
+		t.Errorf("Complete(\"ab\", 5) = %v, want abzzz first", got)
+	}
+	var streamed []string
+	if err := completer.CompleteWithCallback("ab", 5, func(s Suggestion) bool {
+		streamed = append(streamed, s.Word)
+		return true
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Contains(streamed, "abzzz") {
+		t.Errorf("CompleteWithCallback(\"ab\", 5) = %v, want abzzz among them", streamed)
+	}
+}
+
+// errVisit is the failure injected into a trie walk
+var errVisit = errors.New("visit failed")
+
+// failingVisit makes the trie walk fail: the visitor checks the context every
+// few hundred nodes and returns its error, which is errVisit the first time.
+// The context reports no error afterwards, so the failure isn't taken for a
+// timeout.
+type failingVisit struct {
+	context.Context
+	failed bool
+}
+
+func (c *failingVisit) Err() error {
+	if !c.failed {
+		c.failed = true
+		return errVisit
+	}
+	return c.Context.Err()
+}
+
+func TestSearchReportsVisitorFailure(t *testing.T) {
+	completer := NewCompleter()
+	for i := range 2 * cancelCheckInterval {
+		completer.AddWord(fmt.Sprintf("ab%03d", i), 100)
+	}
+
+	suggestions, err := searchTrieContext(&failingVisit{Context: context.Background()}, completer.trie, "ab", 0, 5, nil)
+	if !errors.Is(err, errVisit) || suggestions != nil {
+		t.Errorf("searchTrieContext with a failing visitor = %v, %v, want no words and the failure", words(suggestions), err)
+	}
+
+	suggestions, err = completer.CompleteWithContext(&failingVisit{Context: context.Background()}, CompletionOptions{Prefix: "ab", Limit: 5})
+	if !errors.Is(err, errVisit) || len(suggestions) != 0 {
+		t.Errorf("CompleteWithContext with a failing visitor = %v, %v, want no words and the failure", words(suggestions), err)
+	}
+	if errors.Is(err, context.DeadlineExceeded) || errors.Is(err, context.Canceled) {
+		t.Errorf("CompleteWithContext failure %v is reported as the context ending", err)
+	}
+}
+
+func TestCallbackStopsAfterFalse(t *testing.T) {
+	static := newStaticCompleter(map[string]int{"hello": 900, "help": 800, "helmet": 700, "held": 600})
+	// Words in several chunks, so the walk spans the loader's per-chunk tries
+	lazy := newTestCompleter(t, []string{"hello", "help", "helmet", "held", "hero"}, 2, false)
+	searches := map[string]func(callback func(Suggestion) bool) error{
+		"SearchTrieWithCallback": func(callback func(Suggestion) bool) error {
+			return SearchTrieWithCallback(static.trie, "he", 0, 10, callback)
+		},
+		"static CompleteUnsorted": func(callback func(Suggestion) bool) error {
+			return static.CompleteUnsorted("he", 10, callback)
+		},
+		"lazy CompleteUnsorted": func(callback func(Suggestion) bool) error {
+			return lazy.CompleteUnsorted("he", 10, callback)
+		},
+	}
+	for name, search := range searches {
+		calls := 0
+		err := search(func(Suggestion) bool {
+			calls++
+			return false
+		})
+		if err != nil {
+			t.Errorf("%s returned %v, want nil when the callback stops it", name, err)
+		}
+		if calls != 1 {
+			t.Errorf("%s called the callback %d times after it returned false, want 1", name, calls)
+		}
+	}
+}